@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"cryptowatch/config"
+	"cryptowatch/internal/models"
+	"cryptowatch/internal/service"
+)
+
+// 回測 CLI：以歷史 K 線重播 LRC 突破訂閱，不需要 Redis
+//
+//	go run ./cmd/backtest -symbol BTC -interval 4h -bars 2000 -mode close -volume -volume-multiplier 5
+func main() {
+	req := models.BacktestRequest{}
+	var start, end, horizons string
+	var asJSON bool
+
+	flag.StringVar(&req.Symbol, "symbol", "BTC", "幣種代號")
+	flag.StringVar(&req.Interval, "interval", "4h", "LRC 週期")
+	flag.StringVar(&start, "start", "", "回測開始時間（RFC3339 或 2006-01-02），留空則取結束時間往前 -bars 根")
+	flag.StringVar(&end, "end", "", "回測結束時間（RFC3339 或 2006-01-02），留空則為現在")
+	flag.IntVar(&req.Bars, "bars", models.DefaultBacktestBars, "未指定開始時間時回測的 K 線根數")
+	flag.IntVar(&req.LRCLength, "length", 42, "LRC 回歸長度")
+	flag.Float64Var(&req.LRCDevMultiplier, "dev", 2.0, "LRC 標準差倍數")
+	flag.StringVar(&horizons, "horizons", "1,3,6,12", "前瞻報酬的 K 線根數，以逗號分隔")
+	flag.IntVar(&req.NotifyIntervalMin, "cooldown", 60, "冷卻時間（分鐘）")
+	flag.StringVar(&req.TriggerMode, "mode", models.TriggerModeClose, "觸發模式：intrabar、close 或 consecutive")
+	flag.IntVar(&req.ConfirmCloses, "confirm", 2, "consecutive 模式需連續收盤突破的根數")
+	flag.Float64Var(&req.MinPearsonR, "min-r", 0, "最小 |R|，0 表示不過濾")
+	flag.StringVar(&req.SlopeFilter, "slope", "", "斜率方向過濾：aligned、up 或 down")
+	flag.BoolVar(&req.EnableVolumeCheck, "volume", false, "啟用成交量檢查")
+	flag.StringVar(&req.VolumeCheckMode, "volume-mode", "multiplier", "成交量檢查模式")
+	flag.Float64Var(&req.VolumeMultiplier, "volume-multiplier", 10, "倍數模式：N 倍均量")
+	flag.Float64Var(&req.VolumeFixedValue, "volume-fixed", 0, "固定值模式的閾值")
+	flag.IntVar(&req.VolumeAvgPeriod, "volume-period", 20, "均量計算週期")
+	flag.Float64Var(&req.VolumeScoreThreshold, "volume-score", 3.0, "zscore / mad 模式的分數閾值")
+	flag.Float64Var(&req.VolumeTakerRatio, "volume-taker", 0.7, "taker_buy / taker_sell 模式的主動成交佔比")
+	flag.BoolVar(&req.RequireCompressedBreakout, "compressed", false, "只計算波動收斂後的突破")
+	flag.IntVar(&req.CompressedWithinBars, "compressed-within", 3, "波動收斂往回檢查的根數")
+	flag.BoolVar(&asJSON, "json", false, "以 JSON 輸出完整結果")
+	flag.Parse()
+
+	var err error
+	if req.Start, err = parseTime(start); err != nil {
+		fatalf("無效的 -start: %v", err)
+	}
+	if req.End, err = parseTime(end); err != nil {
+		fatalf("無效的 -end: %v", err)
+	}
+	for _, field := range strings.Split(horizons, ",") {
+		h, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			fatalf("無效的 -horizons: %v", err)
+		}
+		req.Horizons = append(req.Horizons, h)
+	}
+
+	cfg := config.Load()
+	// 回測只呼叫幣安 K 線 API，不需要 Redis
+	backtestService := service.NewBacktestService(service.NewPriceService(nil, cfg.BinanceAPIURL))
+
+	result, err := backtestService.Run(&req)
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			fatalf("參數錯誤: %v", err)
+		}
+		fatalf("回測失敗: %v", err)
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			fatalf("輸出失敗: %v", err)
+		}
+		return
+	}
+	printResult(result)
+}
+
+// printResult 以表格輸出訊號與統計
+func printResult(result *models.BacktestResult) {
+	fmt.Printf("%s %s LRC(%d, %.2f)  %s ~ %s  共 %d 根 K 線\n",
+		result.Symbol, result.Interval, result.LRCLength, result.LRCDevMultiplier,
+		result.Start.Format(time.DateTime), result.End.Format(time.DateTime), result.BarsEvaluated)
+	fmt.Printf("訊號 %d 個（冷卻略過 %d，成交量略過 %d）\n\n", len(result.Signals), result.SkippedByCooldown, result.SkippedByVolume)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "時間\t方向\t價格\tMAE%\t前瞻報酬%")
+	for _, s := range result.Signals {
+		returns := make([]string, len(s.Returns))
+		for i, r := range s.Returns {
+			returns[i] = fmt.Sprintf("%d:%+.2f", r.Bars, r.ReturnPct)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%g\t%.2f\t%s\n",
+			s.Time.Format(time.DateTime), s.Direction, s.Price, s.MAEPct, strings.Join(returns, " "))
+	}
+	tw.Flush()
+
+	fmt.Println()
+	tw = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "前瞻根數\t訊號數\t勝率\t平均報酬%\t中位數報酬%")
+	for _, h := range result.Horizons {
+		fmt.Fprintf(tw, "%d\t%d\t%.1f%%\t%+.2f\t%+.2f\n", h.Bars, h.Signals, h.HitRate*100, h.AvgReturnPct, h.MedianReturnPct)
+	}
+	tw.Flush()
+	fmt.Printf("\n平均 MAE %.2f%%，最大 MAE %.2f%%\n", result.AvgMAE, result.MaxMAE)
+}
+
+// parseTime 解析 RFC3339 或日期字串，空字串返回零值
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// fatalf 輸出錯誤訊息並結束
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -8,6 +8,7 @@ import (
 	_ "cryptowatch/docs"
 	"cryptowatch/internal/api/handlers"
 	"cryptowatch/internal/api/middleware"
+	"cryptowatch/internal/models"
 	"cryptowatch/internal/repository"
 	"cryptowatch/internal/service"
 	"cryptowatch/internal/worker"
@@ -41,7 +42,8 @@ func main() {
 
 	// 現有服務
 	priceService := service.NewPriceService(redisRepo, cfg.BinanceAPIURL)
-	alertService := service.NewAlertService(redisRepo)
+	levelService := service.NewLevelService(redisRepo, priceService)
+	profileService := service.NewVolumeProfileService(redisRepo, priceService)
 
 	// Telegram 通知服務
 	telegramService := service.NewTelegramService(
@@ -50,23 +52,72 @@ func main() {
 		cfg.TelegramMyChatID,
 	)
 
+	// 警報通知管道
+	// 沒有 Bot Token 時不註冊 Telegram，避免警報在未實際發送的情況下被標記為已送達
+	notificationService := service.NewNotificationService()
+	if telegramService.IsEnabled() {
+		notificationService.Register(models.NotifyChannelTelegram, telegramService)
+	} else {
+		log.Warn().Msg("Telegram notification channel not registered: no bot token configured")
+	}
+
+	// 警報觸發歷史
+	alertHistoryService := service.NewAlertHistoryService(redisRepo, cfg.AlertHistoryRetentionDays)
+
+	alertService := service.NewAlertService(redisRepo, priceService, levelService, profileService, notificationService)
+
 	// 訂閱服務
 	subscriptionService := service.NewSubscriptionService(redisRepo)
 
+	// Pine Script 腳本服務
+	scriptService := service.NewScriptService(redisRepo, priceService)
+
+	// 已實現波動率服務
+	volatilityService := service.NewVolatilityService(priceService)
+
+	// 回測服務
+	backtestService := service.NewBacktestService(priceService)
+	sweepService := service.NewSweepService(redisRepo, backtestService)
+	if err := sweepService.FailOrphanedSweeps(); err != nil {
+		log.Error().Err(err).Msg("Error marking interrupted sweeps as failed")
+	}
+
+	// 跨幣種相關係數服務
+	correlationService := service.NewCorrelationService(redisRepo, priceService)
+
+	// 訊號結果追蹤服務
+	signalService := service.NewSignalService(redisRepo, priceService)
+
+	// 模擬交易服務
+	paperService := service.NewPaperService(redisRepo, priceService)
+
 	// 現有 handlers
 	priceHandler := handlers.NewPriceHandler(priceService)
-	alertHandler := handlers.NewAlertHandler(alertService)
+	alertHandler := handlers.NewAlertHandler(alertService, alertHistoryService)
 
 	// 現有 workers
 	priceFetcher := worker.NewPriceFetcher(priceService, cfg.PriceFetchInterval)
-	alertMonitor := worker.NewAlertMonitor(redisRepo)
-	volumeMonitor := worker.NewVolumeMonitor(redisRepo, priceService)
+	alertMonitor := worker.NewAlertMonitor(redisRepo, priceService, profileService, notificationService, alertHistoryService)
+	volumeMonitor := worker.NewVolumeMonitor(redisRepo, priceService, notificationService, alertHistoryService)
+	moveMonitor := worker.NewMoveMonitor(redisRepo, priceService, notificationService, alertHistoryService)
+	levelMonitor := worker.NewLevelMonitor(redisRepo, levelService)
+	alertSweeper := worker.NewAlertSweeper(redisRepo, notificationService, alertHistoryService)
 
 	// 指標監控 worker
-	indicatorMonitor := worker.NewIndicatorMonitor(redisRepo, priceService, telegramService)
+	indicatorMonitor := worker.NewIndicatorMonitor(redisRepo, priceService, telegramService, scriptService, correlationService, signalService, paperService)
+	signalTracker := worker.NewSignalTracker(redisRepo, signalService)
+	paperTrader := worker.NewPaperTrader(paperService)
 
 	// 新增：指標 handler
 	indicatorHandler := handlers.NewIndicatorHandler(subscriptionService, indicatorMonitor)
+	scriptHandler := handlers.NewScriptHandler(scriptService)
+	levelHandler := handlers.NewLevelHandler(levelService)
+	volumeProfileHandler := handlers.NewVolumeProfileHandler(profileService)
+	volatilityHandler := handlers.NewVolatilityHandler(volatilityService)
+	analyticsHandler := handlers.NewAnalyticsHandler(correlationService)
+	backtestHandler := handlers.NewBacktestHandler(backtestService, sweepService)
+	signalHandler := handlers.NewSignalHandler(signalService)
+	paperHandler := handlers.NewPaperHandler(paperService)
 
 	g, ctx := errgroup.WithContext(context.Background())
 
@@ -82,11 +133,31 @@ func main() {
 		return volumeMonitor.Start(ctx)
 	})
 
+	g.Go(func() error {
+		return moveMonitor.Start(ctx)
+	})
+
+	g.Go(func() error {
+		return levelMonitor.Start(ctx)
+	})
+
+	g.Go(func() error {
+		return alertSweeper.Start(ctx)
+	})
+
 	// 新增：啟動指標監控
 	g.Go(func() error {
 		return indicatorMonitor.Start(ctx)
 	})
 
+	g.Go(func() error {
+		return signalTracker.Start(ctx)
+	})
+
+	g.Go(func() error {
+		return paperTrader.Start(ctx)
+	})
+
 	router := gin.Default()
 	router.Use(middleware.CORS())
 
@@ -98,6 +169,7 @@ func main() {
 		api.GET("/prices", priceHandler.GetPrices)
 		api.POST("/alerts", alertHandler.CreateAlert)
 		api.GET("/alerts/:userId", alertHandler.GetUserAlerts)
+		api.GET("/alerts/:userId/history", alertHandler.GetAlertHistory)
 		api.DELETE("/alerts/:alertId", alertHandler.DeleteAlert)
 
 		// 新增：指標監控路由
@@ -109,6 +181,46 @@ func main() {
 			indicators.DELETE("/subscriptions/:id", indicatorHandler.DeleteSubscription)
 			indicators.POST("/subscriptions/:id/toggle", indicatorHandler.ToggleSubscription)
 			indicators.GET("/:symbol", indicatorHandler.GetIndicatorResult)
+			indicators.GET("/:symbol/levels", levelHandler.GetLevels)
+			indicators.GET("/:symbol/volume-profile", volumeProfileHandler.GetVolumeProfile)
+			indicators.GET("/:symbol/volatility", volatilityHandler.GetVolatility)
+		}
+
+		// Pine Script 腳本路由
+		scripts := api.Group("/scripts")
+		{
+			scripts.POST("", scriptHandler.CreateScript)
+			scripts.GET("", scriptHandler.GetUserScripts)
+			scripts.DELETE("/:id", scriptHandler.DeleteScript)
+			scripts.POST("/:id/run", scriptHandler.RunScript)
+		}
+
+		// 跨幣種分析路由
+		analytics := api.Group("/analytics")
+		{
+			analytics.GET("/correlation", analyticsHandler.GetCorrelation)
+		}
+
+		// 回測路由
+		backtests := api.Group("/backtests")
+		{
+			backtests.POST("", backtestHandler.RunBacktest)
+			backtests.POST("/sweeps", backtestHandler.StartSweep)
+			backtests.GET("/sweeps/:id", backtestHandler.GetSweep)
+		}
+
+		// 訊號成績單路由
+		signals := api.Group("/signals")
+		{
+			signals.GET("/subscriptions/:id/scorecard", signalHandler.GetSubscriptionScorecard)
+			signals.GET("/symbols/:symbol/scorecard", signalHandler.GetSymbolScorecard)
+		}
+
+		// 模擬交易路由
+		paper := api.Group("/paper")
+		{
+			paper.GET("/:userId/positions", paperHandler.GetPositions)
+			paper.GET("/:userId/account", paperHandler.GetAccount)
 		}
 	}
 
@@ -17,6 +17,9 @@ type Config struct {
 	TelegramBotToken  string // Telegram Bot Token（從 @BotFather 獲得）
 	TelegramTestMode  bool   // 測試模式（只 Log 不發送）
 	TelegramMyChatID  string // 你自己的 Chat ID（測試用）
+
+	// 警報歷史保留天數
+	AlertHistoryRetentionDays int
 }
 
 func Load() *Config {
@@ -32,6 +35,8 @@ func Load() *Config {
 		TelegramBotToken: getEnv("TELEGRAM_BOT_TOKEN", ""),
 		TelegramTestMode: getEnvBool("TELEGRAM_TEST_MODE", true), // 預設測試模式
 		TelegramMyChatID: getEnv("TELEGRAM_MY_CHAT_ID", ""),
+
+		AlertHistoryRetentionDays: getEnvInt("ALERT_HISTORY_RETENTION_DAYS", 90),
 	}
 }
 
@@ -51,3 +56,13 @@ func getEnvBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		n, err := strconv.Atoi(value)
+		if err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultValue
+}
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"cryptowatch/internal/models"
 	"cryptowatch/internal/service"
@@ -10,16 +12,17 @@ import (
 )
 
 type AlertHandler struct {
-	service *service.AlertService
+	service        *service.AlertService
+	historyService *service.AlertHistoryService
 }
 
-func NewAlertHandler(service *service.AlertService) *AlertHandler {
-	return &AlertHandler{service: service}
+func NewAlertHandler(service *service.AlertService, historyService *service.AlertHistoryService) *AlertHandler {
+	return &AlertHandler{service: service, historyService: historyService}
 }
 
 // CreateAlert godoc
 // @Summary      創建警報
-// @Description  創建價格警報或成交量警報
+// @Description  創建價格、成交量、支撐壓力位（level）、價值區（value_area）、漲跌幅（percent_move）、速度（velocity）或追蹤停損（trailing_stop）警報，觸發時通知 targets 指定的對象；單次警報全部送達後刪除，repeat 警報則停用至價格回到 rearmPct 遲滯區間外再重新啟用；schedule 設定生效的星期與時段（用戶時區），安靜時段內不觸發，deferQuiet 則延到安靜時段結束再通知；expiresAt 到期後刪除警報並通知
 // @Tags         alerts
 // @Accept       json
 // @Produce      json
@@ -37,7 +40,7 @@ func (h *AlertHandler) CreateAlert(c *gin.Context) {
 
 	alert, err := h.service.CreateAlert(&req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -66,6 +69,68 @@ func (h *AlertHandler) GetUserAlerts(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": alerts})
 }
 
+// GetAlertHistory godoc
+// @Summary      獲取警報觸發歷史
+// @Description  依觸發時間（新到舊）分頁列出用戶的警報觸發紀錄，包含觸發時的價格、成交量與各通知管道的送達結果
+// @Tags         alerts
+// @Produce      json
+// @Param        userId   path      string  true   "用戶ID"
+// @Param        alertId  query     string  false  "只列出單一警報的紀錄"
+// @Param        from     query     string  false  "觸發時間下限（RFC3339 或 2006-01-02）"
+// @Param        to       query     string  false  "觸發時間上限，不含（RFC3339 或 2006-01-02）"
+// @Param        limit    query     int     false  "每頁筆數，預設 50，最多 200"
+// @Param        offset   query     int     false  "略過的筆數"
+// @Success      200      {object}  models.AlertHistoryPage
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      500      {object}  map[string]interface{}
+// @Router       /alerts/{userId}/history [get]
+func (h *AlertHandler) GetAlertHistory(c *gin.Context) {
+	query := &models.AlertHistoryQuery{
+		UserID:  c.Param("userId"),
+		AlertID: c.Query("alertId"),
+	}
+
+	var err error
+	if query.From, err = parseQueryTime(c.Query("from")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be RFC3339 or YYYY-MM-DD"})
+		return
+	}
+	if query.To, err = parseQueryTime(c.Query("to")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be RFC3339 or YYYY-MM-DD"})
+		return
+	}
+	if raw := c.Query("limit"); raw != "" {
+		if query.Limit, err = strconv.Atoi(raw); err != nil || query.Limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+	}
+	if raw := c.Query("offset"); raw != "" {
+		if query.Offset, err = strconv.Atoi(raw); err != nil || query.Offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+			return
+		}
+	}
+
+	page, err := h.historyService.GetHistory(query)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// parseQueryTime 解析 RFC3339 或日期字串，空字串返回零值
+func parseQueryTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
 // DeleteAlert godoc
 // @Summary      刪除警報
 // @Description  根據警報ID刪除警報
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"cryptowatch/internal/models"
+	"cryptowatch/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AnalyticsHandler 跨幣種分析 API 處理器
+type AnalyticsHandler struct {
+	correlationService *service.CorrelationService
+}
+
+// NewAnalyticsHandler 創建分析處理器
+func NewAnalyticsHandler(correlationService *service.CorrelationService) *AnalyticsHandler {
+	return &AnalyticsHandler{correlationService: correlationService}
+}
+
+// GetCorrelation 獲取相關矩陣
+// @Summary      獲取監控幣種的相關矩陣
+// @Description  以已收盤 K 線的對數報酬率計算監控幣種之間的相關係數，以及相對 BTC 的 Beta 與最近一根的特有報酬，每根 K 線收盤後更新
+// @Tags         analytics
+// @Produce      json
+// @Param        interval query string false "K 線週期，預設 1h"
+// @Param        window   query int    false "報酬率根數，預設 100，範圍 10~500"
+// @Success      200 {object} models.CorrelationMatrix
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /analytics/correlation [get]
+func (h *AnalyticsHandler) GetCorrelation(c *gin.Context) {
+	interval := c.DefaultQuery("interval", "1h")
+
+	window := models.DefaultCorrelationWindow
+	if raw := c.Query("window"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "window must be a positive integer"})
+			return
+		}
+		window = n
+	}
+
+	matrix, err := h.correlationService.GetCorrelation(interval, window)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, matrix)
+}
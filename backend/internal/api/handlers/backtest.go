@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+
+	"cryptowatch/internal/models"
+	"cryptowatch/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BacktestHandler 回測 API 處理器
+type BacktestHandler struct {
+	backtestService *service.BacktestService
+	sweepService    *service.SweepService
+}
+
+// NewBacktestHandler 創建回測處理器
+func NewBacktestHandler(backtestService *service.BacktestService, sweepService *service.SweepService) *BacktestHandler {
+	return &BacktestHandler{
+		backtestService: backtestService,
+		sweepService:    sweepService,
+	}
+}
+
+// RunBacktest 執行回測
+// @Summary      回測 LRC 突破訂閱
+// @Description  以歷史 K 線重播訂閱的突破判斷（含冷卻時間與成交量條件），返回每個訊號的前瞻報酬、勝率與最大不利偏移
+// @Tags         backtests
+// @Accept       json
+// @Produce      json
+// @Param        request body models.BacktestRequest true "回測參數"
+// @Success      200 {object} models.BacktestResult
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /backtests [post]
+func (h *BacktestHandler) RunBacktest(c *gin.Context) {
+	var req models.BacktestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.backtestService.Run(&req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// StartSweep 開始參數搜尋
+// @Summary      開始 LRC 參數搜尋
+// @Description  在背景以網格或隨機搜尋評估 LRC 長度、標準差倍數、週期與成交量門檻的組合，依目標排序並以 walk-forward 分段檢查過度擬合
+// @Tags         backtests
+// @Accept       json
+// @Produce      json
+// @Param        request body models.SweepRequest true "搜尋設定"
+// @Success      202 {object} models.SweepJob
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /backtests/sweeps [post]
+func (h *BacktestHandler) StartSweep(c *gin.Context) {
+	var req models.SweepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	job, err := h.sweepService.StartSweep(&req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetSweep 獲取參數搜尋任務
+// @Summary      獲取參數搜尋進度與結果
+// @Description  返回任務狀態、進度、排名前幾名的參數組合與 walk-forward 驗證結果
+// @Tags         backtests
+// @Produce      json
+// @Param        id path string true "任務 ID"
+// @Success      200 {object} models.SweepJob
+// @Failure      500 {object} map[string]string
+// @Router       /backtests/sweeps/{id} [get]
+func (h *BacktestHandler) GetSweep(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sweep id is required"})
+		return
+	}
+
+	job, err := h.sweepService.GetSweep(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
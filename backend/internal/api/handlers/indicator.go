@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"cryptowatch/internal/models"
@@ -47,7 +48,7 @@ func (h *IndicatorHandler) CreateSubscription(c *gin.Context) {
 
 	sub, err := h.subscriptionService.CreateSubscription(&req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -108,7 +109,7 @@ func (h *IndicatorHandler) UpdateSubscription(c *gin.Context) {
 
 	sub, err := h.subscriptionService.UpdateSubscription(subscriptionID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -189,3 +190,15 @@ func (h *IndicatorHandler) GetIndicatorResult(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+// errorStatus 將服務層錯誤對應到 HTTP 狀態碼
+func errorStatus(err error) int {
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		return http.StatusBadRequest
+	}
+	var notFoundErr *models.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
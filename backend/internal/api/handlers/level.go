@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"cryptowatch/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LevelHandler 支撐壓力位 API 處理器
+type LevelHandler struct {
+	levelService *service.LevelService
+}
+
+// NewLevelHandler 創建支撐壓力位處理器
+func NewLevelHandler(levelService *service.LevelService) *LevelHandler {
+	return &LevelHandler{levelService: levelService}
+}
+
+// GetLevels 獲取支撐壓力位
+// @Summary      獲取幣種的支撐壓力位
+// @Description  以擺動高低點與成交量密集區偵測的水平支撐壓力區域，每根 K 線收盤後更新
+// @Tags         indicators
+// @Produce      json
+// @Param        symbol   path  string true  "幣種代號"
+// @Param        interval query string false "K 線週期，預設 4h"
+// @Success      200 {object} models.SRLevels
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /indicators/{symbol}/levels [get]
+func (h *LevelHandler) GetLevels(c *gin.Context) {
+	symbol := c.Param("symbol")
+	if symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol is required"})
+		return
+	}
+	interval := c.DefaultQuery("interval", "4h")
+
+	levels, err := h.levelService.GetLevels(symbol, interval)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, levels)
+}
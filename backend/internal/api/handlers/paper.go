@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+
+	"cryptowatch/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PaperHandler 模擬交易 API 處理器
+type PaperHandler struct {
+	paperService *service.PaperService
+}
+
+// NewPaperHandler 創建模擬交易處理器
+func NewPaperHandler(paperService *service.PaperService) *PaperHandler {
+	return &PaperHandler{paperService: paperService}
+}
+
+// GetPositions 獲取用戶的模擬部位
+// @Summary      獲取用戶的模擬部位
+// @Description  列出訂閱開立的模擬部位（新到舊），未平倉部位附上最新價格與扣除手續費、滑價後的未實現損益
+// @Tags         paper
+// @Produce      json
+// @Param        userId path  string true  "用戶 ID"
+// @Param        status query string false "open、closed，留空為全部"
+// @Success      200 {array}  models.PaperPosition
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /paper/{userId}/positions [get]
+func (h *PaperHandler) GetPositions(c *gin.Context) {
+	positions, err := h.paperService.GetPositions(c.Param("userId"), c.Query("status"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, positions)
+}
+
+// GetAccount 獲取用戶的模擬交易帳戶
+// @Summary      獲取用戶的模擬交易帳戶
+// @Description  統計已實現與未實現損益、勝率、手續費、最大回撤，以及依平倉順序累計的權益曲線
+// @Tags         paper
+// @Produce      json
+// @Param        userId path string true "用戶 ID"
+// @Success      200 {object} models.PaperAccount
+// @Failure      500 {object} map[string]string
+// @Router       /paper/{userId}/account [get]
+func (h *PaperHandler) GetAccount(c *gin.Context) {
+	account, err := h.paperService.GetAccount(c.Param("userId"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
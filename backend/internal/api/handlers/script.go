@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+
+	"cryptowatch/internal/models"
+	"cryptowatch/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScriptHandler Pine Script 腳本 API 處理器
+type ScriptHandler struct {
+	scriptService *service.ScriptService
+}
+
+// NewScriptHandler 創建腳本處理器
+func NewScriptHandler(scriptService *service.ScriptService) *ScriptHandler {
+	return &ScriptHandler{scriptService: scriptService}
+}
+
+// CreateScript 上傳腳本
+// @Summary      上傳 Pine Script 腳本
+// @Description  上傳 Pine Script 子集腳本，編譯失敗時返回錯誤行號與原因
+// @Tags         scripts
+// @Accept       json
+// @Produce      json
+// @Param        request body models.CreateScriptRequest true "腳本資料"
+// @Success      201 {object} models.Script
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /scripts [post]
+func (h *ScriptHandler) CreateScript(c *gin.Context) {
+	var req models.CreateScriptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	script, err := h.scriptService.CreateScript(&req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, script)
+}
+
+// GetUserScripts 獲取用戶腳本
+// @Summary      獲取用戶的所有腳本
+// @Description  獲取指定用戶上傳的所有 Pine Script 腳本
+// @Tags         scripts
+// @Produce      json
+// @Param        userId query string true "用戶 ID"
+// @Success      200 {array} models.Script
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /scripts [get]
+func (h *ScriptHandler) GetUserScripts(c *gin.Context) {
+	userID := c.Query("userId")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "userId is required"})
+		return
+	}
+
+	scripts, err := h.scriptService.GetUserScripts(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, scripts)
+}
+
+// DeleteScript 刪除腳本
+// @Summary      刪除腳本
+// @Description  刪除 Pine Script 腳本，仍有訂閱使用時返回 400 並列出訂閱 ID
+// @Tags         scripts
+// @Param        id path string true "腳本 ID"
+// @Success      204
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /scripts/{id} [delete]
+func (h *ScriptHandler) DeleteScript(c *gin.Context) {
+	scriptID := c.Param("id")
+	if scriptID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "script id is required"})
+		return
+	}
+
+	if err := h.scriptService.DeleteScript(scriptID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RunScript 試跑腳本
+// @Summary      試跑腳本
+// @Description  在最新的 K 線上執行腳本，返回最新一根的 plot 值與 alertcondition 狀態
+// @Tags         scripts
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "腳本 ID"
+// @Param        request body models.RunScriptRequest true "執行參數"
+// @Success      200 {object} models.ScriptRunResult
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /scripts/{id}/run [post]
+func (h *ScriptHandler) RunScript(c *gin.Context) {
+	scriptID := c.Param("id")
+	if scriptID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "script id is required"})
+		return
+	}
+
+	var req models.RunScriptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.scriptService.RunScript(scriptID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
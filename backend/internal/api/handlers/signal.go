@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"cryptowatch/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SignalHandler 訊號成績單 API 處理器
+type SignalHandler struct {
+	signalService *service.SignalService
+}
+
+// NewSignalHandler 創建訊號成績單處理器
+func NewSignalHandler(signalService *service.SignalService) *SignalHandler {
+	return &SignalHandler{signalService: signalService}
+}
+
+// GetSubscriptionScorecard 獲取訂閱的訊號成績單
+// @Summary      獲取訂閱的訊號成績單
+// @Description  統計訂閱最近的通知在 +15m/+1h/+4h/+24h 的順向報酬與勝率，以及平均最大順向/逆向波動
+// @Tags         signals
+// @Produce      json
+// @Param        id    path  string true  "訂閱 ID"
+// @Param        limit query int    false "統計最近的訊號數，預設 100，最多 500"
+// @Success      200 {object} models.SignalScorecard
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /signals/subscriptions/{id}/scorecard [get]
+func (h *SignalHandler) GetSubscriptionScorecard(c *gin.Context) {
+	limit, ok := scorecardLimit(c)
+	if !ok {
+		return
+	}
+
+	scorecard, err := h.signalService.GetSubscriptionScorecard(c.Param("id"), limit)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, scorecard)
+}
+
+// GetSymbolScorecard 獲取幣種的訊號成績單
+// @Summary      獲取幣種的訊號成績單
+// @Description  統計所有訂閱在該幣種最近的通知在 +15m/+1h/+4h/+24h 的順向報酬與勝率，以及平均最大順向/逆向波動
+// @Tags         signals
+// @Produce      json
+// @Param        symbol path  string true  "幣種代號"
+// @Param        limit  query int    false "統計最近的訊號數，預設 100，最多 500"
+// @Success      200 {object} models.SignalScorecard
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /signals/symbols/{symbol}/scorecard [get]
+func (h *SignalHandler) GetSymbolScorecard(c *gin.Context) {
+	limit, ok := scorecardLimit(c)
+	if !ok {
+		return
+	}
+
+	scorecard, err := h.signalService.GetSymbolScorecard(c.Param("symbol"), limit)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, scorecard)
+}
+
+// scorecardLimit 解析 limit 參數，無效時直接回應 400
+func scorecardLimit(c *gin.Context) (int, bool) {
+	limit := service.DefaultScorecardSignals
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return 0, false
+		}
+		limit = n
+	}
+	return limit, true
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"cryptowatch/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VolatilityHandler 已實現波動率 API 處理器
+type VolatilityHandler struct {
+	volatilityService *service.VolatilityService
+}
+
+// NewVolatilityHandler 創建已實現波動率處理器
+func NewVolatilityHandler(volatilityService *service.VolatilityService) *VolatilityHandler {
+	return &VolatilityHandler{volatilityService: volatilityService}
+}
+
+// GetVolatility 獲取已實現波動率
+// @Summary      獲取幣種的已實現波動率
+// @Description  以已收盤 K 線計算收盤對收盤、Parkinson、Garman-Klass 年化波動率，並依歷史百分位標示收斂/正常/擴張狀態
+// @Tags         indicators
+// @Produce      json
+// @Param        symbol   path  string true  "幣種代號"
+// @Param        interval query string false "K 線週期，預設 4h"
+// @Success      200 {object} models.Volatility
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /indicators/{symbol}/volatility [get]
+func (h *VolatilityHandler) GetVolatility(c *gin.Context) {
+	symbol := c.Param("symbol")
+	if symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol is required"})
+		return
+	}
+	interval := c.DefaultQuery("interval", "4h")
+
+	volatility, err := h.volatilityService.GetVolatility(symbol, interval)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, volatility)
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"cryptowatch/internal/models"
+	"cryptowatch/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VolumeProfileHandler 成交量分佈 API 處理器
+type VolumeProfileHandler struct {
+	profileService *service.VolumeProfileService
+}
+
+// NewVolumeProfileHandler 創建成交量分佈處理器
+func NewVolumeProfileHandler(profileService *service.VolumeProfileService) *VolumeProfileHandler {
+	return &VolumeProfileHandler{profileService: profileService}
+}
+
+// GetVolumeProfile 獲取成交量分佈
+// @Summary      獲取幣種的成交量分佈
+// @Description  依價格分箱統計回看期間的成交量，返回 POC、價值區上下緣與高低成交量節點，每根 K 線收盤後更新
+// @Tags         indicators
+// @Produce      json
+// @Param        symbol   path  string true  "幣種代號"
+// @Param        interval query string false "K 線週期，預設 1h"
+// @Param        lookback query int    false "回看的 K 線根數，預設 168，上限 1000"
+// @Success      200 {object} models.VolumeProfile
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /indicators/{symbol}/volume-profile [get]
+func (h *VolumeProfileHandler) GetVolumeProfile(c *gin.Context) {
+	symbol := c.Param("symbol")
+	if symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol is required"})
+		return
+	}
+	interval := c.DefaultQuery("interval", "1h")
+
+	lookback := models.DefaultProfileLookback
+	if raw := c.Query("lookback"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "lookback must be a positive integer"})
+			return
+		}
+		lookback = n
+	}
+
+	profile, err := h.profileService.GetVolumeProfile(symbol, interval, lookback)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
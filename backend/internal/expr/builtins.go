@@ -0,0 +1,157 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+
+	"cryptowatch/internal/indicators"
+)
+
+// MaxPeriod 函數週期參數的上限
+const MaxPeriod = 400
+
+// Lookback 資料來源每個週期至少需提供的 K 線根數
+const Lookback = MaxPeriod + 100
+
+// builtin 內建函數定義
+type builtin struct {
+	params   []valueType
+	optional int // 尾端可省略的參數數量
+	result   valueType
+	validate func(n *callNode) error
+	eval     func(src DataSource, args []value) (value, error)
+}
+
+// builtins 可用的內建函數
+var builtins = map[string]*builtin{
+	// lrc(長度, 標準差倍數, 週期) 線性回歸通道
+	"lrc": {
+		params:   []valueType{typeNumber, typeNumber, typeString},
+		result:   typeLRC,
+		validate: validateAll(periodArg(0, 2), positiveArg(1)),
+		eval: func(src DataSource, args []value) (value, error) {
+			closes, err := src.Closes(args[2].str)
+			if err != nil {
+				return value{}, err
+			}
+			lrc, err := indicators.CalculateLRC(closes, int(args[0].num), args[1].num)
+			if err != nil {
+				return value{}, err
+			}
+			return value{lrc: lrc}, nil
+		},
+	},
+
+	// rsi(週期數, 週期) 相對強弱指標
+	"rsi": {
+		params:   []valueType{typeNumber, typeString},
+		result:   typeNumber,
+		validate: periodArg(0, 1),
+		eval:     closeSeries(indicators.RSISeries),
+	},
+
+	// sma(週期數, 週期) 簡單移動平均
+	"sma": {
+		params:   []valueType{typeNumber, typeString},
+		result:   typeNumber,
+		validate: periodArg(0, 1),
+		eval:     closeSeries(indicators.SMASeries),
+	},
+
+	// ema(週期數, 週期) 指數移動平均
+	"ema": {
+		params:   []valueType{typeNumber, typeString},
+		result:   typeNumber,
+		validate: periodArg(0, 1),
+		eval:     closeSeries(indicators.EMASeries),
+	},
+
+	// vol_ratio(週期[, 均量根數]) 當前成交量 / 均量，預設 20 根
+	"vol_ratio": {
+		params:   []valueType{typeString, typeNumber},
+		optional: 1,
+		result:   typeNumber,
+		validate: periodArg(1, 1),
+		eval: func(src DataSource, args []value) (value, error) {
+			volumes, err := src.Volumes(args[0].str)
+			if err != nil {
+				return value{}, err
+			}
+			avgPeriod := 20
+			if len(args) > 1 {
+				avgPeriod = int(args[1].num)
+			}
+			return value{num: indicators.CalculateVolumeStats(volumes, avgPeriod).VolumeRatio}, nil
+		},
+	},
+
+	// change(根數, 週期) N 根 K 線的漲跌幅（%）
+	"change": {
+		params:   []valueType{typeNumber, typeString},
+		result:   typeNumber,
+		validate: periodArg(0, 1),
+		eval: func(src DataSource, args []value) (value, error) {
+			closes, err := src.Closes(args[1].str)
+			if err != nil {
+				return value{}, err
+			}
+			bars := int(args[0].num)
+			if len(closes) <= bars {
+				return value{}, fmt.Errorf("數據長度不足，需要至少 %d 根 K 線", bars+1)
+			}
+			base := closes[len(closes)-1-bars]
+			if base == 0 {
+				return value{num: math.NaN()}, nil
+			}
+			return value{num: (closes[len(closes)-1] - base) / base * 100}, nil
+		},
+	},
+}
+
+// closeSeries 以收盤價序列計算指標並取最新值
+func closeSeries(fn func([]float64, int) []float64) func(DataSource, []value) (value, error) {
+	return func(src DataSource, args []value) (value, error) {
+		closes, err := src.Closes(args[1].str)
+		if err != nil {
+			return value{}, err
+		}
+		return value{num: indicators.Last(fn(closes, int(args[0].num)))}, nil
+	}
+}
+
+// periodArg 檢查第 i 個參數是否為介於 min 與 MaxPeriod 之間的整數（參數省略時略過）
+func periodArg(i int, min int) func(n *callNode) error {
+	return func(n *callNode) error {
+		if i >= len(n.args) {
+			return nil
+		}
+		arg := n.args[i].(*numberNode)
+		if arg.value != math.Trunc(arg.value) || arg.value < float64(min) || arg.value > MaxPeriod {
+			return errorf(arg.pos, "%s 第 %d 個參數必須是 %d 到 %d 之間的整數", n.name, i+1, min, MaxPeriod)
+		}
+		return nil
+	}
+}
+
+// positiveArg 檢查第 i 個參數是否大於 0
+func positiveArg(i int) func(n *callNode) error {
+	return func(n *callNode) error {
+		arg := n.args[i].(*numberNode)
+		if arg.value <= 0 {
+			return errorf(arg.pos, "%s 第 %d 個參數必須大於 0", n.name, i+1)
+		}
+		return nil
+	}
+}
+
+// validateAll 依序執行多個檢查
+func validateAll(checks ...func(n *callNode) error) func(n *callNode) error {
+	return func(n *callNode) error {
+		for _, check := range checks {
+			if err := check(n); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,195 @@
+package expr
+
+import "fmt"
+
+// valueType 值的型別
+type valueType int
+
+const (
+	typeNumber valueType = iota
+	typeBool
+	typeString
+	typeLRC // lrc() 的結果，需以欄位存取
+)
+
+func (t valueType) String() string {
+	switch t {
+	case typeNumber:
+		return "數字"
+	case typeBool:
+		return "布林"
+	case typeString:
+		return "字串"
+	case typeLRC:
+		return "LRC 通道"
+	default:
+		return "未知"
+	}
+}
+
+// lrcFields lrc() 結果可存取的欄位
+var lrcFields = map[string]bool{
+	"upper":     true,
+	"lower":     true,
+	"center":    true,
+	"slope":     true,
+	"slope_pct": true,
+	"r":         true,
+	"dev":       true,
+}
+
+// variables 可用的變數
+var variables = map[string]valueType{
+	"close": typeNumber, // 當前價格
+	"price": typeNumber, // 當前價格（同 close）
+}
+
+// checker 型別檢查器，同時收集條件式需要的 K 線週期
+type checker struct {
+	intervals map[string]bool
+}
+
+// check 檢查節點型別
+func (c *checker) check(n node) (valueType, error) {
+	switch n := n.(type) {
+	case *numberNode:
+		return typeNumber, nil
+	case *stringNode:
+		return typeString, nil
+	case *boolNode:
+		return typeBool, nil
+
+	case *identNode:
+		t, ok := variables[n.name]
+		if !ok {
+			if _, isFunc := builtins[n.name]; isFunc {
+				return 0, errorf(n.pos, "%s 是函數，需要加上括號與參數", n.name)
+			}
+			return 0, errorf(n.pos, "未知的變數 %q", n.name)
+		}
+		return t, nil
+
+	case *unaryNode:
+		t, err := c.check(n.x)
+		if err != nil {
+			return 0, err
+		}
+		want := typeNumber
+		if n.op == "not" {
+			want = typeBool
+		}
+		if t != want {
+			return 0, errorf(n.pos, "%s 需要%s，得到%s", n.op, want, t)
+		}
+		return want, nil
+
+	case *binaryNode:
+		left, err := c.check(n.left)
+		if err != nil {
+			return 0, err
+		}
+		right, err := c.check(n.right)
+		if err != nil {
+			return 0, err
+		}
+		switch n.op {
+		case "and", "or":
+			if left != typeBool || right != typeBool {
+				return 0, errorf(n.pos, "%s 兩側必須是布林，得到%s與%s", n.op, left, right)
+			}
+			return typeBool, nil
+		case "==", "!=":
+			if left != right || (left != typeNumber && left != typeBool) {
+				return 0, errorf(n.pos, "無法比較%s與%s", left, right)
+			}
+			return typeBool, nil
+		case "<", "<=", ">", ">=":
+			if left != typeNumber || right != typeNumber {
+				return 0, errorf(n.pos, "%s 兩側必須是數字，得到%s與%s", n.op, left, right)
+			}
+			return typeBool, nil
+		default:
+			if left != typeNumber || right != typeNumber {
+				return 0, errorf(n.pos, "%s 兩側必須是數字，得到%s與%s", n.op, left, right)
+			}
+			return typeNumber, nil
+		}
+
+	case *fieldNode:
+		t, err := c.check(n.x)
+		if err != nil {
+			return 0, err
+		}
+		if t != typeLRC {
+			return 0, errorf(n.pos, "%s沒有欄位 %q", t, n.name)
+		}
+		if !lrcFields[n.name] {
+			return 0, errorf(n.pos, "LRC 通道沒有欄位 %q（可用: upper, lower, center, slope, slope_pct, r, dev）", n.name)
+		}
+		return typeNumber, nil
+
+	case *callNode:
+		return c.checkCall(n)
+	}
+	return 0, errorf(n.position(), "無法識別的語法")
+}
+
+// checkCall 檢查函數呼叫：參數數量、型別，以及參數必須是常數
+func (c *checker) checkCall(n *callNode) (valueType, error) {
+	b, ok := builtins[n.name]
+	if !ok {
+		return 0, errorf(n.pos, "未知的函數 %q", n.name)
+	}
+
+	minArgs := len(b.params) - b.optional
+	if len(n.args) < minArgs || len(n.args) > len(b.params) {
+		return 0, errorf(n.pos, "%s 需要 %s 個參數，得到 %d 個", n.name, b.arity(), len(n.args))
+	}
+
+	for i, arg := range n.args {
+		want := b.params[i]
+		switch a := arg.(type) {
+		case *numberNode:
+			if want != typeNumber {
+				return 0, errorf(a.pos, "%s 第 %d 個參數需要%s", n.name, i+1, want)
+			}
+		case *stringNode:
+			if want != typeString {
+				return 0, errorf(a.pos, "%s 第 %d 個參數需要%s", n.name, i+1, want)
+			}
+			if want == typeString && !validIntervals[a.value] {
+				return 0, errorf(a.pos, "無效的 K 線週期 %q", a.value)
+			}
+			c.intervals[a.value] = true
+		default:
+			return 0, errorf(arg.position(), "%s 第 %d 個參數必須是常數", n.name, i+1)
+		}
+	}
+
+	if b.validate != nil {
+		if err := b.validate(n); err != nil {
+			return 0, err
+		}
+	}
+	return b.result, nil
+}
+
+// arity 參數數量說明
+func (b *builtin) arity() string {
+	if b.optional == 0 {
+		return fmt.Sprintf("%d", len(b.params))
+	}
+	return fmt.Sprintf("%d~%d", len(b.params)-b.optional, len(b.params))
+}
+
+// ValidInterval 是否為幣安支援的 K 線週期
+func ValidInterval(interval string) bool {
+	return validIntervals[interval]
+}
+
+// validIntervals 幣安支援的 K 線週期
+var validIntervals = map[string]bool{
+	"1m": true, "3m": true, "5m": true, "15m": true, "30m": true,
+	"1h": true, "2h": true, "4h": true, "6h": true, "8h": true, "12h": true,
+	"1d": true, "3d": true, "1w": true, "1M": true,
+}
@@ -0,0 +1,237 @@
+// Package expr 訂閱條件式語言
+//
+// 條件式以指標輸出組合出觸發條件，例如：
+//
+//	close > lrc(42, 2, "4h").upper and rsi(14, "1h") < 70 and vol_ratio("1m") > 5
+//
+// 建立訂閱時以 Compile 完成語法與型別檢查，監控時以 Program.Eval 針對每個幣種求值。
+// 函數參數必須是常數，因此編譯時就能得知需要哪些 K 線週期。
+package expr
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"cryptowatch/internal/indicators"
+)
+
+// DataSource 條件式求值時的資料來源（單一幣種）
+type DataSource interface {
+	// Price 當前價格
+	Price() (float64, error)
+	// Closes 指定週期的收盤價，最新的在最後（至少 Lookback 根時結果才完整）
+	Closes(interval string) ([]float64, error)
+	// Volumes 指定週期的成交量，最新的在最後
+	Volumes(interval string) ([]float64, error)
+}
+
+// 條件式大小限制
+const (
+	MaxSourceBytes = 4 * 1024 // 條件式最大長度
+	MaxDepth       = 64       // 括號、函數呼叫與一元運算的最大巢狀深度
+)
+
+// Program 編譯後的條件式
+type Program struct {
+	source    string
+	root      node
+	intervals []string
+}
+
+// Compile 解析並檢查條件式，結果必須是布林值
+func Compile(source string) (*Program, error) {
+	if len(source) > MaxSourceBytes {
+		return nil, errorf(0, "條件式長度超過上限 %d bytes", MaxSourceBytes)
+	}
+
+	root, err := parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &checker{intervals: make(map[string]bool)}
+	t, err := c.check(root)
+	if err != nil {
+		return nil, err
+	}
+	if t != typeBool {
+		return nil, errorf(0, "條件式結果必須是布林值（比較或 and/or），目前是%s", t)
+	}
+
+	intervals := make([]string, 0, len(c.intervals))
+	for interval := range c.intervals {
+		intervals = append(intervals, interval)
+	}
+	sort.Strings(intervals)
+
+	return &Program{source: source, root: root, intervals: intervals}, nil
+}
+
+// String 返回原始條件式
+func (p *Program) String() string {
+	return p.source
+}
+
+// Intervals 條件式使用到的 K 線週期
+func (p *Program) Intervals() []string {
+	return p.intervals
+}
+
+// Eval 對資料來源求值
+func (p *Program) Eval(src DataSource) (bool, error) {
+	e := &evaluator{src: src}
+	v, err := e.eval(p.root)
+	if err != nil {
+		return false, err
+	}
+	return v.b, nil
+}
+
+// value 求值結果
+type value struct {
+	num float64
+	b   bool
+	str string
+	lrc indicators.LRCResult
+}
+
+// evaluator 求值器
+type evaluator struct {
+	src DataSource
+}
+
+func (e *evaluator) eval(n node) (value, error) {
+	switch n := n.(type) {
+	case *numberNode:
+		return value{num: n.value}, nil
+	case *stringNode:
+		return value{str: n.value}, nil
+	case *boolNode:
+		return value{b: n.value}, nil
+
+	case *identNode:
+		price, err := e.src.Price()
+		if err != nil {
+			return value{}, err
+		}
+		return value{num: price}, nil
+
+	case *unaryNode:
+		x, err := e.eval(n.x)
+		if err != nil {
+			return value{}, err
+		}
+		if n.op == "not" {
+			return value{b: !x.b}, nil
+		}
+		return value{num: -x.num}, nil
+
+	case *binaryNode:
+		return e.evalBinary(n)
+
+	case *fieldNode:
+		x, err := e.eval(n.x)
+		if err != nil {
+			return value{}, err
+		}
+		return value{num: lrcField(x.lrc, n.name)}, nil
+
+	case *callNode:
+		args := make([]value, len(n.args))
+		for i, arg := range n.args {
+			v, err := e.eval(arg)
+			if err != nil {
+				return value{}, err
+			}
+			args[i] = v
+		}
+		v, err := builtins[n.name].eval(e.src, args)
+		if err != nil {
+			return value{}, fmt.Errorf("%s: %v", n.name, err)
+		}
+		return v, nil
+	}
+	return value{}, fmt.Errorf("無法求值的節點 %T", n)
+}
+
+func (e *evaluator) evalBinary(n *binaryNode) (value, error) {
+	left, err := e.eval(n.left)
+	if err != nil {
+		return value{}, err
+	}
+
+	// and / or 短路求值，避免不必要的資料請求
+	switch n.op {
+	case "and":
+		if !left.b {
+			return value{b: false}, nil
+		}
+		return e.eval(n.right)
+	case "or":
+		if left.b {
+			return value{b: true}, nil
+		}
+		return e.eval(n.right)
+	}
+
+	right, err := e.eval(n.right)
+	if err != nil {
+		return value{}, err
+	}
+
+	// NaN（例如暖機期不足的指標）參與的比較一律為 false，包含 !=
+	switch n.op {
+	case "<", "<=", ">", ">=", "==", "!=":
+		if math.IsNaN(left.num) || math.IsNaN(right.num) {
+			return value{b: false}, nil
+		}
+	}
+	switch n.op {
+	case "<":
+		return value{b: left.num < right.num}, nil
+	case "<=":
+		return value{b: left.num <= right.num}, nil
+	case ">":
+		return value{b: left.num > right.num}, nil
+	case ">=":
+		return value{b: left.num >= right.num}, nil
+	case "==":
+		return value{b: left.num == right.num && left.b == right.b}, nil
+	case "!=":
+		return value{b: left.num != right.num || left.b != right.b}, nil
+	case "+":
+		return value{num: left.num + right.num}, nil
+	case "-":
+		return value{num: left.num - right.num}, nil
+	case "*":
+		return value{num: left.num * right.num}, nil
+	case "/":
+		if right.num == 0 {
+			return value{num: math.NaN()}, nil
+		}
+		return value{num: left.num / right.num}, nil
+	}
+	return value{}, fmt.Errorf("未知的運算子 %q", n.op)
+}
+
+// lrcField 取出 LRC 結果的欄位
+func lrcField(lrc indicators.LRCResult, name string) float64 {
+	switch name {
+	case "upper":
+		return lrc.UpperBand
+	case "lower":
+		return lrc.LowerBand
+	case "center":
+		return lrc.CenterLine
+	case "slope":
+		return lrc.Slope
+	case "slope_pct":
+		return lrc.SlopePct
+	case "r":
+		return lrc.PearsonR
+	case "dev":
+		return lrc.Deviation
+	}
+	return math.NaN()
+}
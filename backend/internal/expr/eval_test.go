@@ -0,0 +1,97 @@
+package expr
+
+import (
+	"errors"
+	"testing"
+)
+
+// fakeSource 固定價格與 K 線的資料來源
+type fakeSource struct {
+	price   float64
+	closes  []float64
+	volumes []float64
+}
+
+func (s fakeSource) Price() (float64, error) {
+	return s.price, nil
+}
+
+func (s fakeSource) Closes(interval string) ([]float64, error) {
+	if s.closes == nil {
+		return nil, errors.New("no closes")
+	}
+	return s.closes, nil
+}
+
+func (s fakeSource) Volumes(interval string) ([]float64, error) {
+	if s.volumes == nil {
+		return nil, errors.New("no volumes")
+	}
+	return s.volumes, nil
+}
+
+func TestEval(t *testing.T) {
+	src := fakeSource{
+		price:   110,
+		closes:  []float64{100, 102, 104, 106, 108, 110},
+		volumes: []float64{10, 10, 10, 10, 50},
+	}
+
+	tests := []struct {
+		name     string
+		expr     string
+		noCloses bool // 資料來源沒有 K 線，用來確認短路時不會讀取
+		want     bool
+	}{
+		{"greater", "close > 100", false, true},
+		{"less or equal", "price <= 109", false, false},
+		{"arithmetic precedence", "close - 10 * 2 == 90", false, true},
+		{"parentheses", "(close - 10) * 2 == 200", false, true},
+		{"unary minus", "-close < 0", false, true},
+		{"not", "not close > 200", false, true},
+		{"and", "close > 100 and close < 105", false, false},
+		{"or", "close > 200 or close > 100", false, true},
+		{"bool equality", "(close > 1) == true", false, true},
+		{"bool inequality", "(close > 1) != false", false, true},
+		{"sma", "sma(5, \"1h\") == 106", false, true},
+		{"change", "change(5, \"1h\") == 10", false, true},
+		{"vol_ratio", "vol_ratio(\"1m\", 4) == 5", false, true},
+		{"NaN less", "close / 0 < 1", false, false},
+		{"NaN greater", "close / 0 > 1", false, false},
+		{"NaN equal", "close / 0 == close / 0", false, false},
+		{"NaN not equal", "close / 0 != 1", false, false},
+		{"NaN negated", "not (close / 0 != 1)", false, true},
+		{"and short circuit", "close > 200 and rsi(14, \"4h\") > 0", true, false},
+		{"or short circuit", "close > 100 or rsi(14, \"4h\") > 0", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			s := src
+			if tt.noCloses {
+				s.closes = nil
+			}
+			got, err := program.Eval(s)
+			if err != nil {
+				t.Fatalf("Eval() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalDataError(t *testing.T) {
+	program, err := Compile("sma(5, \"1h\") > 0")
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if _, err := program.Eval(fakeSource{price: 1}); err == nil {
+		t.Error("Eval() error = nil, want data source error")
+	}
+}
@@ -0,0 +1,120 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind 詞法單元類型
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOp     // + - * / < <= > >= == !=
+	tokenLParen // (
+	tokenRParen // )
+	tokenComma  // ,
+	tokenDot    // .
+)
+
+// token 詞法單元
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+// lex 將條件式切分為詞法單元
+func lex(src string) ([]token, error) {
+	tokens := make([]token, 0, 16)
+	i := 0
+	for i < len(src) {
+		c := rune(src[i])
+
+		switch {
+		case unicode.IsSpace(c):
+			i++
+
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(src) && unicode.IsDigit(rune(src[i+1]))):
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			text := src[start:i]
+			num, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, errorf(start, "無效的數字 %q", text)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, num: num, pos: start})
+
+		case c == '"' || c == '\'':
+			start := i
+			end := strings.IndexByte(src[i+1:], byte(c))
+			if end < 0 {
+				return nil, errorf(start, "字串缺少結尾引號")
+			}
+			text := src[i+1 : i+1+end]
+			i += end + 2
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: start})
+
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: src[start:i], pos: start})
+
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+		case c == '.':
+			tokens = append(tokens, token{kind: tokenDot, text: ".", pos: i})
+			i++
+
+		case strings.ContainsRune("+-*/<>=!", c):
+			start := i
+			op := string(c)
+			if i+1 < len(src) && src[i+1] == '=' && strings.ContainsRune("<>=!", c) {
+				op += "="
+			}
+			if op == "=" || op == "!" {
+				return nil, errorf(start, "無效的運算子 %q，請使用 == 或 !=", op)
+			}
+			i += len(op)
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: start})
+
+		default:
+			return nil, errorf(i, "無法識別的字元 %q", c)
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(src)})
+	return tokens, nil
+}
+
+// Error 條件式的語法或型別錯誤
+type Error struct {
+	Pos int    // 錯誤發生的字元位置（從 0 開始）
+	Msg string // 錯誤說明
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("位置 %d: %s", e.Pos, e.Msg)
+}
+
+// errorf 建立條件式錯誤
+func errorf(pos int, format string, args ...interface{}) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,329 @@
+package expr
+
+// node 語法樹節點
+type node interface {
+	position() int
+}
+
+type (
+	// numberNode 數字常數
+	numberNode struct {
+		pos   int
+		value float64
+	}
+
+	// stringNode 字串常數（只能作為函數參數，例如 K 線週期）
+	stringNode struct {
+		pos   int
+		value string
+	}
+
+	// boolNode 布林常數
+	boolNode struct {
+		pos   int
+		value bool
+	}
+
+	// identNode 變數（例如 close）
+	identNode struct {
+		pos  int
+		name string
+	}
+
+	// unaryNode 一元運算（-x、not x）
+	unaryNode struct {
+		pos int
+		op  string
+		x   node
+	}
+
+	// binaryNode 二元運算（算術、比較、and、or）
+	binaryNode struct {
+		pos         int
+		op          string
+		left, right node
+	}
+
+	// callNode 函數呼叫
+	callNode struct {
+		pos  int
+		name string
+		args []node
+	}
+
+	// fieldNode 欄位存取（例如 lrc(...).upper）
+	fieldNode struct {
+		pos  int
+		x    node
+		name string
+	}
+)
+
+func (n *numberNode) position() int { return n.pos }
+func (n *stringNode) position() int { return n.pos }
+func (n *boolNode) position() int   { return n.pos }
+func (n *identNode) position() int  { return n.pos }
+func (n *unaryNode) position() int  { return n.pos }
+func (n *binaryNode) position() int { return n.pos }
+func (n *callNode) position() int   { return n.pos }
+func (n *fieldNode) position() int  { return n.pos }
+
+// parser 遞迴下降解析器
+// 優先順序（低到高）：or、and、not、比較、+ -、* /、一元 -、呼叫與欄位存取
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+// parse 解析條件式為語法樹
+func parse(src string) (node, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, errorf(tok.pos, "多餘的內容 %q", tok.text)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// enter 進入一層巢狀（括號、函數呼叫、一元運算），超過 MaxDepth 時返回錯誤
+func (p *parser) enter(pos int) error {
+	p.depth++
+	if p.depth > MaxDepth {
+		return errorf(pos, "巢狀深度超過上限 %d", MaxDepth)
+	}
+	return nil
+}
+
+// leave 離開一層巢狀
+func (p *parser) leave() {
+	p.depth--
+}
+
+// isKeyword 判斷目前的詞法單元是否為指定關鍵字
+func (p *parser) isKeyword(word string) bool {
+	tok := p.peek()
+	return tok.kind == tokenIdent && tok.text == word
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		tok := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{pos: tok.pos, op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		tok := p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{pos: tok.pos, op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.isKeyword("not") {
+		tok := p.next()
+		if err := p.enter(tok.pos); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{pos: tok.pos, op: "not", x: x}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	if tok.kind == tokenOp {
+		switch tok.text {
+		case "<", "<=", ">", ">=", "==", "!=":
+			p.next()
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			return &binaryNode{pos: tok.pos, op: tok.text, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.kind != tokenOp || (tok.text != "+" && tok.text != "-") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{pos: tok.pos, op: tok.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.kind != tokenOp || (tok.text != "*" && tok.text != "/") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{pos: tok.pos, op: tok.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	tok := p.peek()
+	if tok.kind == tokenOp && tok.text == "-" {
+		p.next()
+		if err := p.enter(tok.pos); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{pos: tok.pos, op: "-", x: x}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenDot {
+		p.next()
+		tok := p.next()
+		if tok.kind != tokenIdent {
+			return nil, errorf(tok.pos, "欄位名稱必須接在 . 之後")
+		}
+		x = &fieldNode{pos: tok.pos, x: x, name: tok.text}
+	}
+	return x, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		return &numberNode{pos: tok.pos, value: tok.num}, nil
+
+	case tokenString:
+		return &stringNode{pos: tok.pos, value: tok.text}, nil
+
+	case tokenIdent:
+		switch tok.text {
+		case "true", "false":
+			return &boolNode{pos: tok.pos, value: tok.text == "true"}, nil
+		case "and", "or", "not":
+			return nil, errorf(tok.pos, "關鍵字 %q 缺少運算元", tok.text)
+		}
+		if p.peek().kind != tokenLParen {
+			return &identNode{pos: tok.pos, name: tok.text}, nil
+		}
+		p.next()
+		if err := p.enter(tok.pos); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		args := make([]node, 0, 3)
+		if p.peek().kind != tokenRParen {
+			for {
+				arg, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+				if p.peek().kind != tokenComma {
+					break
+				}
+				p.next()
+			}
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, errorf(closing.pos, "函數 %s 缺少右括號", tok.text)
+		}
+		return &callNode{pos: tok.pos, name: tok.text, args: args}, nil
+
+	case tokenLParen:
+		if err := p.enter(tok.pos); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, errorf(closing.pos, "缺少右括號")
+		}
+		return x, nil
+
+	case tokenEOF:
+		return nil, errorf(tok.pos, "條件式不完整")
+	}
+	return nil, errorf(tok.pos, "非預期的 %q", tok.text)
+}
@@ -0,0 +1,107 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// render 以完整括號輸出語法樹，方便檢查優先順序
+func render(n node) string {
+	switch n := n.(type) {
+	case *numberNode:
+		return fmt.Sprint(n.value)
+	case *stringNode:
+		return fmt.Sprintf("%q", n.value)
+	case *boolNode:
+		return fmt.Sprint(n.value)
+	case *identNode:
+		return n.name
+	case *unaryNode:
+		return fmt.Sprintf("(%s %s)", n.op, render(n.x))
+	case *binaryNode:
+		return fmt.Sprintf("(%s %s %s)", render(n.left), n.op, render(n.right))
+	case *callNode:
+		args := make([]string, len(n.args))
+		for i, arg := range n.args {
+			args[i] = render(arg)
+		}
+		return fmt.Sprintf("%s(%s)", n.name, strings.Join(args, ", "))
+	case *fieldNode:
+		return fmt.Sprintf("%s.%s", render(n.x), n.name)
+	}
+	return "?"
+}
+
+func TestParsePrecedence(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"1 + 2 * 3 > 4", "((1 + (2 * 3)) > 4)"},
+		{"1 - 2 - 3 < 0", "(((1 - 2) - 3) < 0)"},
+		{"(1 + 2) * 3 >= 9", "(((1 + 2) * 3) >= 9)"},
+		{"a or b and c", "(a or (b and c))"},
+		{"not a and b", "((not a) and b)"},
+		{"not close > 1", "(not (close > 1))"},
+		{"-close * 2 < 0", "(((- close) * 2) < 0)"},
+		{"-lrc(42, 2, \"4h\").upper", "(- lrc(42, 2, \"4h\").upper)"},
+		{"close > lrc(42, 2, '4h').upper and rsi(14, \"1h\") < 70", "((close > lrc(42, 2, \"4h\").upper) and (rsi(14, \"1h\") < 70))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			n, err := parse(tt.src)
+			if err != nil {
+				t.Fatalf("parse() error = %v", err)
+			}
+			if got := render(n); got != tt.want {
+				t.Errorf("parse() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileErrorPosition(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantPos int
+	}{
+		{"incomplete", "close >", 7},
+		{"unexpected operator", "close > > 1", 8},
+		{"missing right paren", "(close > 1", 10},
+		{"missing call right paren", "rsi(14, \"1h\" > 50", 17},
+		{"single equals", "close = 1", 6},
+		{"trailing tokens", "close > 1 2", 10},
+		{"unterminated string", "rsi(14, \"1h) > 50", 8},
+		{"unknown variable", "foo > 1", 0},
+		{"not boolean", "close + 1", 0},
+		{"period out of range", "rsi(0, \"1h\") > 50", 4},
+		{"nesting too deep", strings.Repeat("(", MaxDepth+1) + "close > 1" + strings.Repeat(")", MaxDepth+1), MaxDepth},
+		{"unary nesting too deep", strings.Repeat("-", MaxDepth+1) + "close > 1", MaxDepth},
+		{"not nesting too deep", strings.Repeat("not ", MaxDepth+1) + "close > 1", MaxDepth * 4},
+		{"source too long", "close > " + strings.Repeat("1", MaxSourceBytes), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.src)
+			var exprErr *Error
+			if !errors.As(err, &exprErr) {
+				t.Fatalf("Compile() error = %v, want *Error", err)
+			}
+			if exprErr.Pos != tt.wantPos {
+				t.Errorf("Compile() error position = %d (%v), want %d", exprErr.Pos, err, tt.wantPos)
+			}
+		})
+	}
+}
+
+func TestCompileNestingLimit(t *testing.T) {
+	src := strings.Repeat("(", MaxDepth) + "close > 1" + strings.Repeat(")", MaxDepth)
+	if _, err := Compile(src); err != nil {
+		t.Errorf("Compile() with %d nested parentheses error = %v", MaxDepth, err)
+	}
+}
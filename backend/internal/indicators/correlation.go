@@ -0,0 +1,78 @@
+package indicators
+
+import "math"
+
+// LogReturns 計算對數報酬率序列，長度為 len(closes)-1
+func LogReturns(closes []float64) []float64 {
+	if len(closes) < 2 {
+		return nil
+	}
+	returns := make([]float64, len(closes)-1)
+	for i := 1; i < len(closes); i++ {
+		if closes[i-1] <= 0 || closes[i] <= 0 {
+			returns[i-1] = 0
+			continue
+		}
+		returns[i-1] = math.Log(closes[i] / closes[i-1])
+	}
+	return returns
+}
+
+// Correlation 計算兩個序列的皮爾森相關係數
+// 長度不同時以兩者尾端對齊，任一序列沒有變異時返回 0
+func Correlation(a, b []float64) float64 {
+	a, b = alignTail(a, b)
+	if len(a) < 2 {
+		return 0
+	}
+	meanA, meanB := mean(a), mean(b)
+	var cov, varA, varB float64
+	for i := range a {
+		da, db := a[i]-meanA, b[i]-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varA*varB)
+}
+
+// Beta 計算資產報酬相對於基準報酬的 Beta 值：Cov(asset, market) / Var(market)
+// 長度不同時以兩者尾端對齊，基準沒有變異時返回 0
+func Beta(asset, market []float64) float64 {
+	asset, market = alignTail(asset, market)
+	if len(asset) < 2 {
+		return 0
+	}
+	meanA, meanM := mean(asset), mean(market)
+	var cov, varM float64
+	for i := range asset {
+		dm := market[i] - meanM
+		cov += (asset[i] - meanA) * dm
+		varM += dm * dm
+	}
+	if varM == 0 {
+		return 0
+	}
+	return cov / varM
+}
+
+// alignTail 截取兩個序列共同長度的尾端
+func alignTail(a, b []float64) ([]float64, []float64) {
+	n := min(len(a), len(b))
+	return a[len(a)-n:], b[len(b)-n:]
+}
+
+// mean 平均值
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
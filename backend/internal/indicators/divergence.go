@@ -0,0 +1,140 @@
+package indicators
+
+import "math"
+
+// DivergenceType 背離類型
+type DivergenceType string
+
+const (
+	DivergenceRegularBullish DivergenceType = "regular_bullish" // 價格更低的低點，震盪指標更高的低點（反轉向上）
+	DivergenceRegularBearish DivergenceType = "regular_bearish" // 價格更高的高點，震盪指標更低的高點（反轉向下）
+	DivergenceHiddenBullish  DivergenceType = "hidden_bullish"  // 價格更高的低點，震盪指標更低的低點（上升趨勢延續）
+	DivergenceHiddenBearish  DivergenceType = "hidden_bearish"  // 價格更低的高點，震盪指標更高的高點（下降趨勢延續）
+)
+
+// IsBullish 是否為看漲背離
+func (t DivergenceType) IsBullish() bool {
+	return t == DivergenceRegularBullish || t == DivergenceHiddenBullish
+}
+
+// DivergenceConfig 背離偵測配置
+type DivergenceConfig struct {
+	PivotLeft  int // 轉折點左側需要的 K 線根數，預設 5
+	PivotRight int // 轉折點右側需要的 K 線根數（確認延遲），預設 5
+	MinBars    int // 兩個轉折點之間最少相隔的根數，預設 5
+	MaxBars    int // 兩個轉折點之間最多相隔的根數，預設 60
+}
+
+// DefaultDivergenceConfig 返回預設配置
+func DefaultDivergenceConfig() DivergenceConfig {
+	return DivergenceConfig{
+		PivotLeft:  5,
+		PivotRight: 5,
+		MinBars:    5,
+		MaxBars:    60,
+	}
+}
+
+// Pivot 轉折點
+type Pivot struct {
+	Index      int     // K 線索引
+	Price      float64 // 轉折點價格（高點為最高價，低點為最低價）
+	Oscillator float64 // 同一根 K 線的震盪指標值
+}
+
+// Divergence 背離結果
+type Divergence struct {
+	Type     DivergenceType
+	Previous Pivot // 較早的轉折點
+	Latest   Pivot // 較新的轉折點
+}
+
+// FindPivots 找出轉折點：左右各 left、right 根內的最高（pivot high）或最低（pivot low）
+// 與 Pine Script ta.pivothigh / ta.pivotlow 相同，最新的 right 根無法確認轉折點
+func FindPivots(values []float64, left, right int, high bool) []int {
+	pivots := make([]int, 0)
+	if left <= 0 || right <= 0 {
+		return pivots
+	}
+
+	for i := left; i < len(values)-right; i++ {
+		isPivot := true
+		for j := i - left; j <= i+right && isPivot; j++ {
+			if j == i {
+				continue
+			}
+			if high {
+				isPivot = values[i] > values[j]
+			} else {
+				isPivot = values[i] < values[j]
+			}
+		}
+		if isPivot {
+			pivots = append(pivots, i)
+		}
+	}
+	return pivots
+}
+
+// DetectDivergences 偵測最新確認的轉折點與前一個轉折點之間的背離
+// highs, lows: K 線最高、最低價；oscillator: 同長度的震盪指標序列（RSI、MACD 柱狀圖等）
+// 只在最新的轉折點剛好於最新一根 K 線確認時返回結果；同一根 K 線收盤前會重複返回同一組轉折點，
+// 盤中檢查的呼叫端需自行記錄已通知的轉折點
+func DetectDivergences(highs, lows, oscillator []float64, config DivergenceConfig) []Divergence {
+	n := len(oscillator)
+	if len(highs) != n || len(lows) != n {
+		return nil
+	}
+
+	confirmed := n - 1 - config.PivotRight
+	divergences := make([]Divergence, 0)
+
+	// 低點：看漲背離
+	if prev, last, ok := latestPivotPair(lows, oscillator, config, false, confirmed); ok {
+		switch {
+		case last.Price < prev.Price && last.Oscillator > prev.Oscillator:
+			divergences = append(divergences, Divergence{Type: DivergenceRegularBullish, Previous: prev, Latest: last})
+		case last.Price > prev.Price && last.Oscillator < prev.Oscillator:
+			divergences = append(divergences, Divergence{Type: DivergenceHiddenBullish, Previous: prev, Latest: last})
+		}
+	}
+
+	// 高點：看跌背離
+	if prev, last, ok := latestPivotPair(highs, oscillator, config, true, confirmed); ok {
+		switch {
+		case last.Price > prev.Price && last.Oscillator < prev.Oscillator:
+			divergences = append(divergences, Divergence{Type: DivergenceRegularBearish, Previous: prev, Latest: last})
+		case last.Price < prev.Price && last.Oscillator > prev.Oscillator:
+			divergences = append(divergences, Divergence{Type: DivergenceHiddenBearish, Previous: prev, Latest: last})
+		}
+	}
+
+	return divergences
+}
+
+// latestPivotPair 取出最新的兩個轉折點，最新的轉折點必須位於 confirmed
+func latestPivotPair(prices, oscillator []float64, config DivergenceConfig, high bool, confirmed int) (Pivot, Pivot, bool) {
+	indexes := FindPivots(prices, config.PivotLeft, config.PivotRight, high)
+	if len(indexes) < 2 || indexes[len(indexes)-1] != confirmed {
+		return Pivot{}, Pivot{}, false
+	}
+
+	last := indexes[len(indexes)-1]
+	for k := len(indexes) - 2; k >= 0; k-- {
+		prev := indexes[k]
+		gap := last - prev
+		if gap < config.MinBars {
+			continue
+		}
+		if gap > config.MaxBars {
+			break
+		}
+		if math.IsNaN(oscillator[prev]) || math.IsNaN(oscillator[last]) {
+			return Pivot{}, Pivot{}, false
+		}
+		return Pivot{Index: prev, Price: prices[prev], Oscillator: oscillator[prev]},
+			Pivot{Index: last, Price: prices[last], Oscillator: oscillator[last]},
+			true
+	}
+	return Pivot{}, Pivot{}, false
+}
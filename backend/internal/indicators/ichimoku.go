@@ -0,0 +1,121 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+)
+
+// IchimokuConfig 一目均衡表計算配置
+type IchimokuConfig struct {
+	TenkanPeriod  int // 轉換線週期，預設 9
+	KijunPeriod   int // 基準線週期，預設 26
+	SenkouBPeriod int // 先行帶 B 週期，預設 52
+	Displacement  int // 先行帶前移／遲行線後移的根數，預設 26
+}
+
+// DefaultIchimokuConfig 返回預設配置
+func DefaultIchimokuConfig() IchimokuConfig {
+	return IchimokuConfig{
+		TenkanPeriod:  9,
+		KijunPeriod:   26,
+		SenkouBPeriod: 52,
+		Displacement:  26,
+	}
+}
+
+// IchimokuResult 一目均衡表計算結果（最新一根）
+type IchimokuResult struct {
+	Tenkan float64 // 轉換線
+	Kijun  float64 // 基準線
+
+	// 當前 K 線對應的雲（displacement 根之前計算、前移到現在的先行帶）
+	SenkouA float64 // 先行帶 A
+	SenkouB float64 // 先行帶 B
+
+	// 以最新 K 線計算、將在 displacement 根後生效的先行帶
+	FutureSenkouA float64
+	FutureSenkouB float64
+
+	Chikou      float64 // 遲行線（最新收盤價，繪製在 displacement 根之前）
+	ChikouPrice float64 // 遲行線對照的 displacement 根之前的收盤價
+
+	// 事件：最新一根相對前一根的變化
+	CloudBreakUp   bool // 收盤由雲內／雲下突破至雲上
+	CloudBreakDown bool // 收盤由雲內／雲上跌破至雲下
+	TKCrossBull    bool // 轉換線上穿基準線
+	TKCrossBear    bool // 轉換線下穿基準線
+}
+
+// CloudTop 當前雲的上緣
+func (r IchimokuResult) CloudTop() float64 {
+	return math.Max(r.SenkouA, r.SenkouB)
+}
+
+// CloudBottom 當前雲的下緣
+func (r IchimokuResult) CloudBottom() float64 {
+	return math.Min(r.SenkouA, r.SenkouB)
+}
+
+// CalculateIchimoku 計算一目均衡表
+// highs, lows, closes: K 線的最高、最低、收盤價，最新的在最後，長度需一致
+// 至少需要 SenkouBPeriod + Displacement + 1 根 K 線，才能判斷最新一根的雲突破
+func CalculateIchimoku(highs, lows, closes []float64, config IchimokuConfig) (IchimokuResult, error) {
+	n := len(closes)
+	if len(highs) != n || len(lows) != n {
+		return IchimokuResult{}, fmt.Errorf("K 線序列長度不一致")
+	}
+	if config.TenkanPeriod <= 0 || config.KijunPeriod <= 0 || config.SenkouBPeriod <= 0 || config.Displacement <= 0 {
+		return IchimokuResult{}, fmt.Errorf("一目均衡表週期必須大於 0")
+	}
+	required := config.SenkouBPeriod + config.Displacement + 1
+	if n < required {
+		return IchimokuResult{}, fmt.Errorf("數據長度不足，需要至少 %d 筆數據，目前只有 %d 筆", required, n)
+	}
+
+	tenkan := midpointSeries(highs, lows, config.TenkanPeriod)
+	kijun := midpointSeries(highs, lows, config.KijunPeriod)
+	senkouB := midpointSeries(highs, lows, config.SenkouBPeriod)
+	senkouA := make([]float64, n)
+	for i := range senkouA {
+		senkouA[i] = (tenkan[i] + kijun[i]) / 2
+	}
+
+	last, prev := n-1, n-2
+	d := config.Displacement
+
+	// 第 i 根的雲由第 i-d 根計算
+	cloudTop := func(i int) float64 { return math.Max(senkouA[i-d], senkouB[i-d]) }
+	cloudBottom := func(i int) float64 { return math.Min(senkouA[i-d], senkouB[i-d]) }
+
+	result := IchimokuResult{
+		Tenkan:        tenkan[last],
+		Kijun:         kijun[last],
+		SenkouA:       senkouA[last-d],
+		SenkouB:       senkouB[last-d],
+		FutureSenkouA: senkouA[last],
+		FutureSenkouB: senkouB[last],
+		Chikou:        closes[last],
+		ChikouPrice:   closes[last-d],
+
+		CloudBreakUp:   closes[last] > cloudTop(last) && closes[prev] <= cloudTop(prev),
+		CloudBreakDown: closes[last] < cloudBottom(last) && closes[prev] >= cloudBottom(prev),
+		TKCrossBull:    tenkan[last] > kijun[last] && tenkan[prev] <= kijun[prev],
+		TKCrossBear:    tenkan[last] < kijun[last] && tenkan[prev] >= kijun[prev],
+	}
+
+	return result, nil
+}
+
+// midpointSeries 滾動 (最高價 + 最低價) / 2，暖機期為 NaN
+func midpointSeries(highs, lows []float64, period int) []float64 {
+	out := nanSeries(len(highs))
+	for i := period - 1; i < len(highs); i++ {
+		hh, ll := highs[i], lows[i]
+		for j := i - period + 1; j < i; j++ {
+			hh = math.Max(hh, highs[j])
+			ll = math.Min(ll, lows[j])
+		}
+		out[i] = (hh + ll) / 2
+	}
+	return out
+}
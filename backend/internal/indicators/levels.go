@@ -0,0 +1,165 @@
+package indicators
+
+import (
+	"math"
+	"sort"
+)
+
+// LevelConfig 支撐壓力位偵測配置
+type LevelConfig struct {
+	PivotLookback int     // 擺動高低點左右確認根數，預設 5
+	ZoneWidthPct  float64 // 合併為同一區域的價格距離（佔價格百分比），預設 0.5
+	VolumeBins    int     // 成交量分佈的價格分箱數量，預設 50
+	MaxLevels     int     // 最多保留的價位數量（依強度），預設 12
+}
+
+// DefaultLevelConfig 返回預設配置
+func DefaultLevelConfig() LevelConfig {
+	return LevelConfig{
+		PivotLookback: 5,
+		ZoneWidthPct:  0.5,
+		VolumeBins:    50,
+		MaxLevels:     12,
+	}
+}
+
+// Level 水平支撐壓力區域
+type Level struct {
+	Price       float64 // 區域代表價格
+	Lower       float64 // 區域下緣
+	Upper       float64 // 區域上緣
+	Touches     int     // 區域內的擺動高低點數量
+	VolumeShare float64 // 區域內成交量密集區佔總成交量的比例（0~1）
+	Strength    float64 // 強度：觸及次數 + 成交量比例 × 10
+}
+
+// levelPoint 候選價位
+type levelPoint struct {
+	price  float64
+	swing  bool    // 來自擺動高低點
+	volume float64 // 來自成交量密集區時的成交量比例
+}
+
+// DetectLevels 從擺動高低點與成交量密集區偵測水平支撐壓力區域
+// highs, lows, closes, volumes: 已收盤 K 線資料，長度需一致；結果依價格由低到高排序
+func DetectLevels(highs, lows, closes, volumes []float64, config LevelConfig) []Level {
+	n := len(closes)
+	if n == 0 || len(highs) != n || len(lows) != n || len(volumes) != n {
+		return nil
+	}
+
+	points := make([]levelPoint, 0)
+	for _, i := range FindPivots(highs, config.PivotLookback, config.PivotLookback, true) {
+		points = append(points, levelPoint{price: highs[i], swing: true})
+	}
+	for _, i := range FindPivots(lows, config.PivotLookback, config.PivotLookback, false) {
+		points = append(points, levelPoint{price: lows[i], swing: true})
+	}
+	points = append(points, volumeNodes(highs, lows, closes, volumes, config.VolumeBins)...)
+	if len(points) == 0 {
+		return nil
+	}
+
+	// 依價格排序後，將距離群集均價 ZoneWidthPct 以內的點合併為同一區域
+	sort.Slice(points, func(a, b int) bool { return points[a].price < points[b].price })
+
+	levels := make([]Level, 0)
+	var members []levelPoint
+	flush := func() {
+		if len(members) > 0 {
+			levels = append(levels, buildLevel(members))
+		}
+		members = members[:0]
+	}
+	var sum float64
+	for _, p := range points {
+		if len(members) > 0 {
+			mean := sum / float64(len(members))
+			if math.Abs(p.price-mean)/mean*100 > config.ZoneWidthPct {
+				flush()
+				sum = 0
+			}
+		}
+		members = append(members, p)
+		sum += p.price
+	}
+	flush()
+
+	// 只保留最強的 MaxLevels 個區域
+	if config.MaxLevels > 0 && len(levels) > config.MaxLevels {
+		sort.Slice(levels, func(a, b int) bool { return levels[a].Strength > levels[b].Strength })
+		levels = levels[:config.MaxLevels]
+	}
+	sort.Slice(levels, func(a, b int) bool { return levels[a].Price < levels[b].Price })
+
+	return levels
+}
+
+// buildLevel 由群集內的候選價位建立區域
+func buildLevel(members []levelPoint) Level {
+	level := Level{Lower: members[0].price, Upper: members[0].price}
+	var sum float64
+	for _, m := range members {
+		sum += m.price
+		level.Lower = math.Min(level.Lower, m.price)
+		level.Upper = math.Max(level.Upper, m.price)
+		if m.swing {
+			level.Touches++
+		}
+		level.VolumeShare += m.volume
+	}
+	level.Price = sum / float64(len(members))
+	level.Strength = float64(level.Touches) + level.VolumeShare*10
+	return level
+}
+
+// volumeNodes 找出成交量分佈中的密集區（高於平均一個標準差的局部高點）
+// 每根 K 線的成交量歸入其典型價格 (H+L+C)/3 所在的分箱
+func volumeNodes(highs, lows, closes, volumes []float64, bins int) []levelPoint {
+	if bins <= 2 {
+		return nil
+	}
+
+	minPrice, maxPrice := lows[0], highs[0]
+	for i := range highs {
+		minPrice = math.Min(minPrice, lows[i])
+		maxPrice = math.Max(maxPrice, highs[i])
+	}
+	if maxPrice <= minPrice {
+		return nil
+	}
+
+	binSize := (maxPrice - minPrice) / float64(bins)
+	histogram := make([]float64, bins)
+	var total float64
+	for i := range closes {
+		typical := (highs[i] + lows[i] + closes[i]) / 3
+		bin := int((typical - minPrice) / binSize)
+		if bin >= bins {
+			bin = bins - 1
+		}
+		histogram[bin] += volumes[i]
+		total += volumes[i]
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	mean := total / float64(bins)
+	threshold := mean + standardDeviation(histogram, mean)
+
+	nodes := make([]levelPoint, 0)
+	for i, v := range histogram {
+		if v <= threshold {
+			continue
+		}
+		if (i > 0 && histogram[i-1] > v) || (i < bins-1 && histogram[i+1] > v) {
+			continue
+		}
+		nodes = append(nodes, levelPoint{
+			price:  minPrice + (float64(i)+0.5)*binSize,
+			volume: v / total,
+		})
+	}
+	return nodes
+}
@@ -12,6 +12,8 @@ type LRCResult struct {
 	LowerBand  float64 // 下軌 (中線 - dev * deviation)
 	Slope      float64 // 斜率
 	Deviation  float64 // 標準差
+	PearsonR   float64 // 皮爾森相關係數，|R| 越接近 1 代表通道越乾淨
+	SlopePct   float64 // 標準化斜率：每根 K 線變動佔均價的百分比
 }
 
 // LRCConfig LRC 計算配置
@@ -41,7 +43,7 @@ func CalculateLRC(prices []float64, length int, devMultiplier float64) (LRCResul
 	// window[0] 是最舊的數據 (x=0)，window[length-1] 是最新的數據 (x=length-1)
 	window := prices[len(prices)-length:]
 
-	var sumX, sumY, sumXY, sumXX float64
+	var sumX, sumY, sumXY, sumXX, sumYY float64
 	n := float64(length)
 
 	// 1. 計算線性回歸所需的總和 (Least Squares)
@@ -53,6 +55,7 @@ func CalculateLRC(prices []float64, length int, devMultiplier float64) (LRCResul
 		sumY += y
 		sumXY += (x * y)
 		sumXX += (x * x)
+		sumYY += (y * y)
 	}
 
 	// 2. 計算斜率 (Slope) 和 截距 (Intercept)
@@ -88,7 +91,20 @@ func CalculateLRC(prices []float64, length int, devMultiplier float64) (LRCResul
 	// 標準差 = sqrt(殘差平方和 / n)
 	deviation := math.Sqrt(sumResidualsSq / n)
 
-	// 5. 計算上下軌
+	// 5. 計算通道品質
+	// 公式: R = (n*Σxy - Σx*Σy) / sqrt((n*Σx^2 - (Σx)^2) * (n*Σy^2 - (Σy)^2))
+	pearsonR := 0.0
+	if yVariance := n*sumYY - sumY*sumY; yVariance > 0 {
+		pearsonR = (n*sumXY - sumX*sumY) / math.Sqrt(denominator*yVariance)
+	}
+
+	// 斜率以均價標準化，讓不同價位的幣種可以互相比較
+	slopePct := 0.0
+	if mean := sumY / n; mean != 0 {
+		slopePct = slope / mean * 100
+	}
+
+	// 6. 計算上下軌
 	upperBand := centerLine + (deviation * devMultiplier)
 	lowerBand := centerLine - (deviation * devMultiplier)
 
@@ -98,6 +114,8 @@ func CalculateLRC(prices []float64, length int, devMultiplier float64) (LRCResul
 		LowerBand:  lowerBand,
 		Slope:      slope,
 		Deviation:  deviation,
+		PearsonR:   pearsonR,
+		SlopePct:   slopePct,
 	}, nil
 }
 
@@ -106,3 +124,31 @@ func CalculateLRCWithConfig(prices []float64, config LRCConfig) (LRCResult, erro
 	return CalculateLRC(prices, config.Length, config.DevMultiplier)
 }
 
+// CountClosesBeyondBands 計算最近連續收盤在通道外的 K 線根數
+// closes: 已收盤 K 線的收盤價切片，最新的在最後
+// 每根 K 線都以「截至該根為止」的 LRC 通道判斷，與 Pine Script 逐根計算的行為一致
+// maxCount: 最多往回檢查的根數
+func CountClosesBeyondBands(closes []float64, length int, devMultiplier float64, maxCount int) (above int, below int) {
+	for i := 0; i < maxCount; i++ {
+		end := len(closes) - i
+		if end < length {
+			break
+		}
+
+		lrc, err := CalculateLRC(closes[:end], length, devMultiplier)
+		if err != nil {
+			break
+		}
+
+		closePrice := closes[end-1]
+		switch {
+		case closePrice > lrc.UpperBand && below == 0:
+			above++
+		case closePrice < lrc.LowerBand && above == 0:
+			below++
+		default:
+			return above, below
+		}
+	}
+	return above, below
+}
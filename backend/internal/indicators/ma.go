@@ -0,0 +1,190 @@
+package indicators
+
+import "math"
+
+// 以下函數皆返回與輸入等長的序列，暖機期不足的位置為 NaN
+// 與 Pine Script 的 ta.* 函數行為一致，方便逐根比對
+
+// SMASeries 計算簡單移動平均序列
+func SMASeries(values []float64, period int) []float64 {
+	out := nanSeries(len(values))
+	if period <= 0 {
+		return out
+	}
+
+	var sum float64
+	for i, v := range values {
+		sum += v
+		if i >= period {
+			sum -= values[i-period]
+		}
+		if i >= period-1 {
+			out[i] = sum / float64(period)
+		}
+	}
+	return out
+}
+
+// EMASeries 計算指數移動平均序列
+// 以前 period 根的 SMA 作為初始值，alpha = 2 / (period + 1)
+func EMASeries(values []float64, period int) []float64 {
+	return smoothedSeries(values, period, 2/float64(period+1))
+}
+
+// RMASeries 計算 Wilder 平滑移動平均序列（RSI 使用），alpha = 1 / period
+func RMASeries(values []float64, period int) []float64 {
+	return smoothedSeries(values, period, 1/float64(period))
+}
+
+// RSISeries 計算相對強弱指標序列
+func RSISeries(values []float64, period int) []float64 {
+	out := nanSeries(len(values))
+	if period <= 0 || len(values) < 2 {
+		return out
+	}
+
+	gains := make([]float64, len(values)-1)
+	losses := make([]float64, len(values)-1)
+	for i := 1; i < len(values); i++ {
+		change := values[i] - values[i-1]
+		if change > 0 {
+			gains[i-1] = change
+		} else {
+			losses[i-1] = -change
+		}
+	}
+
+	avgGain := RMASeries(gains, period)
+	avgLoss := RMASeries(losses, period)
+	for i := range gains {
+		if math.IsNaN(avgGain[i]) || math.IsNaN(avgLoss[i]) {
+			continue
+		}
+		switch {
+		case avgLoss[i] == 0 && avgGain[i] == 0:
+			out[i+1] = 50
+		case avgLoss[i] == 0:
+			out[i+1] = 100
+		default:
+			rs := avgGain[i] / avgLoss[i]
+			out[i+1] = 100 - 100/(1+rs)
+		}
+	}
+	return out
+}
+
+// StdevSeries 計算滾動母體標準差序列
+func StdevSeries(values []float64, period int) []float64 {
+	out := nanSeries(len(values))
+	if period <= 0 {
+		return out
+	}
+
+	means := SMASeries(values, period)
+	for i := period - 1; i < len(values); i++ {
+		out[i] = standardDeviation(values[i-period+1:i+1], means[i])
+	}
+	return out
+}
+
+// LinRegSeries 計算滾動線性回歸值序列（等同 Pine Script ta.linreg(source, length, offset)）
+func LinRegSeries(values []float64, period int, offset int) []float64 {
+	out := nanSeries(len(values))
+	if period <= 0 {
+		return out
+	}
+
+	for i := period - 1; i < len(values); i++ {
+		lrc, err := CalculateLRC(values[i-period+1:i+1], period, 0)
+		if err != nil {
+			continue
+		}
+		out[i] = lrc.CenterLine - lrc.Slope*float64(offset)
+	}
+	return out
+}
+
+// MACDHistogramSeries 計算 MACD 柱狀圖序列（MACD 線 - 訊號線）
+// MACD 線 = EMA(fast) - EMA(slow)，訊號線為 MACD 線的 EMA(signal)
+func MACDHistogramSeries(values []float64, fast, slow, signal int) []float64 {
+	out := nanSeries(len(values))
+	if fast <= 0 || slow <= 0 || signal <= 0 || len(values) < slow {
+		return out
+	}
+
+	fastEMA := EMASeries(values, fast)
+	slowEMA := EMASeries(values, slow)
+
+	// MACD 線從慢線暖機完成後才有值，訊號線只對有值的部分計算
+	start := slow - 1
+	if fast > slow {
+		start = fast - 1
+	}
+	macd := make([]float64, len(values)-start)
+	for i := range macd {
+		macd[i] = fastEMA[start+i] - slowEMA[start+i]
+	}
+
+	signalLine := EMASeries(macd, signal)
+	for i := range macd {
+		if !math.IsNaN(signalLine[i]) {
+			out[start+i] = macd[i] - signalLine[i]
+		}
+	}
+	return out
+}
+
+// TrueRangeSeries 計算真實波幅序列，第一根沒有前收盤，以最高價減最低價計算
+func TrueRangeSeries(highs, lows, closes []float64) []float64 {
+	out := make([]float64, len(closes))
+	for i := range closes {
+		out[i] = highs[i] - lows[i]
+		if i > 0 {
+			out[i] = math.Max(out[i], math.Max(math.Abs(highs[i]-closes[i-1]), math.Abs(lows[i]-closes[i-1])))
+		}
+	}
+	return out
+}
+
+// ATRSeries 計算平均真實波幅序列（真實波幅的 RMA）
+func ATRSeries(highs, lows, closes []float64, period int) []float64 {
+	return RMASeries(TrueRangeSeries(highs, lows, closes), period)
+}
+
+// Last 返回序列最後一個值，空序列返回 NaN
+func Last(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	return values[len(values)-1]
+}
+
+// smoothedSeries 以 SMA 為初始值的遞迴平滑
+func smoothedSeries(values []float64, period int, alpha float64) []float64 {
+	out := nanSeries(len(values))
+	if period <= 0 || len(values) < period {
+		return out
+	}
+
+	var sum float64
+	for i := 0; i < period; i++ {
+		sum += values[i]
+	}
+	prev := sum / float64(period)
+	out[period-1] = prev
+
+	for i := period; i < len(values); i++ {
+		prev = alpha*values[i] + (1-alpha)*prev
+		out[i] = prev
+	}
+	return out
+}
+
+// nanSeries 建立全部為 NaN 的序列
+func nanSeries(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	return out
+}
@@ -0,0 +1,164 @@
+package indicators
+
+import "math"
+
+// Candle 單根 K 線的開高低收
+type Candle struct {
+	Open  float64
+	High  float64
+	Low   float64
+	Close float64
+}
+
+// Body 實體長度
+func (c Candle) Body() float64 {
+	return math.Abs(c.Close - c.Open)
+}
+
+// Range 振幅（最高 - 最低）
+func (c Candle) Range() float64 {
+	return c.High - c.Low
+}
+
+// UpperWick 上影線長度
+func (c Candle) UpperWick() float64 {
+	return c.High - math.Max(c.Open, c.Close)
+}
+
+// LowerWick 下影線長度
+func (c Candle) LowerWick() float64 {
+	return math.Min(c.Open, c.Close) - c.Low
+}
+
+// IsBullish 是否為陽線
+func (c Candle) IsBullish() bool {
+	return c.Close > c.Open
+}
+
+// IsBearish 是否為陰線
+func (c Candle) IsBearish() bool {
+	return c.Close < c.Open
+}
+
+// CandlePattern K 線型態
+type CandlePattern string
+
+const (
+	PatternBullishEngulfing CandlePattern = "bullish_engulfing" // 看漲吞噬：陽線實體完全包覆前一根陰線實體
+	PatternBearishEngulfing CandlePattern = "bearish_engulfing" // 看跌吞噬：陰線實體完全包覆前一根陽線實體
+	PatternHammer           CandlePattern = "hammer"            // 錘子線：小實體、長下影線、幾乎無上影線
+	PatternShootingStar     CandlePattern = "shooting_star"     // 射擊之星：小實體、長上影線、幾乎無下影線
+	PatternDoji             CandlePattern = "doji"              // 十字線：實體相對振幅極小
+	PatternMorningStar      CandlePattern = "morning_star"      // 晨星：長陰線、小實體、收復第一根一半以上的長陽線
+	PatternEveningStar      CandlePattern = "evening_star"      // 夜星：長陽線、小實體、跌破第一根一半以下的長陰線
+	PatternInsideBar        CandlePattern = "inside_bar"        // 內包線：高低點都在前一根之內
+	PatternOutsideBar       CandlePattern = "outside_bar"       // 外包線：高低點都超出前一根
+)
+
+// AllPatterns 所有支援的 K 線型態
+var AllPatterns = []CandlePattern{
+	PatternBullishEngulfing, PatternBearishEngulfing,
+	PatternHammer, PatternShootingStar, PatternDoji,
+	PatternMorningStar, PatternEveningStar,
+	PatternInsideBar, PatternOutsideBar,
+}
+
+// IsBullish 是否為看漲型態
+func (p CandlePattern) IsBullish() bool {
+	return p == PatternBullishEngulfing || p == PatternHammer || p == PatternMorningStar
+}
+
+// IsBearish 是否為看跌型態（十字線、內包線、外包線沒有方向，既不看漲也不看跌）
+func (p CandlePattern) IsBearish() bool {
+	return p == PatternBearishEngulfing || p == PatternShootingStar || p == PatternEveningStar
+}
+
+// PatternConfig K 線型態判斷配置（比例皆相對於單根 K 線）
+type PatternConfig struct {
+	DojiBodyRatio     float64 // 十字線：實體 / 振幅上限，預設 0.1
+	HammerWickRatio   float64 // 錘子線／射擊之星：長影線 / 實體下限，預設 2.0
+	HammerShadowRatio float64 // 錘子線／射擊之星：反方向影線 / 振幅上限，預設 0.1
+	StarBodyRatio     float64 // 晨星／夜星：中間 K 線實體 / 第一根實體上限，預設 0.3
+	LongBodyRatio     float64 // 晨星／夜星：第一根與第三根實體 / 振幅下限，預設 0.5
+}
+
+// DefaultPatternConfig 返回預設配置
+func DefaultPatternConfig() PatternConfig {
+	return PatternConfig{
+		DojiBodyRatio:     0.1,
+		HammerWickRatio:   2.0,
+		HammerShadowRatio: 0.1,
+		StarBodyRatio:     0.3,
+		LongBodyRatio:     0.5,
+	}
+}
+
+// DetectPatterns 找出以最新一根 K 線結束的所有型態
+// candles: 已收盤的 K 線，最新的在最後
+func DetectPatterns(candles []Candle, config PatternConfig) []CandlePattern {
+	patterns := make([]CandlePattern, 0)
+	n := len(candles)
+	if n == 0 {
+		return patterns
+	}
+
+	cur := candles[n-1]
+	if cur.Range() <= 0 {
+		return patterns
+	}
+
+	// 單根型態
+	body := cur.Body()
+	if body <= cur.Range()*config.DojiBodyRatio {
+		patterns = append(patterns, PatternDoji)
+	}
+	if body > 0 {
+		if cur.LowerWick() >= body*config.HammerWickRatio && cur.UpperWick() <= cur.Range()*config.HammerShadowRatio {
+			patterns = append(patterns, PatternHammer)
+		}
+		if cur.UpperWick() >= body*config.HammerWickRatio && cur.LowerWick() <= cur.Range()*config.HammerShadowRatio {
+			patterns = append(patterns, PatternShootingStar)
+		}
+	}
+
+	if n < 2 {
+		return patterns
+	}
+
+	// 兩根型態
+	prev := candles[n-2]
+	switch {
+	case cur.IsBullish() && prev.IsBearish() && cur.Open <= prev.Close && cur.Close >= prev.Open && body > prev.Body():
+		patterns = append(patterns, PatternBullishEngulfing)
+	case cur.IsBearish() && prev.IsBullish() && cur.Open >= prev.Close && cur.Close <= prev.Open && body > prev.Body():
+		patterns = append(patterns, PatternBearishEngulfing)
+	}
+	switch {
+	case cur.High < prev.High && cur.Low > prev.Low:
+		patterns = append(patterns, PatternInsideBar)
+	case cur.High > prev.High && cur.Low < prev.Low:
+		patterns = append(patterns, PatternOutsideBar)
+	}
+
+	if n < 3 {
+		return patterns
+	}
+
+	// 三根型態
+	first, star := candles[n-3], candles[n-2]
+	isLong := func(c Candle) bool {
+		return c.Range() > 0 && c.Body() >= c.Range()*config.LongBodyRatio
+	}
+	smallStar := star.Body() <= first.Body()*config.StarBodyRatio
+	firstMid := (first.Open + first.Close) / 2
+	switch {
+	case first.IsBearish() && isLong(first) && smallStar && math.Max(star.Open, star.Close) < first.Close &&
+		cur.IsBullish() && isLong(cur) && cur.Close > firstMid:
+		patterns = append(patterns, PatternMorningStar)
+	case first.IsBullish() && isLong(first) && smallStar && math.Min(star.Open, star.Close) > first.Close &&
+		cur.IsBearish() && isLong(cur) && cur.Close < firstMid:
+		patterns = append(patterns, PatternEveningStar)
+	}
+
+	return patterns
+}
@@ -0,0 +1,202 @@
+package indicators
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDetectPatterns(t *testing.T) {
+	tests := []struct {
+		name    string
+		candles []Candle
+		pattern CandlePattern
+		want    bool
+	}{
+		{
+			name: "bullish engulfing",
+			candles: []Candle{
+				{Open: 105, High: 106, Low: 99, Close: 100},
+				{Open: 99, High: 108, Low: 98, Close: 107},
+			},
+			pattern: PatternBullishEngulfing,
+			want:    true,
+		},
+		{
+			name: "bullish engulfing body does not cover previous open",
+			candles: []Candle{
+				{Open: 105, High: 106, Low: 99, Close: 100},
+				{Open: 99, High: 108, Low: 98, Close: 104},
+			},
+			pattern: PatternBullishEngulfing,
+			want:    false,
+		},
+		{
+			name: "bearish engulfing",
+			candles: []Candle{
+				{Open: 100, High: 106, Low: 99, Close: 105},
+				{Open: 106, High: 107, Low: 98, Close: 99},
+			},
+			pattern: PatternBearishEngulfing,
+			want:    true,
+		},
+		{
+			name: "bearish engulfing body does not cover previous open",
+			candles: []Candle{
+				{Open: 100, High: 106, Low: 99, Close: 105},
+				{Open: 106, High: 107, Low: 98, Close: 101},
+			},
+			pattern: PatternBearishEngulfing,
+			want:    false,
+		},
+		{
+			name:    "hammer",
+			candles: []Candle{{Open: 100, High: 102.2, Low: 92, Close: 102}},
+			pattern: PatternHammer,
+			want:    true,
+		},
+		{
+			name:    "hammer with long upper wick",
+			candles: []Candle{{Open: 100, High: 106, Low: 92, Close: 102}},
+			pattern: PatternHammer,
+			want:    false,
+		},
+		{
+			name:    "shooting star",
+			candles: []Candle{{Open: 102, High: 110, Low: 99.8, Close: 100}},
+			pattern: PatternShootingStar,
+			want:    true,
+		},
+		{
+			name:    "shooting star with long lower wick",
+			candles: []Candle{{Open: 102, High: 110, Low: 96, Close: 100}},
+			pattern: PatternShootingStar,
+			want:    false,
+		},
+		{
+			name:    "doji",
+			candles: []Candle{{Open: 100, High: 105, Low: 95, Close: 100.5}},
+			pattern: PatternDoji,
+			want:    true,
+		},
+		{
+			name:    "doji body too large",
+			candles: []Candle{{Open: 100, High: 105, Low: 95, Close: 103}},
+			pattern: PatternDoji,
+			want:    false,
+		},
+		{
+			name: "morning star",
+			candles: []Candle{
+				{Open: 110, High: 111, Low: 99, Close: 100},
+				{Open: 98, High: 99, Low: 96, Close: 97.5},
+				{Open: 98, High: 108, Low: 97, Close: 107},
+			},
+			pattern: PatternMorningStar,
+			want:    true,
+		},
+		{
+			name: "morning star third candle below first midpoint",
+			candles: []Candle{
+				{Open: 110, High: 111, Low: 99, Close: 100},
+				{Open: 98, High: 99, Low: 96, Close: 97.5},
+				{Open: 98, High: 108, Low: 97, Close: 104},
+			},
+			pattern: PatternMorningStar,
+			want:    false,
+		},
+		{
+			name: "evening star",
+			candles: []Candle{
+				{Open: 100, High: 111, Low: 99, Close: 110},
+				{Open: 112, High: 114, Low: 111, Close: 112.5},
+				{Open: 112, High: 113, Low: 102, Close: 103},
+			},
+			pattern: PatternEveningStar,
+			want:    true,
+		},
+		{
+			name: "evening star third candle above first midpoint",
+			candles: []Candle{
+				{Open: 100, High: 111, Low: 99, Close: 110},
+				{Open: 112, High: 114, Low: 111, Close: 112.5},
+				{Open: 112, High: 113, Low: 102, Close: 106},
+			},
+			pattern: PatternEveningStar,
+			want:    false,
+		},
+		{
+			name: "inside bar",
+			candles: []Candle{
+				{Open: 100, High: 110, Low: 90, Close: 105},
+				{Open: 104, High: 108, Low: 95, Close: 106},
+			},
+			pattern: PatternInsideBar,
+			want:    true,
+		},
+		{
+			name: "inside bar with higher high",
+			candles: []Candle{
+				{Open: 100, High: 110, Low: 90, Close: 105},
+				{Open: 104, High: 111, Low: 95, Close: 106},
+			},
+			pattern: PatternInsideBar,
+			want:    false,
+		},
+		{
+			name: "outside bar",
+			candles: []Candle{
+				{Open: 100, High: 105, Low: 95, Close: 102},
+				{Open: 101, High: 107, Low: 93, Close: 99},
+			},
+			pattern: PatternOutsideBar,
+			want:    true,
+		},
+		{
+			name: "outside bar with higher low",
+			candles: []Candle{
+				{Open: 100, High: 105, Low: 95, Close: 102},
+				{Open: 101, High: 107, Low: 96, Close: 99},
+			},
+			pattern: PatternOutsideBar,
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patterns := DetectPatterns(tt.candles, DefaultPatternConfig())
+			if got := slices.Contains(patterns, tt.pattern); got != tt.want {
+				t.Errorf("DetectPatterns() = %v, contains %s = %v, want %v", patterns, tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatternDirection(t *testing.T) {
+	tests := []struct {
+		pattern CandlePattern
+		bullish bool
+		bearish bool
+	}{
+		{PatternBullishEngulfing, true, false},
+		{PatternHammer, true, false},
+		{PatternMorningStar, true, false},
+		{PatternBearishEngulfing, false, true},
+		{PatternShootingStar, false, true},
+		{PatternEveningStar, false, true},
+		{PatternDoji, false, false},
+		{PatternInsideBar, false, false},
+		{PatternOutsideBar, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.pattern), func(t *testing.T) {
+			if got := tt.pattern.IsBullish(); got != tt.bullish {
+				t.Errorf("IsBullish() = %v, want %v", got, tt.bullish)
+			}
+			if got := tt.pattern.IsBearish(); got != tt.bearish {
+				t.Errorf("IsBearish() = %v, want %v", got, tt.bearish)
+			}
+		})
+	}
+}
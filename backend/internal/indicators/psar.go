@@ -0,0 +1,101 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+)
+
+// PSARConfig 拋物線轉向指標計算配置
+type PSARConfig struct {
+	Start     float64 // 初始加速因子，預設 0.02
+	Increment float64 // 每創新極值的加速因子增量，預設 0.02
+	Max       float64 // 加速因子上限，預設 0.2
+}
+
+// DefaultPSARConfig 返回預設配置
+func DefaultPSARConfig() PSARConfig {
+	return PSARConfig{
+		Start:     0.02,
+		Increment: 0.02,
+		Max:       0.2,
+	}
+}
+
+// PSARResult 拋物線轉向指標計算結果（最新一根）
+type PSARResult struct {
+	SAR           float64 // 停損反轉點
+	Uptrend       bool    // 是否為上升趨勢（SAR 在價格下方）
+	ExtremePoint  float64 // 當前趨勢的極值（上升趨勢為最高價，下降趨勢為最低價）
+	AccelFactor   float64 // 當前加速因子
+	FlippedUp     bool    // 最新一根由下降翻轉為上升
+	FlippedDown   bool    // 最新一根由上升翻轉為下降
+	BarsSinceFlip int     // 距離上次翻轉的 K 線根數
+}
+
+// CalculatePSAR 計算拋物線轉向指標（Wilder 原始算法）
+// highs, lows, closes: K 線的最高、最低、收盤價，最新的在最後，長度需一致
+func CalculatePSAR(highs, lows, closes []float64, config PSARConfig) (PSARResult, error) {
+	n := len(closes)
+	if len(highs) != n || len(lows) != n {
+		return PSARResult{}, fmt.Errorf("K 線序列長度不一致")
+	}
+	if n < 3 {
+		return PSARResult{}, fmt.Errorf("數據長度不足，需要至少 3 筆數據，目前只有 %d 筆", n)
+	}
+	if config.Start <= 0 || config.Increment < 0 || config.Max < config.Start {
+		return PSARResult{}, fmt.Errorf("PSAR 加速因子設定無效")
+	}
+
+	// 以前兩根的收盤方向決定初始趨勢
+	up := closes[1] >= closes[0]
+	af := config.Start
+	var sar, ep float64
+	if up {
+		sar, ep = lows[0], highs[1]
+	} else {
+		sar, ep = highs[0], lows[1]
+	}
+
+	prevUp := up
+	barsSinceFlip := 1
+	for i := 2; i < n; i++ {
+		prevUp = up
+		sar += af * (ep - sar)
+
+		if up {
+			// SAR 不能高於前兩根的最低價
+			sar = math.Min(sar, math.Min(lows[i-1], lows[i-2]))
+			if lows[i] < sar {
+				up, sar, ep, af = false, ep, lows[i], config.Start
+			} else if highs[i] > ep {
+				ep = highs[i]
+				af = math.Min(af+config.Increment, config.Max)
+			}
+		} else {
+			// SAR 不能低於前兩根的最高價
+			sar = math.Max(sar, math.Max(highs[i-1], highs[i-2]))
+			if highs[i] > sar {
+				up, sar, ep, af = true, ep, highs[i], config.Start
+			} else if lows[i] < ep {
+				ep = lows[i]
+				af = math.Min(af+config.Increment, config.Max)
+			}
+		}
+
+		if up != prevUp {
+			barsSinceFlip = 0
+		} else {
+			barsSinceFlip++
+		}
+	}
+
+	return PSARResult{
+		SAR:           sar,
+		Uptrend:       up,
+		ExtremePoint:  ep,
+		AccelFactor:   af,
+		FlippedUp:     up && !prevUp,
+		FlippedDown:   !up && prevUp,
+		BarsSinceFlip: barsSinceFlip,
+	}, nil
+}
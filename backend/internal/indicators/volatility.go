@@ -0,0 +1,170 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+)
+
+// VolatilityRegime 波動率狀態
+type VolatilityRegime string
+
+const (
+	RegimeCompressed VolatilityRegime = "compressed" // 波動收斂：百分位低於 CompressedPct
+	RegimeNormal     VolatilityRegime = "normal"
+	RegimeExpanded   VolatilityRegime = "expanded" // 波動擴張：百分位高於 ExpandedPct
+)
+
+// VolatilityConfig 已實現波動率計算配置
+type VolatilityConfig struct {
+	Window        int     // 每個波動率值使用的 K 線根數，預設 20
+	History       int     // 計算百分位的歷史波動率根數，預設 200
+	CompressedPct float64 // 收斂門檻百分位，預設 20
+	ExpandedPct   float64 // 擴張門檻百分位，預設 80
+}
+
+// DefaultVolatilityConfig 返回預設配置
+func DefaultVolatilityConfig() VolatilityConfig {
+	return VolatilityConfig{
+		Window:        20,
+		History:       200,
+		CompressedPct: 20,
+		ExpandedPct:   80,
+	}
+}
+
+// VolatilityResult 已實現波動率結果（每根 K 線的標準差，未年化）
+type VolatilityResult struct {
+	CloseToClose float64 // 收盤對收盤
+	Parkinson    float64 // Parkinson（最高/最低價）
+	GarmanKlass  float64 // Garman-Klass（開高低收）
+
+	Percentile          float64          // Garman-Klass 波動率在歷史中的百分位（0~100）
+	Regime              VolatilityRegime // 依百分位判斷的波動率狀態
+	BarsSinceCompressed int              // 距離最近一根收斂狀態 K 線的根數，0 表示最新一根，-1 表示歷史中沒有
+}
+
+// CloseToCloseVolSeries 收盤對收盤波動率序列：對數報酬率的滾動標準差
+func CloseToCloseVolSeries(closes []float64, window int) []float64 {
+	out := nanSeries(len(closes))
+	returns := LogReturns(closes)
+	if window < 2 || len(returns) < window {
+		return out
+	}
+	for i := window - 1; i < len(returns); i++ {
+		w := returns[i-window+1 : i+1]
+		out[i+1] = standardDeviation(w, mean(w)) * math.Sqrt(float64(window)/float64(window-1))
+	}
+	return out
+}
+
+// ParkinsonVolSeries Parkinson 波動率序列：以 ln(H/L)² / (4 ln2) 估計每根變異數
+func ParkinsonVolSeries(highs, lows []float64, window int) []float64 {
+	variances := make([]float64, len(highs))
+	for i := range highs {
+		if highs[i] <= 0 || lows[i] <= 0 {
+			continue
+		}
+		hl := math.Log(highs[i] / lows[i])
+		variances[i] = hl * hl / (4 * math.Ln2)
+	}
+	return rollingRootMean(variances, window)
+}
+
+// GarmanKlassVolSeries Garman-Klass 波動率序列：0.5 ln(H/L)² - (2 ln2 - 1) ln(C/O)²
+func GarmanKlassVolSeries(opens, highs, lows, closes []float64, window int) []float64 {
+	variances := make([]float64, len(closes))
+	for i := range closes {
+		if opens[i] <= 0 || highs[i] <= 0 || lows[i] <= 0 || closes[i] <= 0 {
+			continue
+		}
+		hl := math.Log(highs[i] / lows[i])
+		co := math.Log(closes[i] / opens[i])
+		variances[i] = math.Max(0.5*hl*hl-(2*math.Ln2-1)*co*co, 0)
+	}
+	return rollingRootMean(variances, window)
+}
+
+// CalculateVolatility 計算最新一根 K 線的已實現波動率與波動率狀態
+// 輸入應為已收盤的 K 線，百分位以最近 History 根的 Garman-Klass 波動率為分佈
+func CalculateVolatility(opens, highs, lows, closes []float64, config VolatilityConfig) (VolatilityResult, error) {
+	n := len(closes)
+	if len(opens) != n || len(highs) != n || len(lows) != n {
+		return VolatilityResult{}, fmt.Errorf("K 線資料長度不一致")
+	}
+	if config.Window < 2 {
+		return VolatilityResult{}, fmt.Errorf("波動率視窗至少為 2")
+	}
+	if n < config.Window+1 {
+		return VolatilityResult{}, fmt.Errorf("數據長度不足，需要至少 %d 筆數據，目前只有 %d 筆", config.Window+1, n)
+	}
+
+	gk := GarmanKlassVolSeries(opens, highs, lows, closes, config.Window)
+	history := make([]float64, 0, config.History)
+	for i := max(n-config.History, 0); i < n; i++ {
+		if !math.IsNaN(gk[i]) {
+			history = append(history, gk[i])
+		}
+	}
+
+	result := VolatilityResult{
+		CloseToClose:        Last(CloseToCloseVolSeries(closes, config.Window)),
+		Parkinson:           Last(ParkinsonVolSeries(highs, lows, config.Window)),
+		GarmanKlass:         Last(gk),
+		BarsSinceCompressed: -1,
+	}
+	result.Percentile = PercentRank(history, result.GarmanKlass)
+	result.Regime = config.regime(result.Percentile)
+
+	for i := len(history) - 1; i >= 0; i-- {
+		if config.regime(PercentRank(history, history[i])) == RegimeCompressed {
+			result.BarsSinceCompressed = len(history) - 1 - i
+			break
+		}
+	}
+	return result, nil
+}
+
+// PercentRank 計算 value 在 values 中的百分位（0~100），即不大於 value 的比例
+func PercentRank(values []float64, value float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	count := 0
+	for _, v := range values {
+		if v <= value {
+			count++
+		}
+	}
+	return float64(count) / float64(len(values)) * 100
+}
+
+// regime 依百分位判斷波動率狀態
+func (c VolatilityConfig) regime(percentile float64) VolatilityRegime {
+	switch {
+	case percentile <= c.CompressedPct:
+		return RegimeCompressed
+	case percentile >= c.ExpandedPct:
+		return RegimeExpanded
+	default:
+		return RegimeNormal
+	}
+}
+
+// rollingRootMean 滾動平均變異數開根號
+func rollingRootMean(variances []float64, window int) []float64 {
+	out := nanSeries(len(variances))
+	if window <= 0 || len(variances) < window {
+		return out
+	}
+	sum := 0.0
+	for i, v := range variances {
+		sum += v
+		if i >= window {
+			sum -= variances[i-window]
+		}
+		if i >= window-1 {
+			out[i] = math.Sqrt(math.Max(sum, 0) / float64(window))
+		}
+	}
+	return out
+}
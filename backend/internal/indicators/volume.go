@@ -1,10 +1,25 @@
 package indicators
 
+import (
+	"math"
+	"sort"
+)
+
 // VolumeResult 成交量分析結果
 type VolumeResult struct {
 	CurrentVolume float64 // 當前 1 分 K 成交量
 	AvgVolume     float64 // 近 N 根 K 線平均成交量
 	VolumeRatio   float64 // 當前/平均 比值
+
+	// 穩健統計（不含當前這根）
+	MedianVolume float64 // 近 N 根成交量中位數
+	ZScore       float64 // (當前 - 平均) / 標準差
+	MADScore     float64 // 穩健 Z 分數：(當前 - 中位數) / (1.4826 * MAD)
+
+	// 成交額與主動買賣（由 K 線欄位 7~10 取得）
+	CurrentQuoteVolume float64 // 當前成交額（USDT）
+	AvgQuoteVolume     float64 // 近 N 根平均成交額（USDT）
+	TakerBuyRatio      float64 // 主動買入佔比（0~1）
 }
 
 // VolumeCheckMode 成交量檢查模式
@@ -13,15 +28,26 @@ type VolumeCheckMode string
 const (
 	VolumeCheckModeFixed      VolumeCheckMode = "fixed"      // 固定值模式
 	VolumeCheckModeMultiplier VolumeCheckMode = "multiplier" // 倍數模式
+	VolumeCheckModeZScore     VolumeCheckMode = "zscore"     // Z 分數模式（平均/標準差）
+	VolumeCheckModeMAD        VolumeCheckMode = "mad"        // 穩健 Z 分數模式（中位數/MAD），不受單根巨量影響
+	VolumeCheckModeNotional   VolumeCheckMode = "notional"   // 成交額模式：以 USDT 計價的固定閾值
+	VolumeCheckModeTakerBuy   VolumeCheckMode = "taker_buy"  // 倍數放量且主動買入佔比達標
+	VolumeCheckModeTakerSell  VolumeCheckMode = "taker_sell" // 倍數放量且主動賣出佔比達標
 )
 
+// madScale 將 MAD 換算為常態分佈標準差的係數
+const madScale = 1.4826
+
 // VolumeConfig 成交量檢查配置
 type VolumeConfig struct {
 	Enabled    bool            // 是否啟用成交量檢查
 	Mode       VolumeCheckMode // 檢查模式
-	FixedValue float64         // 固定值模式：閾值
+	FixedValue float64         // 固定值模式：閾值（notional 模式為 USDT 成交額）
 	Multiplier float64         // 倍數模式：N 倍
 	AvgPeriod  int             // 均量計算週期（幾根 K 線）
+
+	ScoreThreshold float64 // zscore / mad 模式：分數閾值
+	TakerRatio     float64 // taker_buy / taker_sell 模式：主動買入（賣出）佔比閾值
 }
 
 // DefaultVolumeConfig 返回預設配置
@@ -32,6 +58,9 @@ func DefaultVolumeConfig() VolumeConfig {
 		FixedValue: 0,
 		Multiplier: 10.0, // 預設 10 倍均量
 		AvgPeriod:  60,
+
+		ScoreThreshold: 3.0, // 預設 3 個標準差
+		TakerRatio:     0.7, // 預設 70% 為主動成交
 	}
 }
 
@@ -72,10 +101,27 @@ func CalculateVolumeStats(volumes []float64, avgPeriod int) VolumeResult {
 		volumeRatio = currentVolume / avgVolume
 	}
 
+	// 計算 Z 分數
+	window := volumes[startIdx:endIdx]
+	zScore := 0.0
+	if stdDev := standardDeviation(window, avgVolume); stdDev > 0 {
+		zScore = (currentVolume - avgVolume) / stdDev
+	}
+
+	// 計算穩健 Z 分數
+	medianVolume := median(window)
+	madScore := 0.0
+	if mad := medianAbsoluteDeviation(window, medianVolume); mad > 0 {
+		madScore = (currentVolume - medianVolume) / (madScale * mad)
+	}
+
 	return VolumeResult{
 		CurrentVolume: currentVolume,
 		AvgVolume:     avgVolume,
 		VolumeRatio:   volumeRatio,
+		MedianVolume:  medianVolume,
+		ZScore:        zScore,
+		MADScore:      madScore,
 	}
 }
 
@@ -90,7 +136,90 @@ func CheckVolumeCondition(result VolumeResult, config VolumeConfig) bool {
 		return result.CurrentVolume >= config.FixedValue
 	case VolumeCheckModeMultiplier:
 		return result.CurrentVolume >= (result.AvgVolume * config.Multiplier)
+	case VolumeCheckModeZScore:
+		return result.ZScore >= config.ScoreThreshold
+	case VolumeCheckModeMAD:
+		return result.MADScore >= config.ScoreThreshold
+	case VolumeCheckModeNotional:
+		return result.CurrentQuoteVolume >= config.FixedValue
+	case VolumeCheckModeTakerBuy:
+		return result.CurrentVolume >= (result.AvgVolume*config.Multiplier) &&
+			result.TakerBuyRatio >= config.TakerRatio
+	case VolumeCheckModeTakerSell:
+		return result.CurrentVolume >= (result.AvgVolume*config.Multiplier) &&
+			1-result.TakerBuyRatio >= config.TakerRatio
 	default:
 		return true
 	}
 }
+
+// CalculateTakerBuyRatio 計算主動買入佔比
+// volume: 總成交量
+// takerBuyVolume: 主動買入成交量
+func CalculateTakerBuyRatio(volume, takerBuyVolume float64) float64 {
+	if volume <= 0 {
+		return 0
+	}
+	return takerBuyVolume / volume
+}
+
+// ScoreAgainstBaseline 以基準樣本計算當前成交量的 Z 分數與穩健 Z 分數
+// baseline 為同一時段的歷史成交量（例如近 7 天同一 UTC 小時的 1 分 K），
+// 讓當前成交量與相同時段比較，00:00 UTC 的結構性放量不會被誤判為異常
+// 另外返回基準中位數
+func ScoreAgainstBaseline(current float64, baseline []float64) (zScore, madScore, baselineMedian float64) {
+	if len(baseline) == 0 {
+		return 0, 0, 0
+	}
+	mean := 0.0
+	for _, v := range baseline {
+		mean += v
+	}
+	mean /= float64(len(baseline))
+	if stdDev := standardDeviation(baseline, mean); stdDev > 0 {
+		zScore = (current - mean) / stdDev
+	}
+
+	baselineMedian = median(baseline)
+	if mad := medianAbsoluteDeviation(baseline, baselineMedian); mad > 0 {
+		madScore = (current - baselineMedian) / (madScale * mad)
+	}
+	return zScore, madScore, baselineMedian
+}
+
+// median 計算中位數（不修改原切片）
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// medianAbsoluteDeviation 計算中位數絕對偏差
+func medianAbsoluteDeviation(values []float64, center float64) float64 {
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - center)
+	}
+	return median(deviations)
+}
+
+// standardDeviation 計算母體標準差
+func standardDeviation(values []float64, mean float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sumSq float64
+	for _, v := range values {
+		sumSq += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sumSq / float64(len(values)))
+}
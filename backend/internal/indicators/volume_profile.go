@@ -0,0 +1,153 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+)
+
+// VolumeProfileConfig 成交量分佈計算配置
+type VolumeProfileConfig struct {
+	Bins           int     // 價格分箱數量，預設 50
+	ValueAreaRatio float64 // 價值區涵蓋的成交量比例，預設 0.7
+}
+
+// DefaultVolumeProfileConfig 返回預設配置
+func DefaultVolumeProfileConfig() VolumeProfileConfig {
+	return VolumeProfileConfig{
+		Bins:           50,
+		ValueAreaRatio: 0.7,
+	}
+}
+
+// VolumeBin 單一價格區間的成交量
+type VolumeBin struct {
+	Low    float64
+	High   float64
+	Volume float64
+}
+
+// VolumeProfile 成交量分佈（Volume Profile）
+type VolumeProfile struct {
+	Bins            []VolumeBin
+	TotalVolume     float64
+	POC             float64   // 成交量最大的價格（Point of Control）
+	ValueAreaHigh   float64   // 價值區上緣
+	ValueAreaLow    float64   // 價值區下緣
+	HighVolumeNodes []float64 // 高成交量節點（HVN）
+	LowVolumeNodes  []float64 // 低成交量節點（LVN），只取價值區內
+}
+
+// CalculateVolumeProfile 計算成交量分佈
+// 每根 K 線的成交量依其最高到最低的範圍平均分配到重疊的價格分箱
+func CalculateVolumeProfile(highs, lows, volumes []float64, config VolumeProfileConfig) (VolumeProfile, error) {
+	n := len(volumes)
+	if n == 0 || len(highs) != n || len(lows) != n {
+		return VolumeProfile{}, fmt.Errorf("K 線資料為空或長度不一致")
+	}
+	if config.Bins < 3 {
+		return VolumeProfile{}, fmt.Errorf("分箱數量至少為 3")
+	}
+	if config.ValueAreaRatio <= 0 || config.ValueAreaRatio > 1 {
+		return VolumeProfile{}, fmt.Errorf("價值區比例必須介於 0 到 1 之間")
+	}
+
+	minPrice, maxPrice := lows[0], highs[0]
+	for i := range highs {
+		minPrice = math.Min(minPrice, lows[i])
+		maxPrice = math.Max(maxPrice, highs[i])
+	}
+	if maxPrice <= minPrice {
+		return VolumeProfile{}, fmt.Errorf("價格區間為 0，無法計算成交量分佈")
+	}
+
+	binSize := (maxPrice - minPrice) / float64(config.Bins)
+	binOf := func(price float64) int {
+		return min(int((price-minPrice)/binSize), config.Bins-1)
+	}
+
+	profile := VolumeProfile{Bins: make([]VolumeBin, config.Bins)}
+	for i := range profile.Bins {
+		profile.Bins[i].Low = minPrice + float64(i)*binSize
+		profile.Bins[i].High = profile.Bins[i].Low + binSize
+	}
+
+	for i, v := range volumes {
+		profile.TotalVolume += v
+		lo, hi := binOf(lows[i]), binOf(highs[i])
+		if highs[i] <= lows[i] {
+			profile.Bins[lo].Volume += v
+			continue
+		}
+		// 依 K 線範圍與分箱重疊的比例分配
+		for b := lo; b <= hi; b++ {
+			overlap := math.Min(highs[i], profile.Bins[b].High) - math.Max(lows[i], profile.Bins[b].Low)
+			if overlap > 0 {
+				profile.Bins[b].Volume += v * overlap / (highs[i] - lows[i])
+			}
+		}
+	}
+	if profile.TotalVolume <= 0 {
+		return VolumeProfile{}, fmt.Errorf("成交量為 0，無法計算成交量分佈")
+	}
+
+	// POC
+	poc := 0
+	for i, bin := range profile.Bins {
+		if bin.Volume > profile.Bins[poc].Volume {
+			poc = i
+		}
+	}
+	profile.POC = binMid(profile.Bins[poc])
+
+	// 價值區：從 POC 開始往成交量較大的一側擴張，直到涵蓋 ValueAreaRatio
+	low, high := poc, poc
+	covered := profile.Bins[poc].Volume
+	for covered < profile.TotalVolume*config.ValueAreaRatio && (low > 0 || high < config.Bins-1) {
+		below, above := -1.0, -1.0
+		if low > 0 {
+			below = profile.Bins[low-1].Volume
+		}
+		if high < config.Bins-1 {
+			above = profile.Bins[high+1].Volume
+		}
+		if above >= below {
+			high++
+			covered += above
+		} else {
+			low--
+			covered += below
+		}
+	}
+	profile.ValueAreaLow = profile.Bins[low].Low
+	profile.ValueAreaHigh = profile.Bins[high].High
+
+	// 高／低成交量節點：平滑後的局部極值
+	smoothed := make([]float64, config.Bins)
+	for i := range smoothed {
+		lo, hi := max(i-1, 0), min(i+1, config.Bins-1)
+		var sum float64
+		for j := lo; j <= hi; j++ {
+			sum += profile.Bins[j].Volume
+		}
+		smoothed[i] = sum / float64(hi-lo+1)
+	}
+	mean := profile.TotalVolume / float64(config.Bins)
+	profile.HighVolumeNodes = make([]float64, 0)
+	profile.LowVolumeNodes = make([]float64, 0)
+	for i := 1; i < config.Bins-1; i++ {
+		v := smoothed[i]
+		switch {
+		case v > mean && v >= smoothed[i-1] && v >= smoothed[i+1]:
+			profile.HighVolumeNodes = append(profile.HighVolumeNodes, binMid(profile.Bins[i]))
+		case v < mean*0.5 && v <= smoothed[i-1] && v <= smoothed[i+1] && i > low && i < high:
+			profile.LowVolumeNodes = append(profile.LowVolumeNodes, binMid(profile.Bins[i]))
+		}
+	}
+
+	return profile, nil
+}
+
+// binMid 分箱中間價
+func binMid(bin VolumeBin) float64 {
+	return (bin.Low + bin.High) / 2
+}
@@ -1,25 +1,279 @@
 package models
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 type Alert struct {
-	AlertID       string    `json:"alertId"`
-	UserID        string    `json:"userId"`
-	Symbol        string    `json:"symbol"`
-	AlertType     string    `json:"alertType"`
-	TargetPrice   float64   `json:"targetPrice,omitempty"`
-	Direction     string    `json:"direction,omitempty"`
-	TargetVolume  float64   `json:"targetVolume,omitempty"`
-	TimeWindow    int       `json:"timeWindow,omitempty"`
-	CreatedAt     time.Time `json:"createdAt"`
+	AlertID         string  `json:"alertId"`
+	UserID          string  `json:"userId"`
+	Symbol          string  `json:"symbol"`
+	AlertType       string  `json:"alertType"`
+	TargetPrice     float64 `json:"targetPrice,omitempty"`
+	Direction       string  `json:"direction,omitempty"`
+	TargetVolume    float64 `json:"targetVolume,omitempty"`
+	TimeWindow      int     `json:"timeWindow,omitempty"`      // 成交量、漲跌幅、速度警報的視窗（分鐘）
+	MovePct         float64 `json:"movePct,omitempty"`         // percent_move 警報：漲跌幅門檻（%）
+	VelocityPct     float64 `json:"velocityPct,omitempty"`     // velocity 警報：速度門檻（%/分鐘）
+	LevelInterval   string  `json:"levelInterval,omitempty"`   // level 警報：支撐壓力位的 K 線週期
+	LevelPrice      float64 `json:"levelPrice,omitempty"`      // level 警報：目前鎖定的支撐壓力區代表價格
+	ProfileInterval string  `json:"profileInterval,omitempty"` // value_area 警報：成交量分佈的 K 線週期
+	ProfileLookback int     `json:"profileLookback,omitempty"` // value_area 警報：成交量分佈回看的 K 線根數
+
+	// 追蹤停損警報：記錄建立以來的最高價（做空為最低價），價格自極值回撤超過距離時觸發
+	TrailMode        string  `json:"trailMode,omitempty"`        // "amount"、"percent" 或 "atr"
+	TrailAmount      float64 `json:"trailAmount,omitempty"`      // amount 模式：回撤金額
+	TrailPct         float64 `json:"trailPct,omitempty"`         // percent 模式：回撤百分比
+	TrailATRMultiple float64 `json:"trailAtrMultiple,omitempty"` // atr 模式：N 倍 ATR
+	TrailATRInterval string  `json:"trailAtrInterval,omitempty"` // atr 模式：ATR 的 K 線週期
+	TrailATRPeriod   int     `json:"trailAtrPeriod,omitempty"`   // atr 模式：ATR 週期
+	TrailExtreme     float64 `json:"trailExtreme,omitempty"`     // 建立以來的最高價（做空為最低價）
+	TrailStopPrice   float64 `json:"trailStopPrice,omitempty"`   // 目前的追蹤停損價
+
+	Targets      []NotifyTarget `json:"targets,omitempty"`      // 通知對象
+	TriggeredAt  *time.Time     `json:"triggeredAt,omitempty"`  // 觸發時間，通知全部送達前保留警報並重試
+	TriggerValue float64        `json:"triggerValue,omitempty"` // 觸發時的價格（成交量警報為成交量）
+	EventID      string         `json:"eventId,omitempty"`      // 本次觸發的歷史紀錄 ID
+
+	// 重複警報：觸發後停用，價格回到遲滯區間外才重新啟用
+	Repeat          bool       `json:"repeat,omitempty"`
+	RearmPct        float64    `json:"rearmPct,omitempty"`        // 重新啟用的遲滯幅度（%），例如 above 警報需回到目標價下方 0.5%
+	MaxTriggers     int        `json:"maxTriggers,omitempty"`     // 最多觸發次數，0 表示不限
+	TriggerCount    int        `json:"triggerCount,omitempty"`    // 已觸發次數
+	Disarmed        bool       `json:"disarmed,omitempty"`        // 已觸發、等待重新啟用
+	LastTriggeredAt *time.Time `json:"lastTriggeredAt,omitempty"` // 最近一次觸發時間
+	ExpiresAt       *time.Time `json:"expiresAt,omitempty"`       // 到期時間，到期後刪除警報並通知
+
+	// 生效時段：安靜時段內不觸發，或觸發後延到 DeliverAfter 才送出通知
+	Schedule     *AlertSchedule `json:"schedule,omitempty"`
+	DeliverAfter *time.Time     `json:"deliverAfter,omitempty"` // 延後送出通知的時間
+
+	// 穿越判斷：記錄上次檢查的價格，價格實際穿越目標時才觸發
+	// 上次檢查時未收盤的 1 分鐘 K 線高低點也一併記錄，下次只計入之後新創的高低點
+	LastPrice     float64    `json:"lastPrice,omitempty"`
+	LastCheckedAt *time.Time `json:"lastCheckedAt,omitempty"`
+	LastKlineOpen int64      `json:"lastKlineOpen,omitempty"` // 上次檢查時未收盤 K 線的開盤時間（毫秒）
+	LastKlineHigh float64    `json:"lastKlineHigh,omitempty"`
+	LastKlineLow  float64    `json:"lastKlineLow,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// 重複警報參數
+const (
+	DefaultRearmPct = 0.5
+	MaxRearmPct     = 50.0
+)
+
+// IsTriggered 是否已觸發、等待通知送達
+func (a *Alert) IsTriggered() bool {
+	return a.TriggeredAt != nil
+}
+
+// IsExpired 是否已過到期時間
+func (a *Alert) IsExpired(now time.Time) bool {
+	return a.ExpiresAt != nil && !now.Before(*a.ExpiresAt)
+}
+
+// InQuietHours 是否在生效時段外
+func (a *Alert) InQuietHours(now time.Time) bool {
+	return a.Schedule != nil && !a.Schedule.Active(now)
+}
+
+// IsExhausted 是否已達最多觸發次數
+func (a *Alert) IsExhausted() bool {
+	return a.MaxTriggers > 0 && a.TriggerCount >= a.MaxTriggers
+}
+
+// Crossed 價格是否自上次檢查後穿越目標價，返回穿越時的極值
+// prev 為上次檢查的價格，high、low 為這段期間（含最新價格）的最高與最低價
+func (a *Alert) Crossed(prev, high, low float64) (float64, bool) {
+	switch a.Direction {
+	case "above":
+		return high, prev < a.TargetPrice && high >= a.TargetPrice
+	case "below":
+		return low, prev > a.TargetPrice && low <= a.TargetPrice
+	}
+	return 0, false
+}
+
+// MoveThreshold 漲跌幅或速度警報的門檻
+func (a *Alert) MoveThreshold() float64 {
+	if a.AlertType == AlertTypeVelocity {
+		return a.VelocityPct
+	}
+	return a.MovePct
+}
+
+// MoveMagnitude 依警報方向取出要與門檻比較的變動幅度，以及帶正負號的變動
+// up、down 為視窗內上漲與下跌的幅度（皆不小於 0）
+func (a *Alert) MoveMagnitude(up, down float64) (magnitude, signed float64) {
+	switch a.Direction {
+	case "up":
+		return up, up
+	case "down":
+		return down, -down
+	}
+	if down > up {
+		return down, -down
+	}
+	return up, up
+}
+
+// ShouldRearm 停用中的重複警報是否已回到遲滯區間外
+// value 為最新價格（成交量警報為成交量，漲跌幅與速度警報為 MoveMagnitude）；value_area 警報另以是否回到價值區內判斷
+func (a *Alert) ShouldRearm(value float64) bool {
+	band := a.RearmPct / 100
+	switch {
+	case a.AlertType == "volume":
+		return value < a.TargetVolume*(1-band)
+	case a.AlertType == AlertTypePercentMove || a.AlertType == AlertTypeVelocity:
+		return value < a.MoveThreshold()*(1-band)
+	case a.Direction == "below":
+		return value >= a.TargetPrice*(1+band)
+	default:
+		return value <= a.TargetPrice*(1-band)
+	}
+}
+
+// TrailDistance 追蹤停損的回撤距離，atr 為 atr 模式使用的最新 ATR
+func (a *Alert) TrailDistance(atr float64) float64 {
+	switch a.TrailMode {
+	case TrailModePercent:
+		return a.TrailExtreme * a.TrailPct / 100
+	case TrailModeATR:
+		return atr * a.TrailATRMultiple
+	default:
+		return a.TrailAmount
+	}
+}
+
+// Trail 以上次檢查後的最高與最低價更新追蹤極值，返回價格是否回撤到停損價與觸發時的價格
+// 同一段期間內無法分辨高低點的先後，先以原本的極值判斷是否觸及停損，再更新極值並以最新價格判斷
+func (a *Alert) Trail(high, low, price, atr float64) (float64, bool) {
+	short := a.Direction == TrailDirectionShort
+	if a.TrailExtreme <= 0 {
+		a.TrailExtreme = price
+	}
+
+	a.TrailStopPrice = a.TrailStop(atr)
+	if !short && low <= a.TrailStopPrice {
+		return low, true
+	}
+	if short && high >= a.TrailStopPrice {
+		return high, true
+	}
+
+	if short {
+		a.TrailExtreme = math.Min(a.TrailExtreme, low)
+	} else {
+		a.TrailExtreme = math.Max(a.TrailExtreme, high)
+	}
+	a.TrailStopPrice = a.TrailStop(atr)
+	if (!short && price <= a.TrailStopPrice) || (short && price >= a.TrailStopPrice) {
+		return price, true
+	}
+	return 0, false
+}
+
+// TrailStop 依目前的極值計算追蹤停損價
+func (a *Alert) TrailStop(atr float64) float64 {
+	if a.Direction == TrailDirectionShort {
+		return a.TrailExtreme + a.TrailDistance(atr)
+	}
+	return a.TrailExtreme - a.TrailDistance(atr)
+}
+
+// NotifyChannelTelegram Telegram 通知管道
+const NotifyChannelTelegram = "telegram"
+
+// NotifyTarget 警報的通知對象
+type NotifyTarget struct {
+	Channel   string `json:"channel"`             // 通知管道，例如 "telegram"
+	ChatID    string `json:"chatId"`              // 管道內的收件對象，例如 Telegram Chat ID
+	Delivered bool   `json:"delivered,omitempty"` // 本次觸發已送達
 }
 
+// AlertTypeLevel 支撐壓力位突破警報：目標價由系統依最近的支撐壓力區自動設定
+const AlertTypeLevel = "level"
+
+// AlertTypeValueArea 價值區警報：價格離開成交量分佈的價值區時觸發
+// direction "above" 為突破價值區上緣，"below" 為跌破價值區下緣，留空則任一側皆觸發
+const AlertTypeValueArea = "value_area"
+
+// AlertTypePercentMove 漲跌幅警報：價格在 timeWindow 分鐘內漲跌超過 movePct%
+// direction "up" 只看上漲、"down" 只看下跌，留空則任一方向
+const AlertTypePercentMove = "percent_move"
+
+// AlertTypeVelocity 速度警報：價格在 timeWindow 分鐘內的平均變動速度超過 velocityPct %/分鐘
+// direction 與漲跌幅警報相同
+const AlertTypeVelocity = "velocity"
+
+// AlertTypeTrailingStop 追蹤停損警報：價格自建立以來的極值回撤超過固定金額、百分比或 N 倍 ATR 時觸發
+// direction "long" 追蹤最高價、價格下跌時觸發，"short" 追蹤最低價、價格上漲時觸發，預設 "long"
+const AlertTypeTrailingStop = "trailing_stop"
+
+// 追蹤停損方向
+const (
+	TrailDirectionLong  = "long"
+	TrailDirectionShort = "short"
+)
+
+// 追蹤停損的回撤方式
+const (
+	TrailModeAmount  = "amount"  // 固定金額
+	TrailModePercent = "percent" // 極值的百分比
+	TrailModeATR     = "atr"     // N 倍 ATR
+)
+
+// 追蹤停損參數
+const (
+	DefaultTrailATRInterval = "1h"
+	DefaultTrailATRPeriod   = 14
+	MaxTrailATRPeriod       = 100
+	MaxTrailATRMultiple     = 20.0
+	MaxTrailPct             = 50.0
+)
+
+// 漲跌幅與速度警報的參數上限
+const (
+	MaxMoveWindow     = 1000  // 漲跌幅警報的視窗上限（分鐘），一次抓取的 1 分鐘 K 線上限
+	MaxVelocityWindow = 60    // 速度警報的視窗上限（分鐘）
+	MaxMovePct        = 100.0 // 漲跌幅門檻上限（%）
+	MaxVelocityPct    = 50.0  // 速度門檻上限（%/分鐘）
+)
+
+// VolumeAlertWindows 成交量警報支援的視窗（分鐘），對應幣安 K 線週期
+var VolumeAlertWindows = []int{1, 3, 5, 15, 30, 60}
+
 type CreateAlertRequest struct {
-	UserID       string  `json:"userId" binding:"required"`
-	Symbol       string  `json:"symbol" binding:"required"`
-	AlertType    string  `json:"alertType" binding:"required"`
-	TargetPrice  float64 `json:"targetPrice,omitempty"`
-	Direction    string  `json:"direction,omitempty"`
-	TargetVolume float64 `json:"targetVolume,omitempty"`
-	TimeWindow   int     `json:"timeWindow,omitempty"`
+	UserID          string  `json:"userId" binding:"required"`
+	Symbol          string  `json:"symbol" binding:"required"`
+	AlertType       string  `json:"alertType" binding:"required"`
+	TargetPrice     float64 `json:"targetPrice,omitempty"`
+	Direction       string  `json:"direction,omitempty"`
+	TargetVolume    float64 `json:"targetVolume,omitempty"`
+	TimeWindow      int     `json:"timeWindow,omitempty"`      // 分鐘；成交量警報為 1、3、5、15、30、60，速度警報預設 1
+	MovePct         float64 `json:"movePct,omitempty"`         // percent_move 警報用
+	VelocityPct     float64 `json:"velocityPct,omitempty"`     // velocity 警報用
+	LevelInterval   string  `json:"levelInterval,omitempty"`   // level 警報用，預設 "4h"
+	ProfileInterval string  `json:"profileInterval,omitempty"` // value_area 警報用，預設 "1h"
+	ProfileLookback int     `json:"profileLookback,omitempty"` // value_area 警報用，預設 168
+
+	TrailMode        string  `json:"trailMode,omitempty"`        // trailing_stop 警報用："amount"、"percent" 或 "atr"
+	TrailAmount      float64 `json:"trailAmount,omitempty"`      // amount 模式用
+	TrailPct         float64 `json:"trailPct,omitempty"`         // percent 模式用
+	TrailATRMultiple float64 `json:"trailAtrMultiple,omitempty"` // atr 模式用
+	TrailATRInterval string  `json:"trailAtrInterval,omitempty"` // atr 模式用，預設 "1h"
+	TrailATRPeriod   int     `json:"trailAtrPeriod,omitempty"`   // atr 模式用，預設 14
+
+	Targets []NotifyTarget `json:"targets,omitempty"` // 通知對象，留空則觸發時只記錄 log
+
+	Repeat      bool       `json:"repeat,omitempty"`      // 重複觸發，預設為單次
+	RearmPct    float64    `json:"rearmPct,omitempty"`    // 重複警報用，預設 0.5
+	MaxTriggers int        `json:"maxTriggers,omitempty"` // 重複警報用，0 表示不限
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`   // 到期時間，留空則不過期
+
+	Schedule *AlertSchedule `json:"schedule,omitempty"` // 生效時段，留空則全天生效
 }
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// 警報歷史查詢
+const (
+	DefaultAlertHistoryLimit = 50
+	MaxAlertHistoryLimit     = 200
+)
+
+// AlertEvent 警報的一次觸發紀錄
+type AlertEvent struct {
+	EventID      string           `json:"eventId"`
+	AlertID      string           `json:"alertId"`
+	UserID       string           `json:"userId"`
+	Symbol       string           `json:"symbol"`
+	AlertType    string           `json:"alertType"`
+	Direction    string           `json:"direction,omitempty"`
+	TargetPrice  float64          `json:"targetPrice,omitempty"`
+	TargetVolume float64          `json:"targetVolume,omitempty"`
+	Price        float64          `json:"price"`        // 觸發時的最新價格
+	Volume       float64          `json:"volume"`       // 成交量警報為視窗成交量，其他警報為 24h 成交量
+	Value        float64          `json:"value"`        // 觸發值：價格、成交量或漲跌幅
+	TriggerCount int              `json:"triggerCount"` // 第幾次觸發（重複警報）
+	TriggeredAt  time.Time        `json:"triggeredAt"`
+	Deliveries   []DeliveryResult `json:"deliveries"`
+	CompletedAt  *time.Time       `json:"completedAt,omitempty"` // 通知流程結束時間（全部送達或逾時放棄）
+}
+
+// DeliveryResult 單一通知對象的送達結果
+type DeliveryResult struct {
+	Channel     string     `json:"channel"`
+	ChatID      string     `json:"chatId"`
+	Delivered   bool       `json:"delivered"`
+	Attempts    int        `json:"attempts"`
+	Error       string     `json:"error,omitempty"` // 最近一次失敗的錯誤訊息
+	DeliveredAt *time.Time `json:"deliveredAt,omitempty"`
+}
+
+// AlertHistoryQuery 警報歷史查詢條件
+type AlertHistoryQuery struct {
+	UserID  string
+	AlertID string    // 只查詢單一警報，留空則為用戶所有警報
+	From    time.Time // 觸發時間下限（含），零值表示不限
+	To      time.Time // 觸發時間上限（不含），零值表示不限
+	Limit   int
+	Offset  int
+}
+
+// AlertHistoryPage 警報歷史的一頁結果（新到舊）
+type AlertHistoryPage struct {
+	Events []*AlertEvent `json:"events"`
+	Total  int64         `json:"total"` // 符合條件的總筆數
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+}
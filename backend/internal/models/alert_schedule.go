@@ -0,0 +1,81 @@
+package models
+
+import (
+	"slices"
+	"time"
+	_ "time/tzdata" // 容器內不一定有系統時區資料
+)
+
+// AlertSchedule 警報的生效時段，以用戶時區的星期與小時計算，其餘時間為安靜時段
+// 安靜時段預設不觸發；DeferQuiet 時照常偵測，通知延到安靜時段結束才送出
+type AlertSchedule struct {
+	Timezone   string `json:"timezone,omitempty"`   // IANA 時區，例如 "Asia/Taipei"，預設 UTC
+	Weekdays   []int  `json:"weekdays,omitempty"`   // 生效的星期（0 為週日），以當地日期判斷，留空表示每天
+	StartHour  int    `json:"startHour"`            // 每日生效時段開始（含），0~23
+	EndHour    int    `json:"endHour"`              // 每日生效時段結束（不含），0~24；與 startHour 相同表示全天，小於 startHour 表示跨午夜
+	DeferQuiet bool   `json:"deferQuiet,omitempty"` // 安靜時段內觸發的通知延到下一個生效時段開始時送出
+}
+
+// scheduleSearchHours 尋找下一個生效時段的範圍，涵蓋一整週
+const scheduleSearchHours = 8 * 24
+
+// Validate 驗證生效時段設定
+func (s *AlertSchedule) Validate() error {
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return invalid("schedule.timezone", "無效的時區 %q", s.Timezone)
+	}
+	for _, day := range s.Weekdays {
+		if day < 0 || day > 6 {
+			return invalid("schedule.weekdays", "必須介於 0（週日）到 6（週六）之間")
+		}
+	}
+	if s.StartHour < 0 || s.StartHour > 23 {
+		return invalid("schedule.startHour", "必須介於 0 到 23 之間")
+	}
+	if s.EndHour < 0 || s.EndHour > 24 {
+		return invalid("schedule.endHour", "必須介於 0 到 24 之間")
+	}
+	return nil
+}
+
+// location 用戶時區，無效時使用 UTC
+func (s *AlertSchedule) location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Active t 是否在生效時段內
+func (s *AlertSchedule) Active(t time.Time) bool {
+	local := t.In(s.location())
+	if len(s.Weekdays) > 0 && !slices.Contains(s.Weekdays, int(local.Weekday())) {
+		return false
+	}
+
+	hour := local.Hour()
+	switch {
+	case s.StartHour == s.EndHour%24:
+		return true
+	case s.StartHour < s.EndHour:
+		return hour >= s.StartHour && hour < s.EndHour
+	default:
+		return hour >= s.StartHour || hour < s.EndHour
+	}
+}
+
+// NextActive t 之後下一個生效時段的開始時間，t 已在生效時段內時返回 t
+func (s *AlertSchedule) NextActive(t time.Time) time.Time {
+	if s.Active(t) {
+		return t
+	}
+	local := t.In(s.location())
+	for i := 1; i <= scheduleSearchHours; i++ {
+		next := time.Date(local.Year(), local.Month(), local.Day(), local.Hour()+i, 0, 0, 0, local.Location())
+		if s.Active(next) {
+			return next
+		}
+	}
+	return t
+}
@@ -0,0 +1,174 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// 回測範圍限制
+const (
+	DefaultBacktestBars = 1000 // 未指定開始時間時回測的 K 線根數
+	MaxBacktestBars     = 5000 // 單次回測最多的 K 線根數
+	MaxBacktestHorizon  = 200  // 前瞻報酬最長的 K 線根數
+)
+
+// DefaultBacktestHorizons 預設的前瞻報酬 K 線根數
+var DefaultBacktestHorizons = []int{1, 3, 6, 12}
+
+// BacktestRequest 回測請求：以歷史 K 線重播 LRC 突破訂閱的判斷邏輯
+// 訂閱設定欄位與 IndicatorSubscription 同名欄位意義相同，未提供時套用相同的預設值
+type BacktestRequest struct {
+	Symbol           string    `json:"symbol" binding:"required"`
+	Interval         string    `json:"interval"`         // LRC 週期，預設 "4h"
+	Start            time.Time `json:"start"`            // 回測開始時間，留空則取結束時間往前 Bars 根
+	End              time.Time `json:"end"`              // 回測結束時間，留空則為現在
+	Bars             int       `json:"bars"`             // 未指定開始時間時回測的 K 線根數，預設 1000
+	LRCLength        int       `json:"lrcLength"`        // 預設 42
+	LRCDevMultiplier float64   `json:"lrcDevMultiplier"` // 預設 2.0
+	Horizons         []int     `json:"horizons"`         // 前瞻報酬的 K 線根數，預設 [1, 3, 6, 12]
+
+	NotifyIntervalMin         int     `json:"notifyIntervalMin"` // 冷卻時間（分鐘），預設 60
+	EnableVolumeCheck         bool    `json:"enableVolumeCheck"`
+	VolumeCheckMode           string  `json:"volumeCheckMode"`
+	VolumeFixedValue          float64 `json:"volumeFixedValue"`
+	VolumeMultiplier          float64 `json:"volumeMultiplier"`
+	VolumeAvgPeriod           int     `json:"volumeAvgPeriod"`
+	VolumeScoreThreshold      float64 `json:"volumeScoreThreshold"`
+	VolumeTakerRatio          float64 `json:"volumeTakerRatio"`
+	TriggerMode               string  `json:"triggerMode"` // intrabar 模式以 K 線收盤價近似盤中價格
+	ConfirmCloses             int     `json:"confirmCloses"`
+	MinPearsonR               float64 `json:"minPearsonR"`
+	SlopeFilter               string  `json:"slopeFilter"`
+	RequireCompressedBreakout bool    `json:"requireCompressedBreakout"`
+	CompressedWithinBars      int     `json:"compressedWithinBars"`
+}
+
+// ApplyDefaults 套用預設值
+func (r *BacktestRequest) ApplyDefaults() {
+	if r.Interval == "" {
+		r.Interval = "4h"
+	}
+	if r.End.IsZero() {
+		r.End = time.Now()
+	}
+	if r.Bars <= 0 {
+		r.Bars = DefaultBacktestBars
+	}
+	if r.LRCLength <= 0 {
+		r.LRCLength = 42
+	}
+	if r.LRCDevMultiplier <= 0 {
+		r.LRCDevMultiplier = 2.0
+	}
+	if len(r.Horizons) == 0 {
+		r.Horizons = slices.Clone(DefaultBacktestHorizons)
+	}
+}
+
+// Validate 驗證回測範圍與參數（K 線週期與訂閱設定由 service 層驗證）
+func (r *BacktestRequest) Validate() error {
+	if r.LRCLength < 2 || r.LRCLength > 500 {
+		return invalid("lrcLength", "必須介於 2 到 500 之間")
+	}
+	if !r.Start.IsZero() && !r.Start.Before(r.End) {
+		return invalid("start", "必須早於 end")
+	}
+	if r.Bars > MaxBacktestBars {
+		return invalid("bars", "不能超過 %d", MaxBacktestBars)
+	}
+	for _, h := range r.Horizons {
+		if h < 1 || h > MaxBacktestHorizon {
+			return invalid("horizons", "必須介於 1 到 %d 之間", MaxBacktestHorizon)
+		}
+	}
+	return nil
+}
+
+// Subscription 以回測參數建立訂閱，套用與創建訂閱相同的預設值
+func (r *BacktestRequest) Subscription() *IndicatorSubscription {
+	req := CreateSubscriptionRequest{
+		Symbol:                    r.Symbol,
+		NotifyIntervalMin:         r.NotifyIntervalMin,
+		EnableVolumeCheck:         r.EnableVolumeCheck,
+		VolumeCheckMode:           r.VolumeCheckMode,
+		VolumeFixedValue:          r.VolumeFixedValue,
+		VolumeMultiplier:          r.VolumeMultiplier,
+		VolumeAvgPeriod:           r.VolumeAvgPeriod,
+		VolumeScoreThreshold:      r.VolumeScoreThreshold,
+		VolumeTakerRatio:          r.VolumeTakerRatio,
+		TriggerMode:               r.TriggerMode,
+		ConfirmCloses:             r.ConfirmCloses,
+		MinPearsonR:               r.MinPearsonR,
+		SlopeFilter:               r.SlopeFilter,
+		RequireCompressedBreakout: r.RequireCompressedBreakout,
+		CompressedWithinBars:      r.CompressedWithinBars,
+	}
+	req.ApplyDefaults()
+
+	return &IndicatorSubscription{
+		Symbol:                    req.Symbol,
+		Enabled:                   true,
+		NotifyIntervalMin:         req.NotifyIntervalMin,
+		EnableVolumeCheck:         req.EnableVolumeCheck,
+		VolumeCheckMode:           req.VolumeCheckMode,
+		VolumeFixedValue:          req.VolumeFixedValue,
+		VolumeMultiplier:          req.VolumeMultiplier,
+		VolumeAvgPeriod:           req.VolumeAvgPeriod,
+		VolumeScoreThreshold:      req.VolumeScoreThreshold,
+		VolumeTakerRatio:          req.VolumeTakerRatio,
+		TriggerMode:               req.TriggerMode,
+		ConfirmCloses:             req.ConfirmCloses,
+		MinPearsonR:               req.MinPearsonR,
+		SlopeFilter:               req.SlopeFilter,
+		RequireCompressedBreakout: req.RequireCompressedBreakout,
+		CompressedWithinBars:      req.CompressedWithinBars,
+	}
+}
+
+// ForwardReturn 訊號後第 N 根 K 線收盤的報酬率
+type ForwardReturn struct {
+	Bars      int     `json:"bars"`
+	ReturnPct float64 `json:"returnPct"` // 依突破方向調整：跌破下軌以做空計算
+}
+
+// BacktestSignal 回測期間會發出的一次通知
+type BacktestSignal struct {
+	Time        time.Time       `json:"time"`      // 訊號 K 線的收盤時間
+	Direction   string          `json:"direction"` // "above_upper" 或 "below_lower"
+	Price       float64         `json:"price"`     // 訊號 K 線收盤價
+	UpperBand   float64         `json:"upperBand"`
+	LowerBand   float64         `json:"lowerBand"`
+	PearsonR    float64         `json:"pearsonR"`
+	VolumeRatio float64         `json:"volumeRatio,omitempty"` // 啟用成交量檢查時的 1 分 K 成交量倍數
+	Returns     []ForwardReturn `json:"returns"`               // 資料不足的期間不列出
+	MAEPct      float64         `json:"maePct"`                // 最長前瞻期間內的最大不利偏移（%）
+}
+
+// HorizonStats 單一前瞻期間的統計
+type HorizonStats struct {
+	Bars            int     `json:"bars"`
+	Signals         int     `json:"signals"`         // 有足夠後續資料的訊號數
+	HitRate         float64 `json:"hitRate"`         // 報酬為正的比例（0~1）
+	AvgReturnPct    float64 `json:"avgReturnPct"`    // 平均報酬率（%）
+	MedianReturnPct float64 `json:"medianReturnPct"` // 報酬率中位數（%）
+}
+
+// BacktestResult 回測結果
+type BacktestResult struct {
+	Symbol           string    `json:"symbol"`
+	Interval         string    `json:"interval"`
+	LRCLength        int       `json:"lrcLength"`
+	LRCDevMultiplier float64   `json:"lrcDevMultiplier"`
+	Start            time.Time `json:"start"`
+	End              time.Time `json:"end"`
+	BarsEvaluated    int       `json:"barsEvaluated"`
+
+	Signals  []BacktestSignal `json:"signals"`
+	Horizons []HorizonStats   `json:"horizons"`
+	AvgMAE   float64          `json:"avgMaePct"` // 平均最大不利偏移（%）
+	MaxMAE   float64          `json:"maxMaePct"` // 最大的最大不利偏移（%）
+
+	// 被過濾掉的突破次數
+	SkippedByCooldown int `json:"skippedByCooldown"`
+	SkippedByVolume   int `json:"skippedByVolume"`
+}
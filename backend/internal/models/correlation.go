@@ -0,0 +1,57 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// BenchmarkSymbol 計算 Beta 的基準幣種
+const BenchmarkSymbol = "BTC"
+
+// 相關係數計算視窗（報酬率根數）
+const (
+	DefaultCorrelationWindow = 100
+	MaxCorrelationWindow     = 500
+)
+
+// DefaultBTCCorrelationMin 判斷跟隨 BTC 的預設最小相關係數
+const DefaultBTCCorrelationMin = 0.8
+
+// SymbolCorrelation 單一幣種相對 BTC 的統計
+type SymbolCorrelation struct {
+	Symbol         string  `json:"symbol"`
+	BTCCorrelation float64 `json:"btcCorrelation"` // 與 BTC 報酬率的相關係數
+	Beta           float64 `json:"beta"`           // 相對 BTC 的 Beta
+	Return         float64 `json:"return"`         // 最近一根已收盤 K 線的對數報酬率
+	Residual       float64 `json:"residual"`       // 最近一根的特有報酬：Return - Beta × BTC 報酬率
+}
+
+// IsBTCDriven 是否只是跟隨 BTC 波動：相關係數達標，且最近一根的報酬有一半以上可由 Beta × BTC 報酬解釋
+func (c *SymbolCorrelation) IsBTCDriven(minCorrelation float64) bool {
+	if c.Symbol == BenchmarkSymbol || c.BTCCorrelation < minCorrelation {
+		return false
+	}
+	return math.Abs(c.Residual) < math.Abs(c.Return)/2
+}
+
+// CorrelationMatrix 監控幣種之間的報酬率相關矩陣
+type CorrelationMatrix struct {
+	Interval string              `json:"interval"`
+	Window   int                 `json:"window"`  // 使用的報酬率根數
+	Symbols  []string            `json:"symbols"` // 矩陣的行列順序
+	Matrix   [][]float64         `json:"matrix"`  // Matrix[i][j] 為 Symbols[i] 與 Symbols[j] 的相關係數
+	Stats    []SymbolCorrelation `json:"stats"`
+
+	UpdatedAt     time.Time `json:"updatedAt"`
+	NextRefreshAt time.Time `json:"nextRefreshAt"` // 下一根 K 線收盤後重新計算
+}
+
+// Stat 獲取幣種相對 BTC 的統計
+func (m *CorrelationMatrix) Stat(symbol string) (*SymbolCorrelation, bool) {
+	for i := range m.Stats {
+		if m.Stats[i].Symbol == symbol {
+			return &m.Stats[i], true
+		}
+	}
+	return nil, false
+}
@@ -12,6 +12,8 @@ type IndicatorResult struct {
 	CenterLine float64 `json:"centerLine"`
 	Slope      float64 `json:"slope"`
 	Deviation  float64 `json:"deviation"`
+	PearsonR   float64 `json:"pearsonR"` // 通道擬合度
+	SlopePct   float64 `json:"slopePct"` // 標準化斜率（%/根）
 
 	// 當前價格
 	CurrentPrice float64 `json:"currentPrice"`
@@ -21,10 +23,29 @@ type IndicatorResult struct {
 	AvgVolume     float64 `json:"avgVolume"`     // 近 N 根平均
 	VolumeRatio   float64 `json:"volumeRatio"`   // 當前/平均 比值
 
+	// 1 分 K 成交量異常分數（啟用季節性基準時與近 N 天同一 UTC 小時的 1 分 K 比較）
+	MedianVolume         float64 `json:"medianVolume"`         // 近 N 根中位數
+	VolumeZScore         float64 `json:"volumeZScore"`         // Z 分數
+	VolumeMADScore       float64 `json:"volumeMadScore"`       // 穩健 Z 分數（中位數/MAD）
+	VolumeSeasonalFactor float64 `json:"volumeSeasonalFactor"` // 同時段基準中位數 / 近 N 根中位數，1 表示一般時段
+
+	// 1 分 K 成交額與主動買賣
+	CurrentQuoteVolume float64 `json:"currentQuoteVolume"` // 當前成交額（USDT）
+	AvgQuoteVolume     float64 `json:"avgQuoteVolume"`     // 近 N 根平均成交額（USDT）
+	TakerBuyRatio      float64 `json:"takerBuyRatio"`      // 主動買入佔比（0~1）
+
 	// 狀態
 	IsAboveUpper bool `json:"isAboveUpper"`
 	IsBelowLower bool `json:"isBelowLower"`
 
+	// 收盤確認狀態（已收盤 K 線）
+	ClosesAboveUpper int       `json:"closesAboveUpper"` // 最近連續收盤在上軌之上的根數
+	ClosesBelowLower int       `json:"closesBelowLower"` // 最近連續收盤在下軌之下的根數
+	LastCloseTime    time.Time `json:"lastCloseTime"`    // 最近一根已收盤 K 線的收盤時間
+
+	// LRC 週期的已實現波動率（已收盤 K 線），資料不足時為空
+	Volatility *Volatility `json:"volatility,omitempty"`
+
 	// 計算時間
 	CalculatedAt time.Time `json:"calculatedAt"`
 }
@@ -44,6 +65,13 @@ type IndicatorConfig struct {
 
 	// 1 分 K 成交量預設參數
 	DefaultVolumeAvgPeriod int `json:"defaultVolumeAvgPeriod"` // 預設 20
+
+	// 成交量季節性基準：以近 N 天同一 UTC 小時的 1 分 K 線作為異常分數的基準，0 表示不使用
+	VolumeSeasonalDays int `json:"volumeSeasonalDays"` // 預設 7
+
+	// 已實現波動率：每個值使用的根數，以及判斷收斂/擴張的歷史根數
+	VolatilityWindow  int `json:"volatilityWindow"`  // 預設 20
+	VolatilityHistory int `json:"volatilityHistory"` // 預設 200
 }
 
 // DefaultIndicatorConfig 返回預設配置
@@ -75,9 +103,28 @@ func DefaultIndicatorConfig() IndicatorConfig {
 		LRCDevMultiplier:       2.0,
 		LRCInterval:            "4h",
 		DefaultVolumeAvgPeriod: 20,
+		VolumeSeasonalDays:     7,
+		VolatilityWindow:       20,
+		VolatilityHistory:      200,
 	}
 }
 
+// Volatility 已實現波動率（年化，0.5 表示 50%）與波動率狀態
+type Volatility struct {
+	Interval            string  `json:"interval"`
+	CloseToClose        float64 `json:"closeToClose"`        // 收盤對收盤
+	Parkinson           float64 `json:"parkinson"`           // Parkinson（最高/最低價）
+	GarmanKlass         float64 `json:"garmanKlass"`         // Garman-Klass（開高低收）
+	Percentile          float64 `json:"percentile"`          // Garman-Klass 波動率在歷史中的百分位（0~100）
+	Regime              string  `json:"regime"`              // "compressed"（百分位 ≤ 20）、"normal" 或 "expanded"（百分位 ≥ 80）
+	BarsSinceCompressed int     `json:"barsSinceCompressed"` // 距離最近一根收斂 K 線的根數，0 表示最新一根，-1 表示歷史中沒有
+}
+
+// CompressedWithin 最近 bars 根已收盤 K 線內是否出現過波動收斂
+func (v *Volatility) CompressedWithin(bars int) bool {
+	return v.BarsSinceCompressed >= 0 && v.BarsSinceCompressed <= bars
+}
+
 // AlertPayload 推播通知內容
 type AlertPayload struct {
 	Title        string            `json:"title"`
@@ -89,4 +136,3 @@ type AlertPayload struct {
 	LowerBand    float64           `json:"lowerBand"`
 	Data         map[string]string `json:"data,omitempty"`
 }
-
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// SRLevel 水平支撐壓力區域
+type SRLevel struct {
+	Price       float64 `json:"price"`       // 區域代表價格
+	Lower       float64 `json:"lower"`       // 區域下緣
+	Upper       float64 `json:"upper"`       // 區域上緣
+	Touches     int     `json:"touches"`     // 擺動高低點觸及次數
+	VolumeShare float64 `json:"volumeShare"` // 成交量密集區佔比（0~1）
+	Strength    float64 `json:"strength"`    // 強度
+	Kind        string  `json:"kind"`        // 相對更新時價格："resistance" 或 "support"
+}
+
+// 支撐壓力類型
+const (
+	LevelKindResistance = "resistance"
+	LevelKindSupport    = "support"
+)
+
+// SRLevels 幣種在某個週期的支撐壓力位
+type SRLevels struct {
+	Symbol        string    `json:"symbol"`
+	Interval      string    `json:"interval"`
+	Price         float64   `json:"price"` // 更新時的價格
+	Levels        []SRLevel `json:"levels"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	NextRefreshAt time.Time `json:"nextRefreshAt"` // 下一根 K 線收盤後重新計算
+}
+
+// NearestResistance 價格上方最近的壓力區
+func (l *SRLevels) NearestResistance(price float64) (*SRLevel, bool) {
+	var nearest *SRLevel
+	for i := range l.Levels {
+		level := &l.Levels[i]
+		if level.Price > price && (nearest == nil || level.Price < nearest.Price) {
+			nearest = level
+		}
+	}
+	return nearest, nearest != nil
+}
+
+// NearestSupport 價格下方最近的支撐區
+func (l *SRLevels) NearestSupport(price float64) (*SRLevel, bool) {
+	var nearest *SRLevel
+	for i := range l.Levels {
+		level := &l.Levels[i]
+		if level.Price < price && (nearest == nil || level.Price > nearest.Price) {
+			nearest = level
+		}
+	}
+	return nearest, nearest != nil
+}
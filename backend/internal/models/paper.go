@@ -0,0 +1,217 @@
+package models
+
+import "time"
+
+// 模擬交易停損方式
+const (
+	PaperStopBand    = "band"    // 反向通道線：做多停在下軌，做空停在上軌
+	PaperStopATR     = "atr"     // 進場價 ± N 倍 ATR
+	PaperStopPercent = "percent" // 進場價 ± N%
+)
+
+// 模擬部位狀態
+const (
+	PaperStatusOpen   = "open"
+	PaperStatusClosed = "closed"
+)
+
+// 模擬部位出場原因
+const (
+	PaperExitStop       = "stop"        // 觸及停損
+	PaperExitTakeProfit = "take_profit" // 觸及停利
+	PaperExitReverse    = "reverse"     // 反向訊號平倉
+)
+
+// 模擬交易參數上限
+const (
+	MaxPaperATRPeriod     = 100
+	MaxPaperATRMultiple   = 20.0
+	MaxPaperStopPct       = 50.0
+	MaxPaperTakeProfitR   = 20.0
+	MaxPaperTakeProfitPct = 1000.0
+	MaxPaperFeePct        = 1.0
+	MaxPaperSlippagePct   = 5.0
+)
+
+// PaperTradingConfig 訂閱的模擬交易設定
+// 啟用後每次通知都以最新價格開立模擬部位，同方向已有部位時不加碼，反向訊號則先平倉再開倉
+type PaperTradingConfig struct {
+	Enabled         bool    `json:"enabled"`
+	SizeUSDT        float64 `json:"sizeUsdt"`        // 每筆部位的名目金額（USDT），預設 1000
+	StopMode        string  `json:"stopMode"`        // "band"、"atr" 或 "percent"，預設 "band"（僅限 LRC 突破訂閱）
+	StopATRMultiple float64 `json:"stopAtrMultiple"` // atr 模式：N 倍 ATR，預設 2
+	ATRPeriod       int     `json:"atrPeriod"`       // atr 模式：ATR 週期，以觸發判斷的 K 線週期計算，預設 14
+	StopPct         float64 `json:"stopPct"`         // percent 模式：停損百分比，預設 2
+	TakeProfitR     float64 `json:"takeProfitR"`     // 停利為停損距離的 N 倍，0 表示不設
+	TakeProfitPct   float64 `json:"takeProfitPct"`   // 停利百分比，0 表示不設；與 takeProfitR 擇一
+	FeePct          float64 `json:"feePct"`          // 單邊手續費（%），預設 0.04
+	SlippagePct     float64 `json:"slippagePct"`     // 單邊滑價（%），預設 0.05
+}
+
+// ApplyDefaults 套用預設值，未啟用時不處理
+func (c *PaperTradingConfig) ApplyDefaults() {
+	if !c.Enabled {
+		return
+	}
+	if c.SizeUSDT <= 0 {
+		c.SizeUSDT = 1000
+	}
+	if c.StopMode == "" {
+		c.StopMode = PaperStopBand
+	}
+	if c.StopMode == PaperStopATR {
+		if c.StopATRMultiple <= 0 {
+			c.StopATRMultiple = 2
+		}
+		if c.ATRPeriod <= 0 {
+			c.ATRPeriod = 14
+		}
+	}
+	if c.StopMode == PaperStopPercent && c.StopPct <= 0 {
+		c.StopPct = 2
+	}
+	if c.FeePct <= 0 {
+		c.FeePct = 0.04 // 幣安合約 taker 手續費
+	}
+	if c.SlippagePct <= 0 {
+		c.SlippagePct = 0.05
+	}
+}
+
+// Validate 驗證模擬交易設定，未啟用時不檢查
+func (c *PaperTradingConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.SizeUSDT <= 0 {
+		return invalid("paperTrading.sizeUsdt", "必須大於 0")
+	}
+	switch c.StopMode {
+	case PaperStopBand:
+	case PaperStopATR:
+		if c.StopATRMultiple <= 0 || c.StopATRMultiple > MaxPaperATRMultiple {
+			return invalid("paperTrading.stopAtrMultiple", "必須介於 0 到 %g 之間", MaxPaperATRMultiple)
+		}
+		if c.ATRPeriod < 1 || c.ATRPeriod > MaxPaperATRPeriod {
+			return invalid("paperTrading.atrPeriod", "必須介於 1 到 %d 之間", MaxPaperATRPeriod)
+		}
+	case PaperStopPercent:
+		if c.StopPct <= 0 || c.StopPct > MaxPaperStopPct {
+			return invalid("paperTrading.stopPct", "必須介於 0 到 %g 之間", MaxPaperStopPct)
+		}
+	default:
+		return invalid("paperTrading.stopMode", "不支援的停損方式 %q", c.StopMode)
+	}
+	if c.TakeProfitR < 0 || c.TakeProfitR > MaxPaperTakeProfitR {
+		return invalid("paperTrading.takeProfitR", "必須介於 0 到 %g 之間", MaxPaperTakeProfitR)
+	}
+	if c.TakeProfitPct < 0 || c.TakeProfitPct > MaxPaperTakeProfitPct {
+		return invalid("paperTrading.takeProfitPct", "必須介於 0 到 %g 之間", MaxPaperTakeProfitPct)
+	}
+	if c.TakeProfitR > 0 && c.TakeProfitPct > 0 {
+		return invalid("paperTrading.takeProfitR", "takeProfitR 與 takeProfitPct 只能擇一設定")
+	}
+	if c.FeePct < 0 || c.FeePct > MaxPaperFeePct {
+		return invalid("paperTrading.feePct", "必須介於 0 到 %g 之間", MaxPaperFeePct)
+	}
+	if c.SlippagePct < 0 || c.SlippagePct > MaxPaperSlippagePct {
+		return invalid("paperTrading.slippagePct", "必須介於 0 到 %g 之間", MaxPaperSlippagePct)
+	}
+	return nil
+}
+
+// PaperPosition 模擬部位
+type PaperPosition struct {
+	PositionID      string     `json:"positionId"`
+	UserID          string     `json:"userId"`
+	SubscriptionID  string     `json:"subscriptionId"`
+	Symbol          string     `json:"symbol"`
+	Side            string     `json:"side"`                      // "long" 或 "short"
+	Status          string     `json:"status"`                    // "open" 或 "closed"
+	SignalPrice     float64    `json:"signalPrice"`               // 訊號價格
+	EntryPrice      float64    `json:"entryPrice"`                // 含滑價的進場成交價
+	Quantity        float64    `json:"quantity"`                  // 幣數量
+	SizeUSDT        float64    `json:"sizeUsdt"`                  // 進場名目金額
+	StopPrice       float64    `json:"stopPrice"`                 // 停損價
+	TakeProfitPrice float64    `json:"takeProfitPrice,omitempty"` // 停利價，0 表示不設
+	FeePct          float64    `json:"feePct"`                    // 單邊手續費（%）
+	SlippagePct     float64    `json:"slippagePct"`               // 單邊滑價（%）
+	Fees            float64    `json:"fees"`                      // 已支付手續費（USDT）
+	OpenedAt        time.Time  `json:"openedAt"`
+	ExitPrice       float64    `json:"exitPrice,omitempty"`  // 含滑價的出場成交價
+	ExitReason      string     `json:"exitReason,omitempty"` // "stop"、"take_profit" 或 "reverse"
+	ClosedAt        *time.Time `json:"closedAt,omitempty"`
+	RealizedPnL     float64    `json:"realizedPnl"` // 已實現損益（扣除手續費，USDT）
+
+	// 以下僅在查詢未平倉部位時依最新價格計算
+	MarkPrice     float64 `json:"markPrice,omitempty"`
+	UnrealizedPnL float64 `json:"unrealizedPnl,omitempty"` // 以最新價格平倉的損益（扣除進出場手續費與滑價）
+}
+
+// Direction 損益的正負號，做空為 -1
+func (p *PaperPosition) Direction() float64 {
+	if p.Side == SignalSideShort {
+		return -1
+	}
+	return 1
+}
+
+// ExitReasonAt 最新價格觸及的出場條件，未觸及返回空字串
+func (p *PaperPosition) ExitReasonAt(price float64) string {
+	d := p.Direction()
+	if d*(price-p.StopPrice) <= 0 {
+		return PaperExitStop
+	}
+	if p.TakeProfitPrice > 0 && d*(price-p.TakeProfitPrice) >= 0 {
+		return PaperExitTakeProfit
+	}
+	return ""
+}
+
+// exitFill 以最新價格出場的成交價與損益（扣除進出場手續費）
+func (p *PaperPosition) exitFill(price float64) (fill, pnl, fee float64) {
+	fill = price * (1 - p.Direction()*p.SlippagePct/100)
+	fee = fill * p.Quantity * p.FeePct / 100
+	pnl = p.Direction()*(fill-p.EntryPrice)*p.Quantity - p.Fees - fee
+	return fill, pnl, fee
+}
+
+// Close 以最新價格平倉，成交價以觸價後的市價計算，跳空時不會優於停損價
+func (p *PaperPosition) Close(price float64, reason string, at time.Time) {
+	fill, pnl, fee := p.exitFill(price)
+	p.Status = PaperStatusClosed
+	p.ExitPrice = fill
+	p.ExitReason = reason
+	p.ClosedAt = &at
+	p.Fees += fee
+	p.RealizedPnL = pnl
+	p.MarkPrice, p.UnrealizedPnL = 0, 0
+}
+
+// Mark 以最新價格計算未實現損益
+func (p *PaperPosition) Mark(price float64) {
+	_, pnl, _ := p.exitFill(price)
+	p.MarkPrice = price
+	p.UnrealizedPnL = pnl
+}
+
+// EquityPoint 權益曲線上的一點：每筆平倉後的累計已實現損益
+type EquityPoint struct {
+	Time       time.Time `json:"time"`
+	Equity     float64   `json:"equity"` // 累計已實現損益（USDT）
+	PositionID string    `json:"positionId"`
+}
+
+// PaperAccount 用戶的模擬交易帳戶摘要
+type PaperAccount struct {
+	UserID          string        `json:"userId"`
+	OpenPositions   int           `json:"openPositions"`
+	ClosedPositions int           `json:"closedPositions"`
+	WinRate         float64       `json:"winRate"`       // 已平倉部位中獲利的比例（0~1）
+	RealizedPnL     float64       `json:"realizedPnl"`   // 累計已實現損益（USDT）
+	UnrealizedPnL   float64       `json:"unrealizedPnl"` // 未平倉部位的未實現損益（USDT）
+	Equity          float64       `json:"equity"`        // 已實現 + 未實現損益（USDT）
+	Fees            float64       `json:"fees"`          // 累計手續費（USDT）
+	MaxDrawdown     float64       `json:"maxDrawdown"`   // 已實現權益曲線的最大回撤（USDT）
+	EquityCurve     []EquityPoint `json:"equityCurve"`
+}
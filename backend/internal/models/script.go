@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"cryptowatch/internal/pine"
+)
+
+// Script 用戶上傳的 Pine Script 腳本（子集）
+type Script struct {
+	ScriptID string `json:"scriptId"`
+	UserID   string `json:"userId"`
+	Name     string `json:"name"`
+	Source   string `json:"source"`
+
+	// 編譯時解析出的宣告資訊
+	Inputs []pine.Input `json:"inputs"` // input() 參數與預設值
+	Alerts []string     `json:"alerts"` // alertcondition() 標題
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CreateScriptRequest 上傳腳本請求
+type CreateScriptRequest struct {
+	UserID string `json:"userId" binding:"required"`
+	Name   string `json:"name" binding:"required"`
+	Source string `json:"source" binding:"required"`
+}
+
+// RunScriptRequest 試跑腳本請求
+type RunScriptRequest struct {
+	Symbol   string             `json:"symbol" binding:"required"`
+	Interval string             `json:"interval"` // 預設 "4h"
+	Limit    int                `json:"limit"`    // K 線根數，預設 300
+	Inputs   map[string]float64 `json:"inputs"`   // 覆寫 input() 預設值
+}
+
+// ApplyDefaults 套用預設值
+func (r *RunScriptRequest) ApplyDefaults() {
+	if r.Interval == "" {
+		r.Interval = "4h"
+	}
+	if r.Limit <= 0 {
+		r.Limit = 300
+	}
+}
+
+// ScriptRunResult 腳本試跑結果（只返回最新一根的值）
+type ScriptRunResult struct {
+	Symbol   string              `json:"symbol"`
+	Interval string              `json:"interval"`
+	Bars     int                 `json:"bars"`
+	Plots    map[string]*float64 `json:"plots"` // 暖機期不足時為 null
+	Alerts   []ScriptAlertState  `json:"alerts"`
+	Steps    int64               `json:"steps"` // 使用的運算步數
+}
+
+// ScriptAlertState alertcondition 的最新狀態
+type ScriptAlertState struct {
+	Title       string     `json:"title"`
+	Message     string     `json:"message"`
+	Active      bool       `json:"active"`                // 最新一根是否成立
+	LastFiredAt *time.Time `json:"lastFiredAt,omitempty"` // 最近一次成立的 K 線開盤時間
+}
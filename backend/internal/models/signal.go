@@ -0,0 +1,59 @@
+package models
+
+import "math"
+
+// LRC 突破方向
+const (
+	SignalAboveUpper = "above_upper" // 突破上軌
+	SignalBelowLower = "below_lower" // 跌破下軌
+)
+
+// TriggerDirection 依訂閱的觸發模式判斷是否突破，返回突破方向
+func (s *IndicatorSubscription) TriggerDirection(result *IndicatorResult) (string, bool) {
+	if !s.IsCloseConfirmed() {
+		switch {
+		case result.IsAboveUpper:
+			return SignalAboveUpper, true
+		case result.IsBelowLower:
+			return SignalBelowLower, true
+		}
+		return "", false
+	}
+
+	required := s.RequiredCloses()
+	switch {
+	case result.ClosesAboveUpper >= required:
+		return SignalAboveUpper, true
+	case result.ClosesBelowLower >= required:
+		return SignalBelowLower, true
+	}
+	return "", false
+}
+
+// PassesChannelFilter 檢查通道品質（|R| 與斜率方向）是否符合訂閱要求
+func (s *IndicatorSubscription) PassesChannelFilter(result *IndicatorResult, direction string) bool {
+	if s.MinPearsonR > 0 && math.Abs(result.PearsonR) < s.MinPearsonR {
+		return false
+	}
+
+	switch s.SlopeFilter {
+	case SlopeFilterAligned:
+		if direction == SignalAboveUpper {
+			return result.Slope > 0
+		}
+		return result.Slope < 0
+	case SlopeFilterUp:
+		return result.Slope > 0
+	case SlopeFilterDown:
+		return result.Slope < 0
+	}
+	return true
+}
+
+// PassesVolatilityFilter 檢查突破前是否處於波動收斂（未要求時一律通過）
+func (s *IndicatorSubscription) PassesVolatilityFilter(result *IndicatorResult) bool {
+	if !s.RequireCompressedBreakout {
+		return true
+	}
+	return result.Volatility != nil && result.Volatility.CompressedWithin(s.CompressedWithinBars)
+}
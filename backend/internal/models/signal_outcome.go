@@ -0,0 +1,111 @@
+package models
+
+import "time"
+
+// SignalHorizon 追蹤訊號結果的時間點
+type SignalHorizon struct {
+	Label    string
+	Duration time.Duration
+}
+
+// SignalHorizons 訊號發出後記錄價格的時間點，最後一個也是最大順向/逆向波動的追蹤期間
+var SignalHorizons = []SignalHorizon{
+	{Label: "15m", Duration: 15 * time.Minute},
+	{Label: "1h", Duration: time.Hour},
+	{Label: "4h", Duration: 4 * time.Hour},
+	{Label: "24h", Duration: 24 * time.Hour},
+}
+
+// 訊號方向
+const (
+	SignalSideLong    = "long"    // 預期上漲：突破上軌、看漲背離與型態等
+	SignalSideShort   = "short"   // 預期下跌：跌破下軌、看跌背離與型態等
+	SignalSideNeutral = "neutral" // 條件式、腳本等沒有固定方向，報酬以價格漲跌記錄，不列入成績單統計
+)
+
+// SignalOutcome 訊號在某個時間點的結果
+type SignalOutcome struct {
+	Horizon   string   `json:"horizon"`             // "15m"、"1h"、"4h" 或 "24h"
+	Price     *float64 `json:"price,omitempty"`     // 該時間點的價格，尚未到期為空
+	ReturnPct *float64 `json:"returnPct,omitempty"` // 依訊號方向調整的報酬率（%）
+}
+
+// SignalRecord 一次已發出的通知與其後續表現
+type SignalRecord struct {
+	SignalID       string    `json:"signalId"`
+	SubscriptionID string    `json:"subscriptionId"`
+	UserID         string    `json:"userId"`
+	Symbol         string    `json:"symbol"`
+	AlertType      string    `json:"alertType"` // 與通知相同："above_upper"、"below_lower"、"condition" 等
+	Side           string    `json:"side"`      // "long"、"short" 或 "neutral"
+	Price          float64   `json:"price"`
+	UpperBand      float64   `json:"upperBand"`
+	LowerBand      float64   `json:"lowerBand"`
+	VolumeRatio    float64   `json:"volumeRatio"`
+	CreatedAt      time.Time `json:"createdAt"`
+
+	Outcomes        []SignalOutcome `json:"outcomes"`
+	MaxFavorablePct float64         `json:"maxFavorablePct"` // 追蹤期間內最大順向波動（%，不小於 0）
+	MaxAdversePct   float64         `json:"maxAdversePct"`   // 追蹤期間內最大逆向波動（%，不小於 0）
+	TrackedUntil    time.Time       `json:"trackedUntil"`    // 最大順向/逆向波動已計算到的時間
+	Completed       bool            `json:"completed"`       // 所有時間點都已記錄
+}
+
+// SignalSide 通知類型對應的訊號方向
+func SignalSide(alertType string) string {
+	switch alertType {
+	case SignalAboveUpper:
+		return SignalSideLong
+	case SignalBelowLower:
+		return SignalSideShort
+	default:
+		return SignalSideNeutral
+	}
+}
+
+// CommonSignalSide 多個同時成立的訊號共同的方向，方向不一致或沒有方向時為 neutral
+func CommonSignalSide(sides []string) string {
+	if len(sides) == 0 {
+		return SignalSideNeutral
+	}
+	for _, side := range sides[1:] {
+		if side != sides[0] {
+			return SignalSideNeutral
+		}
+	}
+	return sides[0]
+}
+
+// IsDirectional 訊號是否有明確方向
+func (s *SignalRecord) IsDirectional() bool {
+	return s.Side == SignalSideLong || s.Side == SignalSideShort
+}
+
+// Direction 順向報酬的正負號，做空為 -1，沒有方向時以價格漲跌記錄
+func (s *SignalRecord) Direction() float64 {
+	if s.Side == SignalSideShort {
+		return -1
+	}
+	return 1
+}
+
+// HorizonScore 單一時間點的統計
+type HorizonScore struct {
+	Horizon      string  `json:"horizon"`
+	Signals      int     `json:"signals"`      // 已到期的訊號數
+	HitRate      float64 `json:"hitRate"`      // 順向報酬為正的比例（0~1）
+	AvgReturnPct float64 `json:"avgReturnPct"` // 平均順向報酬率（%）
+}
+
+// SignalScorecard 訂閱或幣種的訊號成績單
+type SignalScorecard struct {
+	SubscriptionID     string         `json:"subscriptionId,omitempty"`
+	Symbol             string         `json:"symbol,omitempty"`
+	Signals            int            `json:"signals"`   // 統計的訊號數
+	Neutral            int            `json:"neutral"`   // 沒有方向的訊號數，不列入勝率與波動統計
+	Completed          int            `json:"completed"` // 已追蹤完成的訊號數
+	Horizons           []HorizonScore `json:"horizons"`
+	AvgMaxFavorablePct float64        `json:"avgMaxFavorablePct"` // 已完成訊號的平均最大順向波動（%）
+	AvgMaxAdversePct   float64        `json:"avgMaxAdversePct"`   // 已完成訊號的平均最大逆向波動（%）
+	Recent             []SignalRecord `json:"recent"`             // 最近的訊號
+}
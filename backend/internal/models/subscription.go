@@ -2,12 +2,96 @@ package models
 
 import "time"
 
+// 觸發模式
+const (
+	TriggerModeIntrabar    = "intrabar"    // 盤中即時價格突破即觸發（原行為）
+	TriggerModeClose       = "close"       // LRC 週期 K 線收盤突破才觸發
+	TriggerModeConsecutive = "consecutive" // 連續 N 根 K 線收盤突破才觸發
+)
+
+// 斜率方向過濾
+const (
+	SlopeFilterNone    = ""        // 不過濾
+	SlopeFilterAligned = "aligned" // 突破方向需與趨勢一致（上升趨勢只通知突破上軌，下降趨勢只通知跌破下軌）
+	SlopeFilterUp      = "up"      // 只在上升趨勢通知
+	SlopeFilterDown    = "down"    // 只在下降趨勢通知
+)
+
+// MaxConfirmCloses consecutive 模式允許的最大連續收盤根數
+const MaxConfirmCloses = 10
+
+// 多週期共振條件
+const (
+	ConfluenceSlopeUp       = "slope_up"       // 該週期 LRC 斜率為正
+	ConfluenceSlopeDown     = "slope_down"     // 該週期 LRC 斜率為負
+	ConfluenceSlopeAligned  = "slope_aligned"  // 斜率方向與突破方向一致
+	ConfluenceAboveCenter   = "above_center"   // 該週期最新收盤在中線之上
+	ConfluenceBelowCenter   = "below_center"   // 該週期最新收盤在中線之下
+	ConfluenceCenterAligned = "center_aligned" // 收盤相對中線的位置與突破方向一致
+)
+
+// 技術指標事件（一目均衡表、拋物線轉向）
+const (
+	StudyEventCloudBreakUp   = "cloud_break_up"   // 收盤突破雲上緣
+	StudyEventCloudBreakDown = "cloud_break_down" // 收盤跌破雲下緣
+	StudyEventTKCrossBull    = "tk_cross_bull"    // 轉換線上穿基準線
+	StudyEventTKCrossBear    = "tk_cross_bear"    // 轉換線下穿基準線
+	StudyEventSARFlipUp      = "sar_flip_up"      // SAR 翻轉為上升趨勢
+	StudyEventSARFlipDown    = "sar_flip_down"    // SAR 翻轉為下降趨勢
+)
+
+// IsIchimokuEvent 是否為一目均衡表事件
+func IsIchimokuEvent(event string) bool {
+	switch event {
+	case StudyEventCloudBreakUp, StudyEventCloudBreakDown, StudyEventTKCrossBull, StudyEventTKCrossBear:
+		return true
+	}
+	return false
+}
+
+// 背離類型
+const (
+	DivergenceRegularBullish = "regular_bullish" // 常規看漲背離：價格更低的低點，指標更高的低點
+	DivergenceRegularBearish = "regular_bearish" // 常規看跌背離：價格更高的高點，指標更低的高點
+	DivergenceHiddenBullish  = "hidden_bullish"  // 隱藏看漲背離：價格更高的低點，指標更低的低點
+	DivergenceHiddenBearish  = "hidden_bearish"  // 隱藏看跌背離：價格更低的高點，指標更高的高點
+)
+
+// 背離使用的震盪指標
+const (
+	DivergenceOscillatorRSI  = "rsi"  // RSI(14)
+	DivergenceOscillatorMACD = "macd" // MACD(12, 26, 9) 柱狀圖
+)
+
+// MaxPivotLookback 轉折點左右確認根數的上限
+const MaxPivotLookback = 20
+
+// MaxCompressedWithinBars 波動收斂突破往回檢查的最大根數
+const MaxCompressedWithinBars = 20
+
+// MaxPatternWickRatio 錘子線／射擊之星長影線比例的上限
+const MaxPatternWickRatio = 10.0
+
+// MaxConfluenceRules 每個訂閱最多的共振條件數量
+const MaxConfluenceRules = 5
+
+// ConfluenceRule 多週期共振條件：主訊號觸發時，指定週期的 LRC 也必須符合條件
+type ConfluenceRule struct {
+	Interval  string `json:"interval"`  // K 線週期，例如 "1h"、"1d"
+	Condition string `json:"condition"` // "slope_up"、"slope_down"、"slope_aligned"、"above_center"、"below_center" 或 "center_aligned"
+}
+
+// IsAligned 是否依突破方向判斷
+func (r ConfluenceRule) IsAligned() bool {
+	return r.Condition == ConfluenceSlopeAligned || r.Condition == ConfluenceCenterAligned
+}
+
 // IndicatorSubscription 用戶對特定幣種的指標監控訂閱
 type IndicatorSubscription struct {
-	SubscriptionID string    `json:"subscriptionId"`
-	UserID         string    `json:"userId"`
-	Symbol         string    `json:"symbol"`  // BTC, ETH, etc.
-	Enabled        bool      `json:"enabled"` // 主開關
+	SubscriptionID string `json:"subscriptionId"`
+	UserID         string `json:"userId"`
+	Symbol         string `json:"symbol"`  // BTC, ETH, etc.
+	Enabled        bool   `json:"enabled"` // 主開關
 
 	// Telegram 通知設定
 	TelegramChatID string `json:"telegramChatId"` // Telegram Chat ID
@@ -16,11 +100,71 @@ type IndicatorSubscription struct {
 	NotifyIntervalMin int `json:"notifyIntervalMin"` // 通知間隔（分鐘），預設 60
 
 	// 成交量判斷設定
-	EnableVolumeCheck bool    `json:"enableVolumeCheck"` // 成交量開關
-	VolumeCheckMode   string  `json:"volumeCheckMode"`   // "fixed" 或 "multiplier"
-	VolumeFixedValue  float64 `json:"volumeFixedValue"`  // 固定值模式：閾值
-	VolumeMultiplier  float64 `json:"volumeMultiplier"`  // 倍數模式：N 倍
-	VolumeAvgPeriod   int     `json:"volumeAvgPeriod"`   // 均量計算週期（幾根 K 線）
+	EnableVolumeCheck    bool    `json:"enableVolumeCheck"`    // 成交量開關
+	VolumeCheckMode      string  `json:"volumeCheckMode"`      // "fixed"、"multiplier"、"zscore"、"mad"、"notional"、"taker_buy" 或 "taker_sell"
+	VolumeFixedValue     float64 `json:"volumeFixedValue"`     // 固定值模式：閾值（notional 模式為 USDT 成交額）
+	VolumeMultiplier     float64 `json:"volumeMultiplier"`     // 倍數模式：N 倍
+	VolumeAvgPeriod      int     `json:"volumeAvgPeriod"`      // 均量計算週期（幾根 K 線）
+	VolumeScoreThreshold float64 `json:"volumeScoreThreshold"` // zscore / mad 模式：分數閾值
+	VolumeTakerRatio     float64 `json:"volumeTakerRatio"`     // taker_buy / taker_sell 模式：主動成交佔比閾值（0.5~1）
+
+	// 觸發模式設定
+	TriggerMode   string `json:"triggerMode"`   // "intrabar"、"close" 或 "consecutive"
+	ConfirmCloses int    `json:"confirmCloses"` // consecutive 模式：需連續收盤突破的 K 線根數
+
+	// 通道品質過濾
+	MinPearsonR float64 `json:"minPearsonR"` // 最小 |R|，0 表示不過濾
+	SlopeFilter string  `json:"slopeFilter"` // 斜率方向過濾："", "aligned", "up", "down"
+
+	// 波動收斂突破：LRC 突破前最近 N 根已收盤 K 線內須出現過收斂狀態（波動率百分位 ≤ 20）
+	RequireCompressedBreakout bool `json:"requireCompressedBreakout"`
+	CompressedWithinBars      int  `json:"compressedWithinBars"` // 預設 3
+
+	// 自訂條件式：設定後以條件式取代 LRC 突破判斷，例如
+	// close > lrc(42,2,"4h").upper and rsi(14,"1h") < 70
+	Condition string `json:"condition"`
+
+	// Pine Script 腳本觸發：設定後以腳本的 alertcondition 取代 LRC 突破判斷
+	ScriptID       string             `json:"scriptId"`
+	ScriptInterval string             `json:"scriptInterval"` // 腳本執行的 K 線週期，預設 "4h"
+	ScriptAlert    string             `json:"scriptAlert"`    // 使用的 alertcondition 標題，留空則使用第一個
+	ScriptInputs   map[string]float64 `json:"scriptInputs"`   // 覆寫腳本 input() 預設值
+
+	// 技術指標事件：設定後以事件取代 LRC 突破判斷，任一事件發生即通知
+	StudyEvents   []string `json:"studyEvents"`   // 例如 "cloud_break_up"、"tk_cross_bull"、"sar_flip_up"
+	StudyInterval string   `json:"studyInterval"` // 計算事件的 K 線週期，預設 "4h"
+
+	// 背離偵測：設定後以價格與震盪指標的背離取代 LRC 突破判斷
+	DivergenceTypes         []string `json:"divergenceTypes"`         // "regular_bullish"、"regular_bearish"、"hidden_bullish"、"hidden_bearish"
+	DivergenceOscillator    string   `json:"divergenceOscillator"`    // "rsi" 或 "macd"，預設 "rsi"
+	DivergencePivotLookback int      `json:"divergencePivotLookback"` // 轉折點左右確認根數，預設 5
+	DivergenceInterval      string   `json:"divergenceInterval"`      // 偵測背離的 K 線週期，預設 "4h"
+	LastDivergencePivotAt   int64    `json:"lastDivergencePivotAt"`   // 最近一次通知的背離轉折點 K 線開盤時間（毫秒），同一轉折點不重複通知
+
+	// K 線型態：PatternAlerts 設定後以型態取代 LRC 突破判斷；PatternFilter 則作為 LRC 突破的過濾條件
+	PatternAlerts   []string `json:"patternAlerts"`   // 例如 "bullish_engulfing"、"hammer"、"morning_star"
+	PatternInterval string   `json:"patternInterval"` // PatternAlerts 判斷型態的 K 線週期，預設 "4h"
+	PatternFilter   []string `json:"patternFilter"`   // LRC 突破時，最近一根收盤 K 線須符合其中一種型態
+
+	// 最近一次通知的型態 K 線開盤時間（毫秒），同一根 K 線不重複通知
+	LastPatternCandleAt int64 `json:"lastPatternCandleAt"`
+
+	// K 線型態判斷比例，0 表示使用預設值
+	PatternDojiBodyRatio     float64 `json:"patternDojiBodyRatio"`     // 十字線：實體 / 振幅上限，預設 0.1
+	PatternHammerWickRatio   float64 `json:"patternHammerWickRatio"`   // 錘子線／射擊之星：長影線 / 實體下限，預設 2.0
+	PatternHammerShadowRatio float64 `json:"patternHammerShadowRatio"` // 錘子線／射擊之星：反方向影線 / 振幅上限，預設 0.1
+	PatternStarBodyRatio     float64 `json:"patternStarBodyRatio"`     // 晨星／夜星：中間 K 線實體 / 第一根實體上限，預設 0.3
+	PatternLongBodyRatio     float64 `json:"patternLongBodyRatio"`     // 晨星／夜星：第一根與第三根實體 / 振幅下限，預設 0.5
+
+	// 多週期共振：所有條件都成立才通知
+	Confluence []ConfluenceRule `json:"confluence"`
+
+	// BTC 聯動抑制：幣種只是跟隨 BTC 波動時不通知，相關矩陣以觸發判斷的 K 線週期計算
+	SuppressBTCDriven bool    `json:"suppressBtcDriven"`
+	BTCCorrelationMin float64 `json:"btcCorrelationMin"` // 視為跟隨 BTC 的最小相關係數，預設 0.8
+
+	// 模擬交易：每次通知開立模擬部位，以 Redis 中的最新價格追蹤停損停利
+	PaperTrading PaperTradingConfig `json:"paperTrading"`
 
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
@@ -28,27 +172,90 @@ type IndicatorSubscription struct {
 
 // CreateSubscriptionRequest 創建訂閱請求
 type CreateSubscriptionRequest struct {
-	UserID            string  `json:"userId" binding:"required"`
-	Symbol            string  `json:"symbol" binding:"required"`
-	TelegramChatID    string  `json:"telegramChatId" binding:"required"` // Telegram Chat ID
-	NotifyIntervalMin int     `json:"notifyIntervalMin"`                 // 預設 60
-	EnableVolumeCheck bool    `json:"enableVolumeCheck"`
-	VolumeCheckMode   string  `json:"volumeCheckMode"`  // "fixed" 或 "multiplier"
-	VolumeFixedValue  float64 `json:"volumeFixedValue"` // 固定值模式用
-	VolumeMultiplier  float64 `json:"volumeMultiplier"` // 倍數模式用
-	VolumeAvgPeriod   int     `json:"volumeAvgPeriod"`  // 預設 20
+	UserID                    string             `json:"userId" binding:"required"`
+	Symbol                    string             `json:"symbol" binding:"required"`
+	TelegramChatID            string             `json:"telegramChatId" binding:"required"` // Telegram Chat ID
+	NotifyIntervalMin         int                `json:"notifyIntervalMin"`                 // 預設 60
+	EnableVolumeCheck         bool               `json:"enableVolumeCheck"`
+	VolumeCheckMode           string             `json:"volumeCheckMode"`           // "fixed"、"multiplier"、"zscore"、"mad"、"notional"、"taker_buy" 或 "taker_sell"
+	VolumeFixedValue          float64            `json:"volumeFixedValue"`          // 固定值模式用
+	VolumeMultiplier          float64            `json:"volumeMultiplier"`          // 倍數模式用
+	VolumeAvgPeriod           int                `json:"volumeAvgPeriod"`           // 預設 20
+	VolumeScoreThreshold      float64            `json:"volumeScoreThreshold"`      // zscore / mad 模式用，預設 3.0
+	VolumeTakerRatio          float64            `json:"volumeTakerRatio"`          // taker_buy / taker_sell 模式用，預設 0.7
+	TriggerMode               string             `json:"triggerMode"`               // 預設 "intrabar"
+	ConfirmCloses             int                `json:"confirmCloses"`             // consecutive 模式用，預設 2
+	MinPearsonR               float64            `json:"minPearsonR"`               // 最小 |R|，0 表示不過濾
+	SlopeFilter               string             `json:"slopeFilter"`               // "", "aligned", "up", "down"
+	Condition                 string             `json:"condition"`                 // 自訂條件式，留空則使用 LRC 突破
+	ScriptID                  string             `json:"scriptId"`                  // Pine Script 腳本 ID
+	ScriptInterval            string             `json:"scriptInterval"`            // 預設 "4h"
+	ScriptAlert               string             `json:"scriptAlert"`               // alertcondition 標題，留空則使用第一個
+	ScriptInputs              map[string]float64 `json:"scriptInputs"`              // 覆寫腳本 input() 預設值
+	StudyEvents               []string           `json:"studyEvents"`               // 一目均衡表／SAR 事件
+	StudyInterval             string             `json:"studyInterval"`             // 預設 "4h"
+	DivergenceTypes           []string           `json:"divergenceTypes"`           // 背離類型
+	DivergenceOscillator      string             `json:"divergenceOscillator"`      // 預設 "rsi"
+	DivergencePivotLookback   int                `json:"divergencePivotLookback"`   // 預設 5
+	DivergenceInterval        string             `json:"divergenceInterval"`        // 預設 "4h"
+	PatternAlerts             []string           `json:"patternAlerts"`             // K 線型態觸發
+	PatternInterval           string             `json:"patternInterval"`           // 預設 "4h"
+	PatternFilter             []string           `json:"patternFilter"`             // LRC 突破的 K 線型態過濾
+	PatternDojiBodyRatio      float64            `json:"patternDojiBodyRatio"`      // 0 表示預設 0.1
+	PatternHammerWickRatio    float64            `json:"patternHammerWickRatio"`    // 0 表示預設 2.0
+	PatternHammerShadowRatio  float64            `json:"patternHammerShadowRatio"`  // 0 表示預設 0.1
+	PatternStarBodyRatio      float64            `json:"patternStarBodyRatio"`      // 0 表示預設 0.3
+	PatternLongBodyRatio      float64            `json:"patternLongBodyRatio"`      // 0 表示預設 0.5
+	Confluence                []ConfluenceRule   `json:"confluence"`                // 多週期共振條件
+	SuppressBTCDriven         bool               `json:"suppressBtcDriven"`         // 跟隨 BTC 波動時不通知
+	BTCCorrelationMin         float64            `json:"btcCorrelationMin"`         // 預設 0.8
+	RequireCompressedBreakout bool               `json:"requireCompressedBreakout"` // 只通知波動收斂後的 LRC 突破
+	CompressedWithinBars      int                `json:"compressedWithinBars"`      // 預設 3
+	PaperTrading              PaperTradingConfig `json:"paperTrading"`              // 模擬交易設定
 }
 
 // UpdateSubscriptionRequest 更新訂閱請求
+// 陣列與 map 欄位提供時整組取代，空陣列表示清除
 type UpdateSubscriptionRequest struct {
-	Enabled           *bool    `json:"enabled"`
-	TelegramChatID    *string  `json:"telegramChatId"`
-	NotifyIntervalMin *int     `json:"notifyIntervalMin"`
-	EnableVolumeCheck *bool    `json:"enableVolumeCheck"`
-	VolumeCheckMode   *string  `json:"volumeCheckMode"`
-	VolumeFixedValue  *float64 `json:"volumeFixedValue"`
-	VolumeMultiplier  *float64 `json:"volumeMultiplier"`
-	VolumeAvgPeriod   *int     `json:"volumeAvgPeriod"`
+	Enabled                   *bool               `json:"enabled"`
+	TelegramChatID            *string             `json:"telegramChatId"`
+	NotifyIntervalMin         *int                `json:"notifyIntervalMin"`
+	EnableVolumeCheck         *bool               `json:"enableVolumeCheck"`
+	VolumeCheckMode           *string             `json:"volumeCheckMode"`
+	VolumeFixedValue          *float64            `json:"volumeFixedValue"`
+	VolumeMultiplier          *float64            `json:"volumeMultiplier"`
+	VolumeAvgPeriod           *int                `json:"volumeAvgPeriod"`
+	VolumeScoreThreshold      *float64            `json:"volumeScoreThreshold"`
+	VolumeTakerRatio          *float64            `json:"volumeTakerRatio"`
+	TriggerMode               *string             `json:"triggerMode"`
+	ConfirmCloses             *int                `json:"confirmCloses"`
+	MinPearsonR               *float64            `json:"minPearsonR"`
+	SlopeFilter               *string             `json:"slopeFilter"`
+	Condition                 *string             `json:"condition"`
+	ScriptID                  *string             `json:"scriptId"`
+	ScriptInterval            *string             `json:"scriptInterval"`
+	ScriptAlert               *string             `json:"scriptAlert"`
+	ScriptInputs              map[string]float64  `json:"scriptInputs"`
+	StudyEvents               *[]string           `json:"studyEvents"`
+	StudyInterval             *string             `json:"studyInterval"`
+	DivergenceTypes           *[]string           `json:"divergenceTypes"`
+	DivergenceOscillator      *string             `json:"divergenceOscillator"`
+	DivergencePivotLookback   *int                `json:"divergencePivotLookback"`
+	DivergenceInterval        *string             `json:"divergenceInterval"`
+	PatternAlerts             *[]string           `json:"patternAlerts"`
+	PatternInterval           *string             `json:"patternInterval"`
+	PatternFilter             *[]string           `json:"patternFilter"`
+	PatternDojiBodyRatio      *float64            `json:"patternDojiBodyRatio"`
+	PatternHammerWickRatio    *float64            `json:"patternHammerWickRatio"`
+	PatternHammerShadowRatio  *float64            `json:"patternHammerShadowRatio"`
+	PatternStarBodyRatio      *float64            `json:"patternStarBodyRatio"`
+	PatternLongBodyRatio      *float64            `json:"patternLongBodyRatio"`
+	Confluence                *[]ConfluenceRule   `json:"confluence"`
+	SuppressBTCDriven         *bool               `json:"suppressBtcDriven"`
+	BTCCorrelationMin         *float64            `json:"btcCorrelationMin"`
+	RequireCompressedBreakout *bool               `json:"requireCompressedBreakout"`
+	CompressedWithinBars      *int                `json:"compressedWithinBars"`
+	PaperTrading              *PaperTradingConfig `json:"paperTrading"`
 }
 
 // ApplyDefaults 套用預設值
@@ -65,5 +272,208 @@ func (r *CreateSubscriptionRequest) ApplyDefaults() {
 	if r.VolumeAvgPeriod <= 0 {
 		r.VolumeAvgPeriod = 20
 	}
+	if r.VolumeScoreThreshold <= 0 {
+		r.VolumeScoreThreshold = 3.0 // 預設 3 個標準差
+	}
+	if r.VolumeTakerRatio <= 0 {
+		r.VolumeTakerRatio = 0.7 // 預設 70% 為主動成交
+	}
+	if r.TriggerMode == "" {
+		r.TriggerMode = TriggerModeIntrabar
+	}
+	if r.TriggerMode == TriggerModeConsecutive && r.ConfirmCloses <= 0 {
+		r.ConfirmCloses = 2
+	}
+	if r.ScriptID != "" && r.ScriptInterval == "" {
+		r.ScriptInterval = "4h"
+	}
+	if len(r.StudyEvents) > 0 && r.StudyInterval == "" {
+		r.StudyInterval = "4h"
+	}
+	if len(r.DivergenceTypes) > 0 {
+		if r.DivergenceOscillator == "" {
+			r.DivergenceOscillator = DivergenceOscillatorRSI
+		}
+		if r.DivergencePivotLookback <= 0 {
+			r.DivergencePivotLookback = 5
+		}
+		if r.DivergenceInterval == "" {
+			r.DivergenceInterval = "4h"
+		}
+	}
+	if len(r.PatternAlerts) > 0 && r.PatternInterval == "" {
+		r.PatternInterval = "4h"
+	}
+	if r.RequireCompressedBreakout && r.CompressedWithinBars <= 0 {
+		r.CompressedWithinBars = 3
+	}
+	if r.SuppressBTCDriven && r.BTCCorrelationMin <= 0 {
+		r.BTCCorrelationMin = DefaultBTCCorrelationMin
+	}
+	r.PaperTrading.ApplyDefaults()
 }
 
+// Validate 驗證訂閱設定
+// 條件式編譯、K 線週期、型態名稱與成交量檢查模式需要引擎套件，由 service 層另外檢查
+func (s *IndicatorSubscription) Validate() error {
+	switch s.TriggerMode {
+	case "", TriggerModeIntrabar, TriggerModeClose:
+	case TriggerModeConsecutive:
+		if s.ConfirmCloses < 1 || s.ConfirmCloses > MaxConfirmCloses {
+			return invalid("confirmCloses", "必須介於 1 到 %d 之間", MaxConfirmCloses)
+		}
+	default:
+		return invalid("triggerMode", "不支援的觸發模式 %q", s.TriggerMode)
+	}
+
+	if s.MinPearsonR < 0 || s.MinPearsonR > 1 {
+		return invalid("minPearsonR", "必須介於 0 到 1 之間")
+	}
+
+	switch s.SlopeFilter {
+	case SlopeFilterNone, SlopeFilterAligned, SlopeFilterUp, SlopeFilterDown:
+	default:
+		return invalid("slopeFilter", "不支援的斜率過濾 %q", s.SlopeFilter)
+	}
+
+	if s.customTriggerCount() > 1 {
+		return invalid("condition", "condition、scriptId、studyEvents、divergenceTypes 與 patternAlerts 只能擇一設定")
+	}
+
+	if len(s.StudyEvents) > 0 {
+		for _, event := range s.StudyEvents {
+			switch event {
+			case StudyEventCloudBreakUp, StudyEventCloudBreakDown, StudyEventTKCrossBull, StudyEventTKCrossBear,
+				StudyEventSARFlipUp, StudyEventSARFlipDown:
+			default:
+				return invalid("studyEvents", "不支援的事件 %q", event)
+			}
+		}
+	}
+
+	if len(s.DivergenceTypes) > 0 {
+		for _, t := range s.DivergenceTypes {
+			switch t {
+			case DivergenceRegularBullish, DivergenceRegularBearish, DivergenceHiddenBullish, DivergenceHiddenBearish:
+			default:
+				return invalid("divergenceTypes", "不支援的背離類型 %q", t)
+			}
+		}
+		switch s.DivergenceOscillator {
+		case DivergenceOscillatorRSI, DivergenceOscillatorMACD:
+		default:
+			return invalid("divergenceOscillator", "不支援的震盪指標 %q", s.DivergenceOscillator)
+		}
+		if s.DivergencePivotLookback < 1 || s.DivergencePivotLookback > MaxPivotLookback {
+			return invalid("divergencePivotLookback", "必須介於 1 到 %d 之間", MaxPivotLookback)
+		}
+	}
+
+	if len(s.PatternFilter) > 0 && s.customTriggerCount() > 0 {
+		return invalid("patternFilter", "只能用於 LRC 突破訂閱")
+	}
+	for _, ratio := range []struct {
+		field string
+		value float64
+	}{
+		{"patternDojiBodyRatio", s.PatternDojiBodyRatio},
+		{"patternHammerShadowRatio", s.PatternHammerShadowRatio},
+		{"patternStarBodyRatio", s.PatternStarBodyRatio},
+		{"patternLongBodyRatio", s.PatternLongBodyRatio},
+	} {
+		if ratio.value < 0 || ratio.value > 1 {
+			return invalid(ratio.field, "必須介於 0 到 1 之間")
+		}
+	}
+	if s.PatternHammerWickRatio < 0 || s.PatternHammerWickRatio > MaxPatternWickRatio {
+		return invalid("patternHammerWickRatio", "必須介於 0 到 %g 之間", MaxPatternWickRatio)
+	}
+
+	if len(s.Confluence) > MaxConfluenceRules {
+		return invalid("confluence", "最多 %d 個共振條件", MaxConfluenceRules)
+	}
+	for i, rule := range s.Confluence {
+		switch rule.Condition {
+		case ConfluenceSlopeUp, ConfluenceSlopeDown, ConfluenceAboveCenter, ConfluenceBelowCenter,
+			ConfluenceSlopeAligned, ConfluenceCenterAligned:
+		default:
+			return invalid("confluence", "第 %d 個條件不支援 %q", i+1, rule.Condition)
+		}
+		if rule.IsAligned() && s.customTriggerCount() > 0 {
+			return invalid("confluence", "%s 需要 LRC 突破方向，只能用於 LRC 突破訂閱", rule.Condition)
+		}
+	}
+
+	if s.RequireCompressedBreakout {
+		if s.customTriggerCount() > 0 {
+			return invalid("requireCompressedBreakout", "只能用於 LRC 突破訂閱")
+		}
+		if s.CompressedWithinBars < 1 || s.CompressedWithinBars > MaxCompressedWithinBars {
+			return invalid("compressedWithinBars", "必須介於 1 到 %d 之間", MaxCompressedWithinBars)
+		}
+	}
+
+	if s.SuppressBTCDriven {
+		if s.Symbol == BenchmarkSymbol {
+			return invalid("suppressBtcDriven", "不適用於 %s 訂閱", BenchmarkSymbol)
+		}
+		if s.BTCCorrelationMin <= 0 || s.BTCCorrelationMin > 1 {
+			return invalid("btcCorrelationMin", "必須介於 0 到 1 之間")
+		}
+	}
+
+	if err := s.PaperTrading.Validate(); err != nil {
+		return err
+	}
+	if s.PaperTrading.Enabled {
+		if s.Condition != "" || s.ScriptID != "" {
+			return invalid("paperTrading.enabled", "條件式與腳本訂閱沒有固定方向，無法模擬交易")
+		}
+		if s.PaperTrading.StopMode == PaperStopBand && s.customTriggerCount() > 0 {
+			return invalid("paperTrading.stopMode", "band 停損以 LRC 通道為停損價，只能用於 LRC 突破訂閱，請改用 atr 或 percent")
+		}
+	}
+	return nil
+}
+
+// TriggerInterval 觸發判斷使用的 K 線週期，LRC 突破與條件式使用 lrcInterval
+func (s *IndicatorSubscription) TriggerInterval(lrcInterval string) string {
+	switch {
+	case s.ScriptID != "":
+		return s.ScriptInterval
+	case len(s.StudyEvents) > 0:
+		return s.StudyInterval
+	case len(s.DivergenceTypes) > 0:
+		return s.DivergenceInterval
+	case len(s.PatternAlerts) > 0:
+		return s.PatternInterval
+	default:
+		return lrcInterval
+	}
+}
+
+// customTriggerCount 取代 LRC 突破判斷的自訂觸發數量
+func (s *IndicatorSubscription) customTriggerCount() int {
+	count := 0
+	for _, set := range []bool{
+		s.Condition != "", s.ScriptID != "", len(s.StudyEvents) > 0, len(s.DivergenceTypes) > 0, len(s.PatternAlerts) > 0,
+	} {
+		if set {
+			count++
+		}
+	}
+	return count
+}
+
+// IsCloseConfirmed 是否為收盤確認類的觸發模式
+func (s *IndicatorSubscription) IsCloseConfirmed() bool {
+	return s.TriggerMode == TriggerModeClose || s.TriggerMode == TriggerModeConsecutive
+}
+
+// RequiredCloses 收盤確認需要的連續收盤根數
+func (s *IndicatorSubscription) RequiredCloses() int {
+	if s.TriggerMode == TriggerModeConsecutive {
+		return s.ConfirmCloses
+	}
+	return 1
+}
@@ -0,0 +1,222 @@
+package models
+
+import "time"
+
+// 參數搜尋方式
+const (
+	SweepMethodGrid   = "grid"   // 窮舉所有組合
+	SweepMethodRandom = "random" // 從所有組合中隨機抽樣
+)
+
+// 參數搜尋的排序目標
+const (
+	SweepObjectiveExpectancy     = "expectancy"       // 前瞻期間的平均報酬率（%）
+	SweepObjectivePrecision      = "precision"        // 前瞻期間報酬為正的比例
+	SweepObjectiveSignalsPerWeek = "signals_per_week" // 每週訊號數
+)
+
+// 參數搜尋任務狀態
+const (
+	SweepStatusPending = "pending"
+	SweepStatusRunning = "running"
+	SweepStatusDone    = "done"
+	SweepStatusFailed  = "failed"
+)
+
+// 參數搜尋限制
+const (
+	MaxSweepCombinations = 500 // 單次搜尋最多評估的組合數
+	MaxSweepFolds        = 10  // 最多的 walk-forward 分段數
+	MaxSweepListLength   = 50  // 每個參數清單最多的值數
+)
+
+// SweepRequest 參數搜尋請求：以歷史 K 線評估 LRC 參數組合
+// 各參數清單的笛卡兒積即為搜尋空間，random 方式從中抽樣 Samples 組
+// 前瞻期間以時間表示，不同週期的組合才能以相同期間的報酬排名
+type SweepRequest struct {
+	Symbol string    `json:"symbol" binding:"required"`
+	Start  time.Time `json:"start"` // 留空則取結束時間往前 Bars 根（以最長的週期計算）
+	End    time.Time `json:"end"`   // 留空則為現在
+	Bars   int       `json:"bars"`  // 預設 1000
+
+	Method  string `json:"method"`  // "grid" 或 "random"，預設 "grid"
+	Samples int    `json:"samples"` // random 方式的抽樣組數，預設 50
+
+	LRCLengths        []int     `json:"lrcLengths"`        // 預設 [21, 42, 63, 84]
+	LRCDevMultipliers []float64 `json:"lrcDevMultipliers"` // 預設 [1.5, 2, 2.5, 3]
+	Intervals         []string  `json:"intervals"`         // 預設 ["1h", "4h"]
+	VolumeMultipliers []float64 `json:"volumeMultipliers"` // 倍數模式的成交量門檻，0 表示不檢查成交量，預設 [0]
+
+	Objective  string `json:"objective"`  // 預設 "expectancy"
+	Horizon    string `json:"horizon"`    // 計算報酬的前瞻期間，例如 "1d"，各週期換算為 K 線根數，預設 "1d"
+	Folds      int    `json:"folds"`      // walk-forward 時間分段數，預設 4
+	MinSignals int    `json:"minSignals"` // 參與排名的最少訊號數，預設 10
+
+	// 其餘訂閱設定，所有組合共用
+	TriggerMode       string  `json:"triggerMode"` // 預設 "close"
+	ConfirmCloses     int     `json:"confirmCloses"`
+	NotifyIntervalMin int     `json:"notifyIntervalMin"`
+	MinPearsonR       float64 `json:"minPearsonR"`
+	SlopeFilter       string  `json:"slopeFilter"`
+}
+
+// ApplyDefaults 套用預設值
+func (r *SweepRequest) ApplyDefaults() {
+	if r.End.IsZero() {
+		r.End = time.Now()
+	}
+	if r.Bars <= 0 {
+		r.Bars = DefaultBacktestBars
+	}
+	if r.Method == "" {
+		r.Method = SweepMethodGrid
+	}
+	if r.Method == SweepMethodRandom && r.Samples <= 0 {
+		r.Samples = 50
+	}
+	if len(r.LRCLengths) == 0 {
+		r.LRCLengths = []int{21, 42, 63, 84}
+	}
+	if len(r.LRCDevMultipliers) == 0 {
+		r.LRCDevMultipliers = []float64{1.5, 2, 2.5, 3}
+	}
+	if len(r.Intervals) == 0 {
+		r.Intervals = []string{"1h", "4h"}
+	}
+	if len(r.VolumeMultipliers) == 0 {
+		r.VolumeMultipliers = []float64{0}
+	}
+	if r.Objective == "" {
+		r.Objective = SweepObjectiveExpectancy
+	}
+	if r.Horizon == "" {
+		r.Horizon = "1d"
+	}
+	if r.Folds <= 0 {
+		r.Folds = 4
+	}
+	if r.MinSignals <= 0 {
+		r.MinSignals = 10
+	}
+	if r.TriggerMode == "" {
+		r.TriggerMode = TriggerModeClose
+	}
+}
+
+// Validate 驗證搜尋設定（K 線週期與前瞻期間由 service 層驗證，各組合的訂閱設定由回測時驗證）
+func (r *SweepRequest) Validate() error {
+	if !r.Start.IsZero() && !r.Start.Before(r.End) {
+		return invalid("start", "必須早於 end")
+	}
+	if r.Bars > MaxBacktestBars {
+		return invalid("bars", "不能超過 %d", MaxBacktestBars)
+	}
+	for _, list := range []struct {
+		field string
+		n     int
+	}{
+		{"lrcLengths", len(r.LRCLengths)},
+		{"lrcDevMultipliers", len(r.LRCDevMultipliers)},
+		{"intervals", len(r.Intervals)},
+		{"volumeMultipliers", len(r.VolumeMultipliers)},
+	} {
+		if list.n > MaxSweepListLength {
+			return invalid(list.field, "最多 %d 個值", MaxSweepListLength)
+		}
+	}
+	for _, length := range r.LRCLengths {
+		if length < 2 || length > 500 {
+			return invalid("lrcLengths", "必須介於 2 到 500 之間")
+		}
+	}
+	for _, dev := range r.LRCDevMultipliers {
+		if dev <= 0 {
+			return invalid("lrcDevMultipliers", "必須大於 0")
+		}
+	}
+	for _, m := range r.VolumeMultipliers {
+		if m < 0 {
+			return invalid("volumeMultipliers", "不能為負數")
+		}
+	}
+
+	switch r.Method {
+	case SweepMethodGrid:
+		if r.Combinations() > MaxSweepCombinations {
+			return invalid("method", "共 %d 組參數，grid 方式最多 %d 組，請減少參數或改用 random", r.Combinations(), MaxSweepCombinations)
+		}
+	case SweepMethodRandom:
+		if r.Samples > MaxSweepCombinations {
+			return invalid("samples", "不能超過 %d", MaxSweepCombinations)
+		}
+	default:
+		return invalid("method", "不支援的搜尋方式 %q", r.Method)
+	}
+
+	switch r.Objective {
+	case SweepObjectiveExpectancy, SweepObjectivePrecision, SweepObjectiveSignalsPerWeek:
+	default:
+		return invalid("objective", "不支援的目標 %q", r.Objective)
+	}
+	if r.Folds < 2 || r.Folds > MaxSweepFolds {
+		return invalid("folds", "必須介於 2 到 %d 之間", MaxSweepFolds)
+	}
+	return nil
+}
+
+// Combinations 搜尋空間的組合數
+func (r *SweepRequest) Combinations() int {
+	return len(r.LRCLengths) * len(r.LRCDevMultipliers) * len(r.Intervals) * len(r.VolumeMultipliers)
+}
+
+// SweepParams 一組 LRC 參數
+type SweepParams struct {
+	Interval         string  `json:"interval"`
+	LRCLength        int     `json:"lrcLength"`
+	LRCDevMultiplier float64 `json:"lrcDevMultiplier"`
+	VolumeMultiplier float64 `json:"volumeMultiplier"` // 0 表示不檢查成交量
+}
+
+// SweepResult 一組參數的回測統計
+type SweepResult struct {
+	Params         SweepParams `json:"params"`
+	Signals        int         `json:"signals"`
+	SignalsPerWeek float64     `json:"signalsPerWeek"`
+	HitRate        float64     `json:"hitRate"`       // 前瞻期間報酬為正的比例
+	ExpectancyPct  float64     `json:"expectancyPct"` // 前瞻期間的平均報酬率（%）
+	AvgMAEPct      float64     `json:"avgMaePct"`
+	Objective      float64     `json:"objective"`
+
+	// 過度擬合檢查：各時間分段的目標值，好的參數應在每一段都表現穩定
+	FoldObjectives []float64 `json:"foldObjectives"`
+	FoldSignals    []int     `json:"foldSignals"`
+	Eligible       bool      `json:"eligible"` // 訊號數達到 MinSignals 才參與排名
+}
+
+// WalkForwardStep walk-forward 的一步：以之前所有分段挑出最佳參數，在下一段驗證
+type WalkForwardStep struct {
+	Fold           int         `json:"fold"` // 驗證的分段（從 0 開始）
+	Params         SweepParams `json:"params"`
+	TrainObjective float64     `json:"trainObjective"`
+	TestObjective  float64     `json:"testObjective"`
+	TestSignals    int         `json:"testSignals"`
+}
+
+// SweepJob 參數搜尋任務
+type SweepJob struct {
+	ID        string       `json:"id"`
+	Status    string       `json:"status"`
+	Request   SweepRequest `json:"request"`
+	Total     int          `json:"total"`     // 要評估的組合數
+	Completed int          `json:"completed"` // 已評估的組合數
+	Error     string       `json:"error,omitempty"`
+
+	Results     []SweepResult     `json:"results"`     // 依目標值排序的前幾名
+	WalkForward []WalkForwardStep `json:"walkForward"` // 樣本外驗證
+	// 所有 walk-forward 驗證段的平均目標值，與排名第一的目標值差距越大越可能過度擬合
+	WalkForwardObjective float64 `json:"walkForwardObjective"`
+
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
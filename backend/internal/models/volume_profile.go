@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// VolumeProfileBin 單一價格區間的成交量
+type VolumeProfileBin struct {
+	Low    float64 `json:"low"`
+	High   float64 `json:"high"`
+	Volume float64 `json:"volume"`
+}
+
+// VolumeProfile 幣種在一段回看期間的成交量分佈
+type VolumeProfile struct {
+	Symbol   string  `json:"symbol"`
+	Interval string  `json:"interval"` // 使用的 K 線週期
+	Lookback int     `json:"lookback"` // 回看的 K 線根數
+	Price    float64 `json:"price"`    // 計算時的價格
+
+	POC             float64   `json:"poc"`             // 成交量最大的價格（Point of Control）
+	ValueAreaHigh   float64   `json:"valueAreaHigh"`   // 價值區上緣（涵蓋 70% 成交量）
+	ValueAreaLow    float64   `json:"valueAreaLow"`    // 價值區下緣
+	HighVolumeNodes []float64 `json:"highVolumeNodes"` // 高成交量節點
+	LowVolumeNodes  []float64 `json:"lowVolumeNodes"`  // 低成交量節點（價值區內）
+	TotalVolume     float64   `json:"totalVolume"`
+
+	Bins []VolumeProfileBin `json:"bins"`
+
+	UpdatedAt     time.Time `json:"updatedAt"`
+	NextRefreshAt time.Time `json:"nextRefreshAt"` // 下一根 K 線收盤後重新計算
+}
+
+// 成交量分佈回看範圍
+const (
+	DefaultProfileLookback = 168  // 預設 168 根（1h 週期約一週）
+	MaxProfileLookback     = 1000 // 幣安單次 K 線請求上限
+)
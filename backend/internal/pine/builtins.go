@@ -0,0 +1,252 @@
+package pine
+
+import (
+	"math"
+
+	"cryptowatch/internal/indicators"
+)
+
+// builtin 內建函數定義
+type builtin struct {
+	params     []string // 參數名稱（依位置順序，也可用具名參數傳入）
+	required   int      // 必填參數數量
+	extraNamed bool     // 是否允許未列出的具名參數（例如 plot 的 color），這些參數會被忽略
+	call       func(in *interp, c *callNode, args []value) value
+}
+
+// builtinSeries 內建序列變數
+var builtinSeries = map[string]bool{
+	"open": true, "high": true, "low": true, "close": true, "volume": true,
+	"hl2": true, "hlc3": true, "ohlc4": true, "bar_index": true,
+	"true": true, "false": true, "na": true,
+}
+
+// builtins 可用的內建函數
+var builtins map[string]*builtin
+
+func init() {
+	builtins = map[string]*builtin{
+		// 技術指標
+		"ta.sma":   lengthFunc(indicators.SMASeries),
+		"ta.ema":   lengthFunc(indicators.EMASeries),
+		"ta.rma":   lengthFunc(indicators.RMASeries),
+		"ta.rsi":   lengthFunc(indicators.RSISeries),
+		"ta.stdev": lengthFunc(indicators.StdevSeries),
+		"ta.highest": lengthFunc(func(values []float64, length int) []float64 {
+			return rollingExtreme(values, length, math.Max)
+		}),
+		"ta.lowest": lengthFunc(func(values []float64, length int) []float64 {
+			return rollingExtreme(values, length, math.Min)
+		}),
+		"ta.linreg": {
+			params:   []string{"source", "length", "offset"},
+			required: 3,
+			call: func(in *interp, c *callNode, args []value) value {
+				length := in.length(c, args[1])
+				offset := in.integer(c, args[2], "offset", 0)
+				in.tick(int64(in.n) * int64(length))
+				return in.wrap(indicators.LinRegSeries(in.series(args[0]), length, offset))
+			},
+		},
+		"ta.change": {
+			params:   []string{"source", "length"},
+			required: 1,
+			call: func(in *interp, c *callNode, args []value) value {
+				length := 1
+				if len(args) > 1 && !args[1].missing {
+					length = in.length(c, args[1])
+				}
+				src := in.series(args[0])
+				out := in.alloc()
+				for i := range out {
+					out[i] = math.NaN()
+					if i >= length {
+						out[i] = src[i] - src[i-length]
+					}
+				}
+				return value{series: out}
+			},
+		},
+		"ta.crossover": {
+			params:   []string{"source1", "source2"},
+			required: 2,
+			call: func(in *interp, c *callNode, args []value) value {
+				return in.cross(args[0], args[1], true)
+			},
+		},
+		"ta.crossunder": {
+			params:   []string{"source1", "source2"},
+			required: 2,
+			call: func(in *interp, c *callNode, args []value) value {
+				return in.cross(args[0], args[1], false)
+			},
+		},
+
+		// 數學函數
+		"math.abs":  mathFunc(math.Abs),
+		"math.sqrt": mathFunc(math.Sqrt),
+		"math.log":  mathFunc(math.Log),
+		"math.max": {
+			params:   []string{"number0", "number1"},
+			required: 2,
+			call: func(in *interp, c *callNode, args []value) value {
+				return in.elementwise(args[0], args[1], math.Max)
+			},
+		},
+		"math.min": {
+			params:   []string{"number0", "number1"},
+			required: 2,
+			call: func(in *interp, c *callNode, args []value) value {
+				return in.elementwise(args[0], args[1], math.Min)
+			},
+		},
+		"nz": {
+			params:   []string{"source", "replacement"},
+			required: 1,
+			call: func(in *interp, c *callNode, args []value) value {
+				replacement := value{}
+				if len(args) > 1 && !args[1].missing {
+					replacement = args[1]
+				}
+				return in.elementwise(args[0], replacement, func(x, r float64) float64 {
+					if math.IsNaN(x) {
+						return r
+					}
+					return x
+				})
+			},
+		},
+		"na": {
+			params:   []string{"x"},
+			required: 1,
+			call: func(in *interp, c *callNode, args []value) value {
+				return in.elementwise(args[0], value{}, func(x, _ float64) float64 {
+					return boolToFloat(math.IsNaN(x))
+				})
+			},
+		},
+
+		// 使用者輸入：返回預設值或訂閱提供的覆寫值
+		"input":       inputFunc(),
+		"input.int":   inputFunc("minval", "maxval", "step", "tooltip", "group", "inline"),
+		"input.float": inputFunc("minval", "maxval", "step", "tooltip", "group", "inline"),
+		"input.bool":  inputFunc("tooltip", "group", "inline"),
+		"input.source": {
+			params:     []string{"defval", "title"},
+			required:   1,
+			extraNamed: true,
+			call: func(in *interp, c *callNode, args []value) value {
+				return args[0]
+			},
+		},
+
+		// 輸出
+		"plot": {
+			params:     []string{"series", "title"},
+			required:   1,
+			extraNamed: true,
+			call: func(in *interp, c *callNode, args []value) value {
+				in.result.Plots = append(in.result.Plots, Plot{
+					Title:  in.title(args, 1, "plot", len(in.result.Plots)),
+					Values: in.series(args[0]),
+				})
+				return value{}
+			},
+		},
+		"alertcondition": {
+			params:   []string{"condition", "title", "message"},
+			required: 1,
+			call: func(in *interp, c *callNode, args []value) value {
+				cond := in.series(args[0])
+				fired := make([]bool, len(cond))
+				for i, v := range cond {
+					fired[i] = truthy(v)
+				}
+				message := ""
+				if len(args) > 2 && !args[2].missing {
+					message = args[2].str
+				}
+				in.result.Alerts = append(in.result.Alerts, AlertCondition{
+					Title:   in.title(args, 1, "alert", len(in.result.Alerts)),
+					Message: message,
+					Values:  fired,
+				})
+				return value{}
+			},
+		},
+
+		// 腳本宣告（忽略）
+		"indicator": declarationFunc(),
+		"study":     declarationFunc(),
+	}
+}
+
+// lengthFunc ta.xxx(source, length) 類型的函數
+func lengthFunc(fn func([]float64, int) []float64) *builtin {
+	return &builtin{
+		params:   []string{"source", "length"},
+		required: 2,
+		call: func(in *interp, c *callNode, args []value) value {
+			length := in.length(c, args[1])
+			in.tick(int64(in.n) * int64(length))
+			return in.wrap(fn(in.series(args[0]), length))
+		},
+	}
+}
+
+// mathFunc 單一參數的數學函數
+func mathFunc(fn func(float64) float64) *builtin {
+	return &builtin{
+		params:   []string{"number"},
+		required: 1,
+		call: func(in *interp, c *callNode, args []value) value {
+			return in.elementwise(args[0], value{}, func(x, _ float64) float64 {
+				return fn(x)
+			})
+		},
+	}
+}
+
+// inputFunc input() 系列函數
+func inputFunc(extra ...string) *builtin {
+	return &builtin{
+		params:     append([]string{"defval", "title"}, extra...),
+		required:   1,
+		extraNamed: true,
+		call: func(in *interp, c *callNode, args []value) value {
+			name := in.inputNames[c]
+			if v, ok := in.inputs[name]; ok {
+				return value{num: v}
+			}
+			return args[0]
+		},
+	}
+}
+
+// declarationFunc indicator() / study() 宣告，不影響計算
+func declarationFunc() *builtin {
+	return &builtin{
+		params:     []string{"title"},
+		extraNamed: true,
+		call: func(in *interp, c *callNode, args []value) value {
+			return value{}
+		},
+	}
+}
+
+// rollingExtreme 滾動最高／最低值
+func rollingExtreme(values []float64, length int, pick func(a, b float64) float64) []float64 {
+	out := make([]float64, len(values))
+	for i := range values {
+		out[i] = math.NaN()
+		if i < length-1 {
+			continue
+		}
+		extreme := values[i]
+		for j := i - length + 1; j < i; j++ {
+			extreme = pick(extreme, values[j])
+		}
+		out[i] = extreme
+	}
+	return out
+}
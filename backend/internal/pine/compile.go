@@ -0,0 +1,267 @@
+// Package pine Pine Script 子集直譯器
+//
+// 支援的語法：
+//   - 變數宣告 x = expr（不支援 := 重新賦值、if/for 區塊與自訂函數）
+//   - 序列運算 + - * / %、比較、and/or/not、三元運算、歷史參照 x[n]
+//   - 內建序列 open/high/low/close/volume/hl2/hlc3/ohlc4/bar_index
+//   - ta.sma/ema/rma/rsi/stdev/linreg/highest/lowest/change/crossover/crossunder
+//   - input()、input.int/float/bool/source、plot()、alertcondition()
+//
+// 腳本以「整條序列」為單位計算，而非逐根執行，
+// 因此所有運算都在固定的步數、記憶體與時間預算內完成。
+package pine
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// 腳本大小限制
+const (
+	MaxSourceBytes = 16 * 1024 // 腳本最大長度
+	MaxStatements  = 200       // 最多敘述數量
+	MaxLength      = 1000      // 週期參數（length）上限
+)
+
+// Input 腳本宣告的輸入參數
+type Input struct {
+	Name    string  `json:"name"`    // 參數名稱（input 的 title，未提供時為變數名稱）
+	Default float64 `json:"default"` // 預設值（布林以 1/0 表示）
+}
+
+// Program 編譯後的腳本
+type Program struct {
+	stmts      []statement
+	inputs     []Input
+	inputNames map[*callNode]string
+	alerts     []string
+}
+
+// Compile 解析並檢查腳本
+func Compile(src string) (*Program, error) {
+	if len(src) > MaxSourceBytes {
+		return nil, errorf(0, "腳本長度超過上限 %d bytes", MaxSourceBytes)
+	}
+
+	stmts, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(stmts) > MaxStatements {
+		return nil, errorf(0, "敘述數量超過上限 %d", MaxStatements)
+	}
+
+	c := &compiler{
+		defined: make(map[string]bool),
+		program: &Program{stmts: stmts, inputNames: make(map[*callNode]string)},
+	}
+	for _, stmt := range stmts {
+		if err := c.statement(stmt); err != nil {
+			return nil, err
+		}
+	}
+	if len(c.program.alerts) == 0 {
+		return nil, errorf(0, "腳本至少需要一個 alertcondition() 才能作為訂閱觸發條件")
+	}
+	return c.program, nil
+}
+
+// Inputs 腳本宣告的輸入參數
+func (p *Program) Inputs() []Input {
+	return p.inputs
+}
+
+// Alerts 腳本宣告的 alertcondition 標題
+func (p *Program) Alerts() []string {
+	return p.alerts
+}
+
+// compiler 編譯期檢查：變數必須先宣告、函數與參數必須存在、歷史參照必須是常數
+type compiler struct {
+	defined map[string]bool
+	program *Program
+}
+
+func (c *compiler) statement(stmt statement) error {
+	if stmt.name != "" {
+		if builtinSeries[stmt.name] || c.defined[stmt.name] {
+			return errorf(stmt.line, "變數 %q 已宣告，不支援重新賦值", stmt.name)
+		}
+	}
+
+	if err := c.expr(stmt.x); err != nil {
+		return err
+	}
+
+	if call, ok := stmt.x.(*callNode); ok {
+		if err := c.topLevelCall(stmt, call); err != nil {
+			return err
+		}
+	}
+
+	if stmt.name != "" {
+		c.defined[stmt.name] = true
+	}
+	return nil
+}
+
+// topLevelCall 記錄 input 與 alertcondition 的宣告資訊
+func (c *compiler) topLevelCall(stmt statement, call *callNode) error {
+	switch {
+	case strings.HasPrefix(call.name, "input"):
+		name := stmt.name
+		if title, ok := stringArg(call, 1, "title"); ok {
+			name = title
+		}
+		if name == "" {
+			return errorf(call.line, "%s 必須指定 title 或賦值給變數", call.name)
+		}
+
+		def := 0.0
+		if call.name != "input.source" {
+			v, ok := constantArg(argNode(call, 0, "defval"))
+			if !ok {
+				return errorf(call.line, "%s 的預設值必須是常數", call.name)
+			}
+			def = v
+		}
+		c.program.inputs = append(c.program.inputs, Input{Name: name, Default: def})
+		c.program.inputNames[call] = name
+
+	case call.name == "alertcondition":
+		title, ok := stringArg(call, 1, "title")
+		if !ok {
+			title = fmt.Sprintf("alert %d", len(c.program.alerts))
+		}
+		c.program.alerts = append(c.program.alerts, title)
+	}
+	return nil
+}
+
+func (c *compiler) expr(n node) error {
+	switch n := n.(type) {
+	case *numberNode, *stringNode:
+		return nil
+
+	case *identNode:
+		if builtinSeries[n.name] || c.defined[n.name] || strings.HasPrefix(n.name, "color.") {
+			return nil
+		}
+		if _, ok := builtins[n.name]; ok {
+			return errorf(n.line, "%s 是函數，需要加上括號與參數", n.name)
+		}
+		return errorf(n.line, "未宣告的變數 %q", n.name)
+
+	case *unaryNode:
+		return c.expr(n.x)
+
+	case *binaryNode:
+		if err := c.expr(n.left); err != nil {
+			return err
+		}
+		return c.expr(n.right)
+
+	case *ternaryNode:
+		for _, x := range []node{n.cond, n.then, n.orElse} {
+			if err := c.expr(x); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *historyNode:
+		offset, ok := n.offset.(*numberNode)
+		if !ok || offset.value < 0 || offset.value != math.Trunc(offset.value) || offset.value > MaxLength {
+			return errorf(n.line, "歷史參照 [n] 的 n 必須是 0 到 %d 之間的整數常數", MaxLength)
+		}
+		return c.expr(n.x)
+
+	case *callNode:
+		return c.call(n)
+	}
+	return errorf(n.lineNo(), "無法識別的語法")
+}
+
+func (c *compiler) call(n *callNode) error {
+	b, ok := builtins[n.name]
+	if !ok {
+		return errorf(n.line, "不支援的函數 %q", n.name)
+	}
+
+	if len(n.args) > len(b.params) {
+		return errorf(n.line, "%s 最多 %d 個參數，得到 %d 個", n.name, len(b.params), len(n.args))
+	}
+	for name := range n.named {
+		idx := indexOf(b.params, name)
+		if idx < 0 && !b.extraNamed {
+			return errorf(n.line, "%s 沒有參數 %q", n.name, name)
+		}
+		if idx >= 0 && idx < len(n.args) {
+			return errorf(n.line, "%s 的參數 %q 重複指定", n.name, name)
+		}
+	}
+	for i := 0; i < b.required; i++ {
+		if i >= len(n.args) && n.named[b.params[i]] == nil {
+			return errorf(n.line, "%s 缺少參數 %q", n.name, b.params[i])
+		}
+	}
+
+	for _, arg := range n.args {
+		if err := c.expr(arg); err != nil {
+			return err
+		}
+	}
+	for _, arg := range n.named {
+		if err := c.expr(arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// argNode 取出第 pos 個位置參數或具名參數，未提供時返回 nil
+func argNode(call *callNode, pos int, name string) node {
+	if pos < len(call.args) {
+		return call.args[pos]
+	}
+	return call.named[name]
+}
+
+// stringArg 取出第 pos 個位置參數或具名參數的字串常數
+func stringArg(call *callNode, pos int, name string) (string, bool) {
+	s, ok := argNode(call, pos, name).(*stringNode)
+	if !ok {
+		return "", false
+	}
+	return s.value, true
+}
+
+// constantArg 取出數字或布林常數
+func constantArg(arg node) (float64, bool) {
+	switch a := arg.(type) {
+	case *numberNode:
+		return a.value, true
+	case *unaryNode:
+		if num, ok := a.x.(*numberNode); ok && a.op == "-" {
+			return -num.value, true
+		}
+	case *identNode:
+		switch a.name {
+		case "true":
+			return 1, true
+		case "false":
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
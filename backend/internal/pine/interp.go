@@ -0,0 +1,400 @@
+package pine
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// MaxBars 單次執行最多的 K 線根數
+const MaxBars = 2000
+
+// Series 腳本執行的 K 線序列，最新的在最後
+type Series struct {
+	Time   []int64
+	Open   []float64
+	High   []float64
+	Low    []float64
+	Close  []float64
+	Volume []float64
+}
+
+// Limits 執行預算
+type Limits struct {
+	MaxSteps int64         // 最多運算步數（約等於處理的序列元素數量）
+	MaxCells int64         // 最多配置的序列元素數量（記憶體預算）
+	Timeout  time.Duration // 最長執行時間
+}
+
+// DefaultLimits 返回預設執行預算
+func DefaultLimits() Limits {
+	return Limits{
+		MaxSteps: 5_000_000,
+		MaxCells: 2_000_000,
+		Timeout:  500 * time.Millisecond,
+	}
+}
+
+// Plot plot() 輸出的序列
+type Plot struct {
+	Title  string    `json:"title"`
+	Values []float64 `json:"-"`
+}
+
+// AlertCondition alertcondition() 輸出的序列
+type AlertCondition struct {
+	Title   string `json:"title"`
+	Message string `json:"message"`
+	Values  []bool `json:"-"`
+}
+
+// Result 腳本執行結果
+type Result struct {
+	Plots  []Plot
+	Alerts []AlertCondition
+	Steps  int64 // 實際使用的運算步數
+}
+
+// Alert 依標題取得 alertcondition 結果，title 為空時返回第一個
+func (r *Result) Alert(title string) (*AlertCondition, bool) {
+	for i := range r.Alerts {
+		if title == "" || r.Alerts[i].Title == title {
+			return &r.Alerts[i], true
+		}
+	}
+	return nil, false
+}
+
+// Run 在 K 線序列上執行腳本
+// inputs: 依 Input.Name 覆寫 input() 的預設值
+func (p *Program) Run(series Series, inputs map[string]float64, limits Limits) (result *Result, err error) {
+	n := len(series.Close)
+	if n == 0 {
+		return nil, errorf(0, "沒有 K 線資料")
+	}
+	if n > MaxBars {
+		return nil, errorf(0, "K 線數量超過上限 %d", MaxBars)
+	}
+	if len(series.Open) != n || len(series.High) != n || len(series.Low) != n || len(series.Volume) != n {
+		return nil, errorf(0, "K 線序列長度不一致")
+	}
+
+	in := &interp{
+		n:          n,
+		bars:       series,
+		vars:       make(map[string]value),
+		inputs:     inputs,
+		inputNames: p.inputNames,
+		limits:     limits,
+		deadline:   time.Now().Add(limits.Timeout),
+		result:     &Result{},
+	}
+
+	// 預算用盡或執行錯誤以 panic 中斷深層遞迴，在此統一轉為錯誤
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*Error)
+			if !ok {
+				panic(r)
+			}
+			result, err = nil, e
+		}
+	}()
+
+	for _, stmt := range p.stmts {
+		v := in.eval(stmt.x)
+		if stmt.name != "" {
+			in.vars[stmt.name] = v
+		}
+	}
+
+	in.result.Steps = in.steps
+	return in.result, nil
+}
+
+// value 執行期的值：常數（series 為 nil）、序列或字串
+type value struct {
+	num     float64
+	series  []float64
+	str     string
+	missing bool // 未提供的選填參數
+}
+
+// interp 直譯器狀態
+type interp struct {
+	n          int
+	bars       Series
+	vars       map[string]value
+	inputs     map[string]float64
+	inputNames map[*callNode]string
+	limits     Limits
+
+	steps    int64
+	cells    int64
+	deadline time.Time
+	result   *Result
+}
+
+// tick 累計運算步數並檢查預算
+func (in *interp) tick(cost int64) {
+	in.steps += cost
+	if in.limits.MaxSteps > 0 && in.steps > in.limits.MaxSteps {
+		panic(errorf(0, "超過運算預算（%d 步）", in.limits.MaxSteps))
+	}
+	if in.limits.Timeout > 0 && time.Now().After(in.deadline) {
+		panic(errorf(0, "超過執行時間上限 %v", in.limits.Timeout))
+	}
+}
+
+// account 累計配置的序列元素並檢查記憶體預算
+func (in *interp) account(cells int) {
+	in.cells += int64(cells)
+	if in.limits.MaxCells > 0 && in.cells > in.limits.MaxCells {
+		panic(errorf(0, "超過記憶體預算（%d 個序列元素）", in.limits.MaxCells))
+	}
+}
+
+// alloc 配置一條新序列
+func (in *interp) alloc() []float64 {
+	in.account(in.n)
+	return make([]float64, in.n)
+}
+
+// wrap 包裝外部函數產生的序列
+func (in *interp) wrap(s []float64) value {
+	in.account(len(s))
+	return value{series: s}
+}
+
+// series 將值轉換為序列（常數會展開）
+func (in *interp) series(v value) []float64 {
+	if v.series != nil {
+		return v.series
+	}
+	out := in.alloc()
+	for i := range out {
+		out[i] = v.num
+	}
+	return out
+}
+
+// length 取出 length 類參數（必須是常數）
+func (in *interp) length(c *callNode, v value) int {
+	return in.integer(c, v, "length", 1)
+}
+
+// integer 取出整數常數參數並檢查範圍
+func (in *interp) integer(c *callNode, v value, name string, min int) int {
+	if v.series != nil {
+		panic(errorf(c.line, "%s 的 %s 必須是常數", c.name, name))
+	}
+	if v.num != math.Trunc(v.num) || v.num < float64(min) || v.num > MaxLength {
+		panic(errorf(c.line, "%s 的 %s 必須是 %d 到 %d 之間的整數", c.name, name, min, MaxLength))
+	}
+	return int(v.num)
+}
+
+// title 取出標題參數，未提供時產生預設標題
+func (in *interp) title(args []value, pos int, prefix string, index int) string {
+	if pos < len(args) && !args[pos].missing && args[pos].str != "" {
+		return args[pos].str
+	}
+	return fmt.Sprintf("%s %d", prefix, index)
+}
+
+// elementwise 逐元素運算，兩個常數的結果仍為常數
+func (in *interp) elementwise(a, b value, fn func(x, y float64) float64) value {
+	in.tick(int64(in.n))
+	if a.series == nil && b.series == nil {
+		return value{num: fn(a.num, b.num)}
+	}
+	out := in.alloc()
+	for i := range out {
+		out[i] = fn(at(a, i), at(b, i))
+	}
+	return value{series: out}
+}
+
+// cross 計算 ta.crossover / ta.crossunder
+func (in *interp) cross(a, b value, over bool) value {
+	in.tick(int64(in.n))
+	out := in.alloc()
+	for i := range out {
+		if i == 0 {
+			continue
+		}
+		prevA, prevB := at(a, i-1), at(b, i-1)
+		curA, curB := at(a, i), at(b, i)
+		if over {
+			out[i] = boolToFloat(curA > curB && prevA <= prevB)
+		} else {
+			out[i] = boolToFloat(curA < curB && prevA >= prevB)
+		}
+	}
+	return value{series: out}
+}
+
+func (in *interp) eval(n node) value {
+	in.tick(1)
+
+	switch n := n.(type) {
+	case *numberNode:
+		return value{num: n.value}
+
+	case *stringNode:
+		return value{str: n.value}
+
+	case *identNode:
+		if v, ok := in.vars[n.name]; ok {
+			return v
+		}
+		return in.builtinSeries(n.name)
+
+	case *unaryNode:
+		x := in.eval(n.x)
+		if n.op == "not" {
+			return in.elementwise(x, value{}, func(v, _ float64) float64 { return boolToFloat(!truthy(v)) })
+		}
+		return in.elementwise(x, value{}, func(v, _ float64) float64 { return -v })
+
+	case *binaryNode:
+		return in.elementwise(in.eval(n.left), in.eval(n.right), binaryOps[n.op])
+
+	case *ternaryNode:
+		cond, then, orElse := in.eval(n.cond), in.eval(n.then), in.eval(n.orElse)
+		in.tick(int64(in.n))
+		if cond.series == nil && then.series == nil && orElse.series == nil {
+			if truthy(cond.num) {
+				return then
+			}
+			return orElse
+		}
+		out := in.alloc()
+		for i := range out {
+			if truthy(at(cond, i)) {
+				out[i] = at(then, i)
+			} else {
+				out[i] = at(orElse, i)
+			}
+		}
+		return value{series: out}
+
+	case *historyNode:
+		x := in.eval(n.x)
+		offset := int(n.offset.(*numberNode).value)
+		if x.series == nil || offset == 0 {
+			return x
+		}
+		in.tick(int64(in.n))
+		out := in.alloc()
+		for i := range out {
+			out[i] = math.NaN()
+			if i >= offset {
+				out[i] = x.series[i-offset]
+			}
+		}
+		return value{series: out}
+
+	case *callNode:
+		b := builtins[n.name]
+		args := make([]value, len(b.params))
+		for i := range args {
+			arg := argNode(n, i, b.params[i])
+			if arg == nil {
+				args[i] = value{missing: true}
+				continue
+			}
+			args[i] = in.eval(arg)
+		}
+		return b.call(in, n, args)
+	}
+	panic(errorf(n.lineNo(), "無法執行的語法"))
+}
+
+// builtinSeries 內建序列變數
+func (in *interp) builtinSeries(name string) value {
+	s := in.bars
+	switch name {
+	case "open":
+		return value{series: s.Open}
+	case "high":
+		return value{series: s.High}
+	case "low":
+		return value{series: s.Low}
+	case "close":
+		return value{series: s.Close}
+	case "volume":
+		return value{series: s.Volume}
+	case "true":
+		return value{num: 1}
+	case "false":
+		return value{num: 0}
+	case "na":
+		return value{num: math.NaN()}
+	case "hl2", "hlc3", "ohlc4", "bar_index":
+		in.tick(int64(in.n))
+		out := in.alloc()
+		for i := range out {
+			switch name {
+			case "hl2":
+				out[i] = (s.High[i] + s.Low[i]) / 2
+			case "hlc3":
+				out[i] = (s.High[i] + s.Low[i] + s.Close[i]) / 3
+			case "ohlc4":
+				out[i] = (s.Open[i] + s.High[i] + s.Low[i] + s.Close[i]) / 4
+			case "bar_index":
+				out[i] = float64(i)
+			}
+		}
+		return value{series: out}
+	}
+	// color.* 等僅用於繪圖的常數
+	return value{str: name}
+}
+
+// binaryOps 二元運算子
+var binaryOps = map[string]func(a, b float64) float64{
+	"+": func(a, b float64) float64 { return a + b },
+	"-": func(a, b float64) float64 { return a - b },
+	"*": func(a, b float64) float64 { return a * b },
+	"/": func(a, b float64) float64 {
+		if b == 0 {
+			return math.NaN()
+		}
+		return a / b
+	},
+	"%": func(a, b float64) float64 {
+		if b == 0 {
+			return math.NaN()
+		}
+		return math.Mod(a, b)
+	},
+	"<":   func(a, b float64) float64 { return boolToFloat(a < b) },
+	"<=":  func(a, b float64) float64 { return boolToFloat(a <= b) },
+	">":   func(a, b float64) float64 { return boolToFloat(a > b) },
+	">=":  func(a, b float64) float64 { return boolToFloat(a >= b) },
+	"==":  func(a, b float64) float64 { return boolToFloat(a == b) },
+	"!=":  func(a, b float64) float64 { return boolToFloat(a != b) },
+	"and": func(a, b float64) float64 { return boolToFloat(truthy(a) && truthy(b)) },
+	"or":  func(a, b float64) float64 { return boolToFloat(truthy(a) || truthy(b)) },
+}
+
+// at 取出值在第 i 根的數值
+func at(v value, i int) float64 {
+	if v.series == nil {
+		return v.num
+	}
+	return v.series[i]
+}
+
+// truthy 布林判斷：na 與 0 為 false
+func truthy(v float64) bool {
+	return !math.IsNaN(v) && v != 0
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,168 @@
+package pine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind 詞法單元類型
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNewline
+	tokenNumber
+	tokenString
+	tokenIdent    // 可包含命名空間，例如 ta.sma
+	tokenOp       // + - * / % < <= > >= == !=
+	tokenAssign   // =
+	tokenReassign // :=
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenComma
+	tokenQuestion
+	tokenColon
+)
+
+// token 詞法單元
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	line int
+}
+
+// lex 將腳本切分為詞法單元
+// 括號內的換行會被忽略，讓函數參數可以跨行撰寫
+func lex(src string) ([]token, error) {
+	tokens := make([]token, 0, 64)
+	line := 1
+	depth := 0
+	i := 0
+
+	for i < len(src) {
+		c := rune(src[i])
+
+		switch {
+		case c == '\n':
+			if depth == 0 && len(tokens) > 0 && tokens[len(tokens)-1].kind != tokenNewline {
+				tokens = append(tokens, token{kind: tokenNewline, line: line})
+			}
+			line++
+			i++
+
+		case unicode.IsSpace(c):
+			i++
+
+		case strings.HasPrefix(src[i:], "//"):
+			// 註解（包含 //@version=5）
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(src) && unicode.IsDigit(rune(src[i+1]))):
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			text := src[start:i]
+			num, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, errorf(line, "無效的數字 %q", text)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, num: num, line: line})
+
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(src[i+1:], byte(c))
+			if end < 0 || strings.ContainsRune(src[i+1:i+1+end], '\n') {
+				return nil, errorf(line, "字串缺少結尾引號")
+			}
+			tokens = append(tokens, token{kind: tokenString, text: src[i+1 : i+1+end], line: line})
+			i += end + 2
+
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || src[i] == '.' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: src[start:i], line: line})
+
+		case c == ':' && i+1 < len(src) && src[i+1] == '=':
+			tokens = append(tokens, token{kind: tokenReassign, text: ":=", line: line})
+			i += 2
+
+		case c == '=' && (i+1 >= len(src) || src[i+1] != '='):
+			tokens = append(tokens, token{kind: tokenAssign, text: "=", line: line})
+			i++
+
+		case strings.ContainsRune("+-*/%<>=!", c):
+			op := string(c)
+			if i+1 < len(src) && src[i+1] == '=' && strings.ContainsRune("<>=!", c) {
+				op += "="
+			}
+			if op == "!" {
+				return nil, errorf(line, "無效的運算子 %q，請使用 not 或 !=", op)
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: op, line: line})
+			i += len(op)
+
+		case c == '(' || c == '[':
+			kind := tokenLParen
+			if c == '[' {
+				kind = tokenLBracket
+			}
+			depth++
+			tokens = append(tokens, token{kind: kind, text: string(c), line: line})
+			i++
+
+		case c == ')' || c == ']':
+			kind := tokenRParen
+			if c == ']' {
+				kind = tokenRBracket
+			}
+			if depth > 0 {
+				depth--
+			}
+			tokens = append(tokens, token{kind: kind, text: string(c), line: line})
+			i++
+
+		case c == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", line: line})
+			i++
+		case c == '?':
+			tokens = append(tokens, token{kind: tokenQuestion, text: "?", line: line})
+			i++
+		case c == ':':
+			tokens = append(tokens, token{kind: tokenColon, text: ":", line: line})
+			i++
+
+		default:
+			return nil, errorf(line, "無法識別的字元 %q", c)
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, line: line})
+	return tokens, nil
+}
+
+// Error 腳本的語法、檢查或執行錯誤
+type Error struct {
+	Line int    // 錯誤所在行號（從 1 開始，0 表示與行號無關）
+	Msg  string // 錯誤說明
+}
+
+func (e *Error) Error() string {
+	if e.Line == 0 {
+		return e.Msg
+	}
+	return fmt.Sprintf("第 %d 行: %s", e.Line, e.Msg)
+}
+
+// errorf 建立腳本錯誤
+func errorf(line int, format string, args ...interface{}) *Error {
+	return &Error{Line: line, Msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,400 @@
+package pine
+
+// node 語法樹節點
+type node interface {
+	lineNo() int
+}
+
+type (
+	// numberNode 數字常數
+	numberNode struct {
+		line  int
+		value float64
+	}
+
+	// stringNode 字串常數（標題、訊息等）
+	stringNode struct {
+		line  int
+		value string
+	}
+
+	// identNode 變數或內建序列（close、volume 等）
+	identNode struct {
+		line int
+		name string
+	}
+
+	// unaryNode 一元運算（-x、not x）
+	unaryNode struct {
+		line int
+		op   string
+		x    node
+	}
+
+	// binaryNode 二元運算
+	binaryNode struct {
+		line        int
+		op          string
+		left, right node
+	}
+
+	// ternaryNode 三元運算 cond ? a : b
+	ternaryNode struct {
+		line               int
+		cond, then, orElse node
+	}
+
+	// callNode 函數呼叫，支援具名參數（title="RSI"）
+	callNode struct {
+		line  int
+		name  string
+		args  []node
+		named map[string]node
+	}
+
+	// historyNode 歷史參照 x[n]
+	historyNode struct {
+		line   int
+		x      node
+		offset node
+	}
+)
+
+func (n *numberNode) lineNo() int  { return n.line }
+func (n *stringNode) lineNo() int  { return n.line }
+func (n *identNode) lineNo() int   { return n.line }
+func (n *unaryNode) lineNo() int   { return n.line }
+func (n *binaryNode) lineNo() int  { return n.line }
+func (n *ternaryNode) lineNo() int { return n.line }
+func (n *callNode) lineNo() int    { return n.line }
+func (n *historyNode) lineNo() int { return n.line }
+
+// statement 腳本敘述：變數宣告或函數呼叫
+type statement struct {
+	line int
+	name string // 宣告的變數名稱，函數呼叫敘述為空
+	x    node
+}
+
+// parser 遞迴下降解析器
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse 解析腳本為敘述清單
+func parse(src string) ([]statement, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	stmts := make([]statement, 0, 16)
+	for {
+		for p.peek().kind == tokenNewline {
+			p.next()
+		}
+		if p.peek().kind == tokenEOF {
+			return stmts, nil
+		}
+
+		stmt, err := p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, stmt)
+
+		if tok := p.peek(); tok.kind != tokenNewline && tok.kind != tokenEOF {
+			return nil, errorf(tok.line, "多餘的內容 %q", tok.text)
+		}
+	}
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(offset int) token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) isKeyword(word string) bool {
+	tok := p.peek()
+	return tok.kind == tokenIdent && tok.text == word
+}
+
+func (p *parser) parseStatement() (statement, error) {
+	tok := p.peek()
+
+	if tok.kind == tokenIdent {
+		switch tok.text {
+		case "if", "for", "while", "switch", "var", "varip", "function":
+			return statement{}, errorf(tok.line, "不支援的語法 %q（僅支援變數宣告與函數呼叫）", tok.text)
+		}
+
+		switch p.peekAt(1).kind {
+		case tokenAssign:
+			p.next()
+			p.next()
+			x, err := p.parseExpr()
+			if err != nil {
+				return statement{}, err
+			}
+			return statement{line: tok.line, name: tok.text, x: x}, nil
+		case tokenReassign:
+			return statement{}, errorf(tok.line, "不支援 := 重新賦值")
+		}
+	}
+
+	x, err := p.parseExpr()
+	if err != nil {
+		return statement{}, err
+	}
+	if _, ok := x.(*callNode); !ok {
+		return statement{}, errorf(tok.line, "敘述必須是變數宣告或函數呼叫")
+	}
+	return statement{line: tok.line, x: x}, nil
+}
+
+// parseExpr 優先順序（低到高）：?:、or、and、not、比較、+ -、* / %、一元 -、呼叫與歷史參照
+func (p *parser) parseExpr() (node, error) {
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenQuestion {
+		return cond, nil
+	}
+
+	tok := p.next()
+	then, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if colon := p.next(); colon.kind != tokenColon {
+		return nil, errorf(colon.line, "三元運算缺少 :")
+	}
+	orElse, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return &ternaryNode{line: tok.line, cond: cond, then: then, orElse: orElse}, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		tok := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{line: tok.line, op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		tok := p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{line: tok.line, op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.isKeyword("not") {
+		tok := p.next()
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{line: tok.line, op: "not", x: x}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	if tok.kind == tokenOp {
+		switch tok.text {
+		case "<", "<=", ">", ">=", "==", "!=":
+			p.next()
+			right, err := p.parseBinary(0)
+			if err != nil {
+				return nil, err
+			}
+			return &binaryNode{line: tok.line, op: tok.text, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+// arithmeticLevels 算術運算子的優先層級
+var arithmeticLevels = [][]string{
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) parseBinary(level int) (node, error) {
+	if level >= len(arithmeticLevels) {
+		return p.parseUnary()
+	}
+
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.kind != tokenOp || !contains(arithmeticLevels[level], tok.text) {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{line: tok.line, op: tok.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	tok := p.peek()
+	if tok.kind == tokenOp && (tok.text == "-" || tok.text == "+") {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if tok.text == "+" {
+			return x, nil
+		}
+		return &unaryNode{line: tok.line, op: "-", x: x}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenLBracket {
+		tok := p.next()
+		offset, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRBracket {
+			return nil, errorf(closing.line, "歷史參照缺少 ]")
+		}
+		x = &historyNode{line: tok.line, x: x, offset: offset}
+	}
+	return x, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		return &numberNode{line: tok.line, value: tok.num}, nil
+
+	case tokenString:
+		return &stringNode{line: tok.line, value: tok.text}, nil
+
+	case tokenIdent:
+		if p.peek().kind != tokenLParen {
+			return &identNode{line: tok.line, name: tok.text}, nil
+		}
+		return p.parseCall(tok)
+
+	case tokenLParen:
+		x, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, errorf(closing.line, "缺少右括號")
+		}
+		return x, nil
+
+	case tokenEOF, tokenNewline:
+		return nil, errorf(tok.line, "敘述不完整")
+	}
+	return nil, errorf(tok.line, "非預期的 %q", tok.text)
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	p.next() // (
+	call := &callNode{line: name.line, name: name.text, named: make(map[string]node)}
+
+	if p.peek().kind != tokenRParen {
+		for {
+			if p.peek().kind == tokenIdent && p.peekAt(1).kind == tokenAssign {
+				argName := p.next()
+				p.next()
+				arg, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				call.named[argName.text] = arg
+			} else {
+				if len(call.named) > 0 {
+					return nil, errorf(p.peek().line, "%s 的位置參數不能出現在具名參數之後", name.text)
+				}
+				arg, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				call.args = append(call.args, arg)
+			}
+
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if closing := p.next(); closing.kind != tokenRParen {
+		return nil, errorf(closing.line, "函數 %s 缺少右括號", name.text)
+	}
+	return call, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,9 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"cryptowatch/internal/models"
@@ -67,6 +70,53 @@ func (r *RedisRepository) SaveAlert(alert *models.Alert) error {
 	return r.client.Set(r.ctx, key, data, 0).Err()
 }
 
+// alertStateRetries 警報狀態寫入遇到同時更新時的重試次數
+const alertStateRetries = 3
+
+// UpdateAlertState 讀取最新的警報資料，套用 update 後寫回，返回是否已寫入
+// 以 WATCH 確保期間沒有其他寫入，監控器之間不會互相覆蓋；警報已刪除或 update 返回 false 時不寫入
+func (r *RedisRepository) UpdateAlertState(alertID string, update func(alert *models.Alert) bool) (bool, error) {
+	key := "alert:" + alertID
+	var written bool
+	txf := func(tx *redis.Tx) error {
+		written = false
+		data, err := tx.Get(r.ctx, key).Result()
+		if err == redis.Nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var alert models.Alert
+		if err := json.Unmarshal([]byte(data), &alert); err != nil {
+			return err
+		}
+		if !update(&alert) {
+			return nil
+		}
+		updated, err := json.Marshal(&alert)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(r.ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(r.ctx, key, updated, 0)
+			return nil
+		})
+		written = err == nil
+		return err
+	}
+
+	for i := 0; i < alertStateRetries; i++ {
+		err := r.client.Watch(r.ctx, txf, key)
+		if err != redis.TxFailedErr {
+			return written, err
+		}
+	}
+	return false, fmt.Errorf("alert %s updated concurrently", alertID)
+}
+
 func (r *RedisRepository) GetUserAlerts(userID string) ([]*models.Alert, error) {
 	pattern := "alert:*"
 	keys, err := r.client.Keys(r.ctx, pattern).Result()
@@ -96,6 +146,12 @@ func (r *RedisRepository) DeleteAlert(alertID string) error {
 	return r.client.Del(r.ctx, key).Err()
 }
 
+// DeleteAlertIfExists 刪除警報，返回刪除前警報是否存在
+func (r *RedisRepository) DeleteAlertIfExists(alertID string) (bool, error) {
+	n, err := r.client.Del(r.ctx, "alert:"+alertID).Result()
+	return n > 0, err
+}
+
 func (r *RedisRepository) GetAllAlerts() ([]*models.Alert, error) {
 	pattern := "alert:*"
 	keys, err := r.client.Keys(r.ctx, pattern).Result()
@@ -206,6 +262,48 @@ func (r *RedisRepository) UpdateSubscription(sub *models.IndicatorSubscription)
 	return r.client.Set(r.ctx, key, data, 0).Err()
 }
 
+// subscriptionStateRetries 訂閱狀態寫入遇到同時更新時的重試次數
+const subscriptionStateRetries = 3
+
+// UpdateSubscriptionState 讀取最新的訂閱資料，套用 update 後寫回
+// 以 WATCH 確保期間沒有其他寫入，不會覆蓋用戶同時做的更新；訂閱已刪除時不寫入
+func (r *RedisRepository) UpdateSubscriptionState(subscriptionID string, update func(sub *models.IndicatorSubscription)) error {
+	key := "indicator_sub:" + subscriptionID
+	txf := func(tx *redis.Tx) error {
+		data, err := tx.Get(r.ctx, key).Result()
+		if err == redis.Nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var sub models.IndicatorSubscription
+		if err := json.Unmarshal([]byte(data), &sub); err != nil {
+			return err
+		}
+		update(&sub)
+		updated, err := json.Marshal(&sub)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(r.ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(r.ctx, key, updated, 0)
+			return nil
+		})
+		return err
+	}
+
+	for i := 0; i < subscriptionStateRetries; i++ {
+		err := r.client.Watch(r.ctx, txf, key)
+		if err != redis.TxFailedErr {
+			return err
+		}
+	}
+	return fmt.Errorf("subscription %s updated concurrently", subscriptionID)
+}
+
 // DeleteSubscription 刪除訂閱
 func (r *RedisRepository) DeleteSubscription(subscriptionID string) error {
 	// 先獲取訂閱資料以取得 UserID 和 Symbol
@@ -320,3 +418,484 @@ func (r *RedisRepository) GetIndicatorConfig() (*models.IndicatorConfig, error)
 	}
 	return &config, nil
 }
+
+// ==================== 腳本相關方法 ====================
+
+// SaveScript 儲存腳本
+func (r *RedisRepository) SaveScript(script *models.Script) error {
+	data, err := json.Marshal(script)
+	if err != nil {
+		return err
+	}
+
+	key := "script:" + script.ScriptID
+	if err := r.client.Set(r.ctx, key, data, 0).Err(); err != nil {
+		return err
+	}
+
+	// 加入用戶的腳本集合
+	userKey := "scripts:user:" + script.UserID
+	return r.client.SAdd(r.ctx, userKey, script.ScriptID).Err()
+}
+
+// GetScript 獲取單個腳本
+func (r *RedisRepository) GetScript(scriptID string) (*models.Script, error) {
+	key := "script:" + scriptID
+	data, err := r.client.Get(r.ctx, key).Result()
+	if err == redis.Nil {
+		return nil, &models.NotFoundError{Resource: "script", ID: scriptID}
+	}
+	if err != nil {
+		return nil, err
+	}
+	var script models.Script
+	if err := json.Unmarshal([]byte(data), &script); err != nil {
+		return nil, err
+	}
+	return &script, nil
+}
+
+// GetUserScripts 獲取用戶的所有腳本
+func (r *RedisRepository) GetUserScripts(userID string) ([]*models.Script, error) {
+	userKey := "scripts:user:" + userID
+	scriptIDs, err := r.client.SMembers(r.ctx, userKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	scripts := make([]*models.Script, 0, len(scriptIDs))
+	for _, scriptID := range scriptIDs {
+		script, err := r.GetScript(scriptID)
+		if err != nil {
+			continue
+		}
+		scripts = append(scripts, script)
+	}
+	return scripts, nil
+}
+
+// DeleteScript 刪除腳本
+func (r *RedisRepository) DeleteScript(scriptID string) error {
+	script, err := r.GetScript(scriptID)
+	if err != nil {
+		return err
+	}
+
+	userKey := "scripts:user:" + script.UserID
+	r.client.SRem(r.ctx, userKey, scriptID)
+
+	key := "script:" + scriptID
+	return r.client.Del(r.ctx, key).Err()
+}
+
+// ==================== 支撐壓力位相關方法 ====================
+
+// SetSRLevels 儲存幣種的支撐壓力位
+func (r *RedisRepository) SetSRLevels(levels *models.SRLevels) error {
+	data, err := json.Marshal(levels)
+	if err != nil {
+		return err
+	}
+	key := "sr_levels:" + levels.Symbol + ":" + levels.Interval
+	return r.client.Set(r.ctx, key, data, 0).Err()
+}
+
+// GetSRLevels 獲取幣種的支撐壓力位
+func (r *RedisRepository) GetSRLevels(symbol, interval string) (*models.SRLevels, error) {
+	key := "sr_levels:" + symbol + ":" + interval
+	data, err := r.client.Get(r.ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	var levels models.SRLevels
+	if err := json.Unmarshal([]byte(data), &levels); err != nil {
+		return nil, err
+	}
+	return &levels, nil
+}
+
+// ==================== 成交量分佈相關方法 ====================
+
+// volumeProfileKey 成交量分佈的快取 key
+func volumeProfileKey(symbol, interval string, lookback int) string {
+	return fmt.Sprintf("volume_profile:%s:%s:%d", symbol, interval, lookback)
+}
+
+// SetVolumeProfile 快取成交量分佈，到下一根 K 線收盤時過期
+func (r *RedisRepository) SetVolumeProfile(profile *models.VolumeProfile) error {
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(profile.NextRefreshAt)
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	key := volumeProfileKey(profile.Symbol, profile.Interval, profile.Lookback)
+	return r.client.Set(r.ctx, key, data, ttl).Err()
+}
+
+// GetVolumeProfile 獲取快取的成交量分佈
+func (r *RedisRepository) GetVolumeProfile(symbol, interval string, lookback int) (*models.VolumeProfile, error) {
+	data, err := r.client.Get(r.ctx, volumeProfileKey(symbol, interval, lookback)).Result()
+	if err != nil {
+		return nil, err
+	}
+	var profile models.VolumeProfile
+	if err := json.Unmarshal([]byte(data), &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// ==================== 相關係數相關方法 ====================
+
+// correlationKey 相關矩陣的快取 key
+func correlationKey(interval string, window int) string {
+	return fmt.Sprintf("correlation:%s:%d", interval, window)
+}
+
+// SetCorrelation 快取相關矩陣，到下一根 K 線收盤時過期
+func (r *RedisRepository) SetCorrelation(matrix *models.CorrelationMatrix) error {
+	data, err := json.Marshal(matrix)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(matrix.NextRefreshAt)
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	return r.client.Set(r.ctx, correlationKey(matrix.Interval, matrix.Window), data, ttl).Err()
+}
+
+// GetCorrelation 獲取快取的相關矩陣
+func (r *RedisRepository) GetCorrelation(interval string, window int) (*models.CorrelationMatrix, error) {
+	data, err := r.client.Get(r.ctx, correlationKey(interval, window)).Result()
+	if err != nil {
+		return nil, err
+	}
+	var matrix models.CorrelationMatrix
+	if err := json.Unmarshal([]byte(data), &matrix); err != nil {
+		return nil, err
+	}
+	return &matrix, nil
+}
+
+// ==================== 參數搜尋相關方法 ====================
+
+// sweepJobTTL 參數搜尋任務的保留時間
+const sweepJobTTL = 7 * 24 * time.Hour
+
+// SaveSweepJob 儲存參數搜尋任務
+func (r *RedisRepository) SaveSweepJob(job *models.SweepJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(r.ctx, "sweep:"+job.ID, data, sweepJobTTL).Err()
+}
+
+// GetSweepJob 獲取參數搜尋任務
+func (r *RedisRepository) GetSweepJob(id string) (*models.SweepJob, error) {
+	data, err := r.client.Get(r.ctx, "sweep:"+id).Result()
+	if err != nil {
+		return nil, err
+	}
+	var job models.SweepJob
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetSweepJobs 獲取所有保留中的參數搜尋任務
+func (r *RedisRepository) GetSweepJobs() ([]*models.SweepJob, error) {
+	keys, err := r.client.Keys(r.ctx, "sweep:*").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*models.SweepJob, 0, len(keys))
+	for _, key := range keys {
+		job, err := r.GetSweepJob(strings.TrimPrefix(key, "sweep:"))
+		if err != nil {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// ==================== 訊號追蹤相關方法 ====================
+
+// 訊號記錄的保留時間與每個索引保留的訊號數
+const (
+	signalTTL       = 90 * 24 * time.Hour
+	signalIndexSize = 1000
+)
+
+// SaveSignal 儲存新訊號，並加入訂閱、幣種索引與待追蹤集合
+func (r *RedisRepository) SaveSignal(signal *models.SignalRecord) error {
+	if err := r.UpdateSignal(signal); err != nil {
+		return err
+	}
+
+	member := redis.Z{Score: float64(signal.CreatedAt.UnixMilli()), Member: signal.SignalID}
+	for _, key := range []string{"signals:sub:" + signal.SubscriptionID, "signals:symbol:" + signal.Symbol} {
+		if err := r.client.ZAdd(r.ctx, key, member).Err(); err != nil {
+			return err
+		}
+		// 只保留最近的訊號
+		if err := r.client.ZRemRangeByRank(r.ctx, key, 0, -signalIndexSize-1).Err(); err != nil {
+			return err
+		}
+	}
+	return r.client.SAdd(r.ctx, "signals:pending", signal.SignalID).Err()
+}
+
+// UpdateSignal 更新訊號記錄，追蹤完成時移出待追蹤集合
+func (r *RedisRepository) UpdateSignal(signal *models.SignalRecord) error {
+	data, err := json.Marshal(signal)
+	if err != nil {
+		return err
+	}
+	if err := r.client.Set(r.ctx, "signal:"+signal.SignalID, data, signalTTL).Err(); err != nil {
+		return err
+	}
+	if signal.Completed {
+		return r.client.SRem(r.ctx, "signals:pending", signal.SignalID).Err()
+	}
+	return nil
+}
+
+// GetSignal 獲取單個訊號
+func (r *RedisRepository) GetSignal(signalID string) (*models.SignalRecord, error) {
+	data, err := r.client.Get(r.ctx, "signal:"+signalID).Result()
+	if err != nil {
+		return nil, err
+	}
+	var signal models.SignalRecord
+	if err := json.Unmarshal([]byte(data), &signal); err != nil {
+		return nil, err
+	}
+	return &signal, nil
+}
+
+// GetPendingSignals 獲取尚未追蹤完成的訊號
+func (r *RedisRepository) GetPendingSignals() ([]*models.SignalRecord, error) {
+	ids, err := r.client.SMembers(r.ctx, "signals:pending").Result()
+	if err != nil {
+		return nil, err
+	}
+	return r.getSignals(ids), nil
+}
+
+// GetSubscriptionSignals 獲取訂閱最近的 limit 個訊號（新到舊）
+func (r *RedisRepository) GetSubscriptionSignals(subscriptionID string, limit int) ([]*models.SignalRecord, error) {
+	ids, err := r.client.ZRevRange(r.ctx, "signals:sub:"+subscriptionID, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	return r.getSignals(ids), nil
+}
+
+// GetSymbolSignals 獲取幣種最近的 limit 個訊號（新到舊）
+func (r *RedisRepository) GetSymbolSignals(symbol string, limit int) ([]*models.SignalRecord, error) {
+	ids, err := r.client.ZRevRange(r.ctx, "signals:symbol:"+symbol, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	return r.getSignals(ids), nil
+}
+
+// getSignals 依 ID 獲取訊號，已過期的略過
+func (r *RedisRepository) getSignals(ids []string) []*models.SignalRecord {
+	signals := make([]*models.SignalRecord, 0, len(ids))
+	for _, id := range ids {
+		signal, err := r.GetSignal(id)
+		if err != nil {
+			continue
+		}
+		signals = append(signals, signal)
+	}
+	return signals
+}
+
+// ==================== 模擬交易相關方法 ====================
+
+// SavePaperPosition 儲存模擬部位，並維護用戶索引與未平倉集合
+func (r *RedisRepository) SavePaperPosition(position *models.PaperPosition) error {
+	data, err := json.Marshal(position)
+	if err != nil {
+		return err
+	}
+	if err := r.client.Set(r.ctx, "paper_position:"+position.PositionID, data, 0).Err(); err != nil {
+		return err
+	}
+
+	// 加入用戶的部位集合
+	if err := r.client.SAdd(r.ctx, "paper_positions:user:"+position.UserID, position.PositionID).Err(); err != nil {
+		return err
+	}
+
+	// 維護未平倉集合
+	if position.Status == models.PaperStatusOpen {
+		return r.client.SAdd(r.ctx, "paper_positions:open", position.PositionID).Err()
+	}
+	return r.client.SRem(r.ctx, "paper_positions:open", position.PositionID).Err()
+}
+
+// GetPaperPosition 獲取單個模擬部位
+func (r *RedisRepository) GetPaperPosition(positionID string) (*models.PaperPosition, error) {
+	data, err := r.client.Get(r.ctx, "paper_position:"+positionID).Result()
+	if err != nil {
+		return nil, err
+	}
+	var position models.PaperPosition
+	if err := json.Unmarshal([]byte(data), &position); err != nil {
+		return nil, err
+	}
+	return &position, nil
+}
+
+// GetUserPaperPositions 獲取用戶的所有模擬部位
+func (r *RedisRepository) GetUserPaperPositions(userID string) ([]*models.PaperPosition, error) {
+	ids, err := r.client.SMembers(r.ctx, "paper_positions:user:"+userID).Result()
+	if err != nil {
+		return nil, err
+	}
+	return r.getPaperPositions(ids), nil
+}
+
+// GetOpenPaperPositions 獲取所有未平倉的模擬部位
+func (r *RedisRepository) GetOpenPaperPositions() ([]*models.PaperPosition, error) {
+	ids, err := r.client.SMembers(r.ctx, "paper_positions:open").Result()
+	if err != nil {
+		return nil, err
+	}
+	return r.getPaperPositions(ids), nil
+}
+
+// getPaperPositions 依 ID 獲取模擬部位，不存在的略過
+func (r *RedisRepository) getPaperPositions(ids []string) []*models.PaperPosition {
+	positions := make([]*models.PaperPosition, 0, len(ids))
+	for _, id := range ids {
+		position, err := r.GetPaperPosition(id)
+		if err != nil {
+			continue
+		}
+		positions = append(positions, position)
+	}
+	return positions
+}
+
+// ==================== 警報歷史相關方法 ====================
+
+// SaveAlertEvent 儲存警報觸發紀錄，並加入用戶與警報索引
+// retention 為保留期間，索引中超過保留期間的紀錄一併移除
+func (r *RedisRepository) SaveAlertEvent(event *models.AlertEvent, retention time.Duration) error {
+	if err := r.UpdateAlertEvent(event, retention); err != nil {
+		return err
+	}
+
+	member := redis.Z{Score: float64(event.TriggeredAt.UnixMilli()), Member: event.EventID}
+	expired := strconv.FormatInt(time.Now().Add(-retention).UnixMilli(), 10)
+	for _, key := range []string{"alert_events:user:" + event.UserID, "alert_events:alert:" + event.AlertID} {
+		if err := r.client.ZAdd(r.ctx, key, member).Err(); err != nil {
+			return err
+		}
+		if err := r.client.ZRemRangeByScore(r.ctx, key, "-inf", "("+expired).Err(); err != nil {
+			return err
+		}
+		if err := r.client.Expire(r.ctx, key, retention).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateAlertEvent 更新警報觸發紀錄，保留期間從觸發時間起算
+func (r *RedisRepository) UpdateAlertEvent(event *models.AlertEvent, retention time.Duration) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(event.TriggeredAt.Add(retention))
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	return r.client.Set(r.ctx, "alert_event:"+event.EventID, data, ttl).Err()
+}
+
+// GetAlertEvent 獲取單筆警報觸發紀錄
+func (r *RedisRepository) GetAlertEvent(eventID string) (*models.AlertEvent, error) {
+	data, err := r.client.Get(r.ctx, "alert_event:"+eventID).Result()
+	if err != nil {
+		return nil, err
+	}
+	var event models.AlertEvent
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// HasAlertEvents 警報是否有屬於該用戶的觸發紀錄：以警報索引中最新一筆是否也在用戶索引中判斷
+// 警報沒有任何紀錄時返回 false
+func (r *RedisRepository) HasAlertEvents(alertID, userID string) (bool, error) {
+	ids, err := r.client.ZRevRange(r.ctx, "alert_events:alert:"+alertID, 0, 0).Result()
+	if err != nil {
+		return false, err
+	}
+	if len(ids) == 0 {
+		return false, nil
+	}
+	err = r.client.ZScore(r.ctx, "alert_events:user:"+userID, ids[0]).Err()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// QueryAlertEvents 依觸發時間查詢警報紀錄（新到舊），返回該頁紀錄與符合條件的總筆數
+func (r *RedisRepository) QueryAlertEvents(query *models.AlertHistoryQuery) ([]*models.AlertEvent, int64, error) {
+	key := "alert_events:user:" + query.UserID
+	if query.AlertID != "" {
+		key = "alert_events:alert:" + query.AlertID
+	}
+
+	minScore, maxScore := "-inf", "+inf"
+	if !query.From.IsZero() {
+		minScore = strconv.FormatInt(query.From.UnixMilli(), 10)
+	}
+	if !query.To.IsZero() {
+		maxScore = "(" + strconv.FormatInt(query.To.UnixMilli(), 10)
+	}
+
+	total, err := r.client.ZCount(r.ctx, key, minScore, maxScore).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	ids, err := r.client.ZRevRangeByScore(r.ctx, key, &redis.ZRangeBy{
+		Min:    minScore,
+		Max:    maxScore,
+		Offset: int64(query.Offset),
+		Count:  int64(query.Limit),
+	}).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	events := make([]*models.AlertEvent, 0, len(ids))
+	for _, id := range ids {
+		event, err := r.GetAlertEvent(id)
+		if err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, total, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"cryptowatch/internal/models"
+	"cryptowatch/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// AlertHistoryService 警報觸發紀錄服務
+type AlertHistoryService struct {
+	repo      *repository.RedisRepository
+	retention time.Duration
+}
+
+// NewAlertHistoryService 創建警報觸發紀錄服務，retentionDays 為紀錄保留天數
+func NewAlertHistoryService(repo *repository.RedisRepository, retentionDays int) *AlertHistoryService {
+	return &AlertHistoryService{
+		repo:      repo,
+		retention: time.Duration(retentionDays) * 24 * time.Hour,
+	}
+}
+
+// RecordTrigger 記錄一次警報觸發，通知對象先記為未送達
+// 價格取 Redis 中的最新價格；成交量警報記錄視窗成交量，其他警報記錄 24h 成交量
+func (s *AlertHistoryService) RecordTrigger(alert *models.Alert) (*models.AlertEvent, error) {
+	event := &models.AlertEvent{
+		EventID:      uuid.New().String(),
+		AlertID:      alert.AlertID,
+		UserID:       alert.UserID,
+		Symbol:       alert.Symbol,
+		AlertType:    alert.AlertType,
+		Direction:    alert.Direction,
+		TargetPrice:  alert.TargetPrice,
+		TargetVolume: alert.TargetVolume,
+		Value:        alert.TriggerValue,
+		TriggerCount: alert.TriggerCount,
+		TriggeredAt:  *alert.TriggeredAt,
+		Deliveries:   make([]models.DeliveryResult, len(alert.Targets)),
+	}
+	if price, err := s.repo.GetPrice(alert.Symbol); err == nil {
+		event.Price = price.Price
+		event.Volume = price.Volume
+	}
+	if alert.AlertType == "volume" {
+		event.Volume = alert.TriggerValue
+	}
+	for i, target := range alert.Targets {
+		event.Deliveries[i] = models.DeliveryResult{Channel: target.Channel, ChatID: target.ChatID}
+	}
+
+	if err := s.repo.SaveAlertEvent(event, s.retention); err != nil {
+		return nil, fmt.Errorf("error saving alert event: %v", err)
+	}
+	return event, nil
+}
+
+// RecordDelivery 更新觸發紀錄中各通知對象的送達結果
+// errs 與 alert.Targets 對應，只包含本次嘗試送出的對象；completed 表示通知流程已結束
+func (s *AlertHistoryService) RecordDelivery(alert *models.Alert, errs map[int]error, completed bool) error {
+	if alert.EventID == "" {
+		return nil
+	}
+	event, err := s.repo.GetAlertEvent(alert.EventID)
+	if err != nil {
+		return fmt.Errorf("error getting alert event: %v", err)
+	}
+
+	now := time.Now()
+	for i, sendErr := range errs {
+		if i >= len(event.Deliveries) {
+			continue
+		}
+		result := &event.Deliveries[i]
+		result.Attempts++
+		if sendErr != nil {
+			result.Error = sendErr.Error()
+			continue
+		}
+		result.Delivered = true
+		result.Error = ""
+		result.DeliveredAt = &now
+	}
+	if completed {
+		event.CompletedAt = &now
+	}
+
+	if err := s.repo.UpdateAlertEvent(event, s.retention); err != nil {
+		return fmt.Errorf("error updating alert event: %v", err)
+	}
+	return nil
+}
+
+// GetHistory 查詢用戶的警報觸發紀錄，可限定單一警報與觸發時間範圍
+func (s *AlertHistoryService) GetHistory(query *models.AlertHistoryQuery) (*models.AlertHistoryPage, error) {
+	if query.Limit == 0 {
+		query.Limit = models.DefaultAlertHistoryLimit
+	}
+	if query.Limit < 1 || query.Limit > models.MaxAlertHistoryLimit {
+		return nil, &models.ValidationError{Field: "limit", Message: fmt.Sprintf("必須介於 1 到 %d 之間", models.MaxAlertHistoryLimit)}
+	}
+	if query.Offset < 0 {
+		return nil, &models.ValidationError{Field: "offset", Message: "不能為負數"}
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return nil, &models.ValidationError{Field: "from", Message: "必須早於 to"}
+	}
+
+	page := &models.AlertHistoryPage{Events: []*models.AlertEvent{}, Limit: query.Limit, Offset: query.Offset}
+
+	// 先確認警報的紀錄屬於該用戶，屬於其他用戶或沒有紀錄時返回空頁，不透露總筆數
+	if query.AlertID != "" {
+		owned, err := s.repo.HasAlertEvents(query.AlertID, query.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("error checking alert event owner: %v", err)
+		}
+		if !owned {
+			return page, nil
+		}
+	}
+
+	events, total, err := s.repo.QueryAlertEvents(query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying alert events: %v", err)
+	}
+	page.Events, page.Total = events, total
+	return page, nil
+}
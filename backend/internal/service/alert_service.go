@@ -1,19 +1,26 @@
 package service
 
 import (
+	"fmt"
+	"slices"
 	"time"
 
+	"cryptowatch/internal/expr"
 	"cryptowatch/internal/models"
 	"cryptowatch/internal/repository"
 	"github.com/google/uuid"
 )
 
 type AlertService struct {
-	repo *repository.RedisRepository
+	repo                *repository.RedisRepository
+	priceService        *PriceService
+	levelService        *LevelService
+	profileService      *VolumeProfileService
+	notificationService *NotificationService
 }
 
-func NewAlertService(repo *repository.RedisRepository) *AlertService {
-	return &AlertService{repo: repo}
+func NewAlertService(repo *repository.RedisRepository, priceService *PriceService, levelService *LevelService, profileService *VolumeProfileService, notificationService *NotificationService) *AlertService {
+	return &AlertService{repo: repo, priceService: priceService, levelService: levelService, profileService: profileService, notificationService: notificationService}
 }
 
 func (s *AlertService) CreateAlert(req *models.CreateAlertRequest) (*models.Alert, error) {
@@ -26,8 +33,83 @@ func (s *AlertService) CreateAlert(req *models.CreateAlertRequest) (*models.Aler
 		Direction:    req.Direction,
 		TargetVolume: req.TargetVolume,
 		TimeWindow:   req.TimeWindow,
+		MovePct:      req.MovePct,
+		VelocityPct:  req.VelocityPct,
 		CreatedAt:    time.Now(),
 	}
+
+	// 視窗與門檻
+	if err := validateWindow(alert); err != nil {
+		return nil, err
+	}
+
+	// 通知對象：新警報一律從未送達開始
+	if err := s.notificationService.ValidateTargets(req.Targets); err != nil {
+		return nil, err
+	}
+	for _, target := range req.Targets {
+		target.Delivered = false
+		alert.Targets = append(alert.Targets, target)
+	}
+
+	// 重複觸發、到期時間與生效時段
+	if err := applyRepeat(alert, req); err != nil {
+		return nil, err
+	}
+
+	// 記錄建立時的價格，之後價格實際穿越目標才觸發
+	if price, err := s.repo.GetPrice(alert.Symbol); err == nil {
+		alert.LastPrice = price.Price
+		alert.LastCheckedAt = &alert.CreatedAt
+	}
+
+	// 支撐壓力位警報：目標價由最近的支撐壓力區決定
+	if alert.AlertType == models.AlertTypeLevel {
+		alert.LevelInterval = req.LevelInterval
+		if alert.LevelInterval == "" {
+			alert.LevelInterval = "4h"
+		}
+		levels, err := s.levelService.GetLevels(alert.Symbol, alert.LevelInterval)
+		if err != nil {
+			return nil, err
+		}
+		price, err := s.levelService.CurrentPrice(alert.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.levelService.ResolveAlertTarget(alert, levels, price); err != nil {
+			return nil, err
+		}
+	}
+
+	// 價值區警報：驗證成交量分佈參數並預先計算
+	if alert.AlertType == models.AlertTypeValueArea {
+		if alert.Direction != "" && alert.Direction != "above" && alert.Direction != "below" {
+			return nil, &models.ValidationError{Field: "direction", Message: "價值區警報的 direction 必須是 above、below 或留空"}
+		}
+		alert.ProfileInterval = req.ProfileInterval
+		if alert.ProfileInterval == "" {
+			alert.ProfileInterval = "1h"
+		}
+		alert.ProfileLookback = req.ProfileLookback
+		if alert.ProfileLookback == 0 {
+			alert.ProfileLookback = models.DefaultProfileLookback
+		}
+		if alert.ProfileLookback < 0 {
+			return nil, &models.ValidationError{Field: "profileLookback", Message: "不能為負數"}
+		}
+		if _, err := s.profileService.GetVolumeProfile(alert.Symbol, alert.ProfileInterval, alert.ProfileLookback); err != nil {
+			return nil, err
+		}
+	}
+
+	// 追蹤停損警報：驗證回撤方式，並以目前價格作為起始極值
+	if alert.AlertType == models.AlertTypeTrailingStop {
+		if err := s.applyTrailingStop(alert, req); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.repo.SaveAlert(alert); err != nil {
 		return nil, err
 	}
@@ -42,3 +124,153 @@ func (s *AlertService) DeleteAlert(alertID string) error {
 	return s.repo.DeleteAlert(alertID)
 }
 
+// applyRepeat 驗證並套用重複觸發、到期與生效時段設定
+func applyRepeat(alert *models.Alert, req *models.CreateAlertRequest) error {
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			return &models.ValidationError{Field: "expiresAt", Message: "必須是未來的時間"}
+		}
+		alert.ExpiresAt = req.ExpiresAt
+	}
+
+	if req.Schedule != nil {
+		if err := req.Schedule.Validate(); err != nil {
+			return err
+		}
+		alert.Schedule = req.Schedule
+	}
+
+	if !req.Repeat {
+		if req.MaxTriggers != 0 || req.RearmPct != 0 {
+			return &models.ValidationError{Field: "repeat", Message: "maxTriggers 與 rearmPct 只能用於重複警報"}
+		}
+		return nil
+	}
+
+	alert.Repeat = true
+	alert.RearmPct = req.RearmPct
+	if alert.RearmPct == 0 {
+		alert.RearmPct = models.DefaultRearmPct
+	}
+	if alert.RearmPct < 0 || alert.RearmPct > models.MaxRearmPct {
+		return &models.ValidationError{Field: "rearmPct", Message: fmt.Sprintf("必須介於 0 到 %g 之間", models.MaxRearmPct)}
+	}
+	if req.MaxTriggers < 0 {
+		return &models.ValidationError{Field: "maxTriggers", Message: "不能為負數"}
+	}
+	alert.MaxTriggers = req.MaxTriggers
+	return nil
+}
+
+// applyTrailingStop 驗證並套用追蹤停損設定，以目前價格作為起始極值
+func (s *AlertService) applyTrailingStop(alert *models.Alert, req *models.CreateAlertRequest) error {
+	if alert.Direction == "" {
+		alert.Direction = models.TrailDirectionLong
+	}
+	if alert.Direction != models.TrailDirectionLong && alert.Direction != models.TrailDirectionShort {
+		return &models.ValidationError{Field: "direction", Message: "追蹤停損警報的 direction 必須是 long 或 short"}
+	}
+	if alert.Repeat {
+		return &models.ValidationError{Field: "repeat", Message: "追蹤停損警報不支援重複觸發"}
+	}
+
+	alert.TrailMode = req.TrailMode
+	if alert.TrailMode == "" {
+		alert.TrailMode = models.TrailModePercent
+	}
+	var atr float64
+	switch alert.TrailMode {
+	case models.TrailModeAmount:
+		if req.TrailAmount <= 0 {
+			return &models.ValidationError{Field: "trailAmount", Message: "必須大於 0"}
+		}
+		alert.TrailAmount = req.TrailAmount
+	case models.TrailModePercent:
+		if req.TrailPct <= 0 || req.TrailPct > models.MaxTrailPct {
+			return &models.ValidationError{Field: "trailPct", Message: fmt.Sprintf("必須介於 0 到 %g 之間", models.MaxTrailPct)}
+		}
+		alert.TrailPct = req.TrailPct
+	case models.TrailModeATR:
+		if req.TrailATRMultiple <= 0 || req.TrailATRMultiple > models.MaxTrailATRMultiple {
+			return &models.ValidationError{Field: "trailAtrMultiple", Message: fmt.Sprintf("必須介於 0 到 %g 之間", models.MaxTrailATRMultiple)}
+		}
+		alert.TrailATRMultiple = req.TrailATRMultiple
+		alert.TrailATRInterval = req.TrailATRInterval
+		if alert.TrailATRInterval == "" {
+			alert.TrailATRInterval = models.DefaultTrailATRInterval
+		}
+		if !expr.ValidInterval(alert.TrailATRInterval) {
+			return &models.ValidationError{Field: "trailAtrInterval", Message: fmt.Sprintf("無效的 K 線週期 %q", alert.TrailATRInterval)}
+		}
+		alert.TrailATRPeriod = req.TrailATRPeriod
+		if alert.TrailATRPeriod == 0 {
+			alert.TrailATRPeriod = models.DefaultTrailATRPeriod
+		}
+		if alert.TrailATRPeriod < 1 || alert.TrailATRPeriod > models.MaxTrailATRPeriod {
+			return &models.ValidationError{Field: "trailAtrPeriod", Message: fmt.Sprintf("必須介於 1 到 %d 之間", models.MaxTrailATRPeriod)}
+		}
+		var err error
+		if atr, err = s.priceService.FetchATR(alert.Symbol, alert.TrailATRInterval, alert.TrailATRPeriod); err != nil {
+			return err
+		}
+	default:
+		return &models.ValidationError{Field: "trailMode", Message: fmt.Sprintf("不支援的回撤方式 %q", alert.TrailMode)}
+	}
+
+	price, err := s.priceService.FetchCurrentPrice(alert.Symbol)
+	if err != nil {
+		return fmt.Errorf("error fetching current price: %v", err)
+	}
+	alert.TrailExtreme = price
+	alert.TrailStopPrice = alert.TrailStop(atr)
+	if alert.TrailStopPrice <= 0 {
+		return &models.ValidationError{Field: trailDistanceField(alert.TrailMode), Message: "回撤距離不能大於目前價格"}
+	}
+	return nil
+}
+
+// trailDistanceField 回撤方式對應的回撤距離欄位
+func trailDistanceField(mode string) string {
+	switch mode {
+	case models.TrailModePercent:
+		return "trailPct"
+	case models.TrailModeATR:
+		return "trailAtrMultiple"
+	default:
+		return "trailAmount"
+	}
+}
+
+// validateWindow 驗證成交量、漲跌幅與速度警報的視窗與門檻
+func validateWindow(alert *models.Alert) error {
+	switch alert.AlertType {
+	case "volume":
+		if alert.TimeWindow != 0 && !slices.Contains(models.VolumeAlertWindows, alert.TimeWindow) {
+			return &models.ValidationError{Field: "timeWindow", Message: fmt.Sprintf("成交量警報的視窗必須是 %v 分鐘之一", models.VolumeAlertWindows)}
+		}
+	case models.AlertTypePercentMove:
+		if alert.MovePct <= 0 || alert.MovePct > models.MaxMovePct {
+			return &models.ValidationError{Field: "movePct", Message: fmt.Sprintf("必須介於 0 到 %g 之間", models.MaxMovePct)}
+		}
+		if alert.TimeWindow < 1 || alert.TimeWindow > models.MaxMoveWindow {
+			return &models.ValidationError{Field: "timeWindow", Message: fmt.Sprintf("漲跌幅警報的視窗必須介於 1 到 %d 分鐘", models.MaxMoveWindow)}
+		}
+	case models.AlertTypeVelocity:
+		if alert.VelocityPct <= 0 || alert.VelocityPct > models.MaxVelocityPct {
+			return &models.ValidationError{Field: "velocityPct", Message: fmt.Sprintf("必須介於 0 到 %g 之間", models.MaxVelocityPct)}
+		}
+		if alert.TimeWindow == 0 {
+			alert.TimeWindow = 1
+		}
+		if alert.TimeWindow < 1 || alert.TimeWindow > models.MaxVelocityWindow {
+			return &models.ValidationError{Field: "timeWindow", Message: fmt.Sprintf("速度警報的視窗必須介於 1 到 %d 分鐘", models.MaxVelocityWindow)}
+		}
+	default:
+		return nil
+	}
+
+	if alert.AlertType != "volume" && alert.Direction != "" && alert.Direction != "up" && alert.Direction != "down" {
+		return &models.ValidationError{Field: "direction", Message: "漲跌幅與速度警報的 direction 必須是 up、down 或留空"}
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"cryptowatch/internal/expr"
+	"cryptowatch/internal/indicators"
+	"cryptowatch/internal/models"
+)
+
+// BacktestService 指標訂閱回測服務
+type BacktestService struct {
+	priceService *PriceService
+}
+
+// NewBacktestService 創建回測服務
+func NewBacktestService(priceService *PriceService) *BacktestService {
+	return &BacktestService{priceService: priceService}
+}
+
+// volumeFunc 獲取訊號 K 線收盤前的 1 分 K 成交量統計
+type volumeFunc func(symbol string, closeTime time.Time, avgPeriod int) (indicators.VolumeResult, error)
+
+// Run 以歷史 K 線逐根重播 LRC 突破訂閱的判斷邏輯
+// 每根 K 線收盤時依序檢查突破方向、通道品質、波動收斂、冷卻時間與成交量條件，與 IndicatorMonitor 相同
+// intrabar 模式無法重播盤中價格，以 K 線收盤價近似；成交量條件以訊號 K 線收盤前的 1 分 K 計算，不做季節性調整
+func (s *BacktestService) Run(req *models.BacktestRequest) (*models.BacktestResult, error) {
+	req.ApplyDefaults()
+	if !expr.ValidInterval(req.Interval) {
+		return nil, &models.ValidationError{Field: "interval", Message: fmt.Sprintf("無效的 K 線週期 %q", req.Interval)}
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	sub := req.Subscription()
+	if err := validateSubscription(sub); err != nil {
+		return nil, err
+	}
+
+	start, err := backtestStart(req.Interval, req.Start, req.End, req.Bars)
+	if err != nil {
+		return nil, err
+	}
+	klines, startIdx, err := s.loadKlines(req.Symbol, req.Interval, start, req.End, backtestWarmup(req.LRCLength, sub))
+	if err != nil {
+		return nil, err
+	}
+	return replayBacktest(req, sub, klines, startIdx, s.volumeStats)
+}
+
+// backtestStart 回測開始時間，未指定時取結束時間往前 bars 根，並檢查範圍上限
+func backtestStart(interval string, start, end time.Time, bars int) (time.Time, error) {
+	d, err := IntervalDuration(interval)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if start.IsZero() {
+		start = end.Add(-time.Duration(bars) * d)
+	}
+	if int(end.Sub(start)/d) > models.MaxBacktestBars {
+		return time.Time{}, &models.ValidationError{Field: "start", Message: fmt.Sprintf("回測範圍不能超過 %d 根 K 線", models.MaxBacktestBars)}
+	}
+	return start, nil
+}
+
+// backtestWarmup 回測開始前需要的暖機 K 線根數：LRC、收盤確認與波動率歷史
+func backtestWarmup(lrcLength int, sub *models.IndicatorSubscription) int {
+	warmup := lrcLength + models.MaxConfirmCloses
+	if sub.RequireCompressedBreakout {
+		config := indicators.DefaultVolatilityConfig()
+		warmup = max(warmup, config.Window+config.History)
+	}
+	return warmup
+}
+
+// loadKlines 獲取回測範圍與暖機資料的已收盤 K 線，startIdx 為回測開始的 K 線索引
+func (s *BacktestService) loadKlines(symbol, interval string, start, end time.Time, warmup int) (klines []KlineData, startIdx int, err error) {
+	d, err := IntervalDuration(interval)
+	if err != nil {
+		return nil, 0, err
+	}
+	klines, err = s.priceService.FetchKlinesRange(symbol, interval, start.Add(-time.Duration(warmup)*d), end)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error fetching klines: %v", err)
+	}
+	if n := len(klines); n > 0 && !IsKlineClosed(klines[n-1], time.Now()) {
+		klines = klines[:n-1]
+	}
+	for startIdx < len(klines) && klines[startIdx].OpenTime < start.UnixMilli() {
+		startIdx++
+	}
+	return klines, startIdx, nil
+}
+
+// volumeStats 即時抓取訊號 K 線收盤前的 1 分 K 成交量統計
+func (s *BacktestService) volumeStats(symbol string, closeTime time.Time, avgPeriod int) (indicators.VolumeResult, error) {
+	klines, err := s.priceService.FetchKlinesBefore(symbol, "1m", closeTime, avgPeriod+5)
+	if err != nil {
+		return indicators.VolumeResult{}, fmt.Errorf("error fetching volume klines: %v", err)
+	}
+	return BuildVolumeStats(klines, avgPeriod), nil
+}
+
+// replayBacktest 從 startIdx 開始逐根重播訂閱的判斷邏輯
+func replayBacktest(req *models.BacktestRequest, sub *models.IndicatorSubscription, klines []KlineData, startIdx int, volume volumeFunc) (*models.BacktestResult, error) {
+	first := max(startIdx, req.LRCLength-1)
+	if first >= len(klines) {
+		return nil, &models.ValidationError{Field: "start", Message: "回測範圍內沒有足夠的 K 線"}
+	}
+
+	result := &models.BacktestResult{
+		Symbol:           req.Symbol,
+		Interval:         req.Interval,
+		LRCLength:        req.LRCLength,
+		LRCDevMultiplier: req.LRCDevMultiplier,
+		Start:            time.UnixMilli(klines[first].OpenTime),
+		End:              time.UnixMilli(klines[len(klines)-1].CloseTime + 1),
+		BarsEvaluated:    len(klines) - first,
+		Signals:          []models.BacktestSignal{},
+	}
+
+	volatilityConfig := indicators.DefaultVolatilityConfig()
+	closes := GetClosePrices(klines)
+	cooldown := time.Duration(sub.NotifyIntervalMin) * time.Minute
+	var lastSignal time.Time
+	for i := first; i < len(klines); i++ {
+		lrc, err := indicators.CalculateLRC(closes[:i+1], req.LRCLength, req.LRCDevMultiplier)
+		if err != nil {
+			continue
+		}
+		closesAbove, closesBelow := indicators.CountClosesBeyondBands(closes[:i+1], req.LRCLength, req.LRCDevMultiplier, models.MaxConfirmCloses)
+		indicator := &models.IndicatorResult{
+			Symbol:           req.Symbol,
+			UpperBand:        lrc.UpperBand,
+			LowerBand:        lrc.LowerBand,
+			CenterLine:       lrc.CenterLine,
+			Slope:            lrc.Slope,
+			Deviation:        lrc.Deviation,
+			PearsonR:         lrc.PearsonR,
+			SlopePct:         lrc.SlopePct,
+			CurrentPrice:     closes[i],
+			IsAboveUpper:     closes[i] > lrc.UpperBand,
+			IsBelowLower:     closes[i] < lrc.LowerBand,
+			ClosesAboveUpper: closesAbove,
+			ClosesBelowLower: closesBelow,
+		}
+
+		direction, triggered := sub.TriggerDirection(indicator)
+		if !triggered || !sub.PassesChannelFilter(indicator, direction) {
+			continue
+		}
+		if sub.RequireCompressedBreakout {
+			indicator.Volatility, _ = BuildVolatility(klines[:i+1], req.Interval, volatilityConfig)
+			if !sub.PassesVolatilityFilter(indicator) {
+				continue
+			}
+		}
+
+		closeTime := time.UnixMilli(klines[i].CloseTime + 1)
+		if !lastSignal.IsZero() && closeTime.Sub(lastSignal) < cooldown {
+			result.SkippedByCooldown++
+			continue
+		}
+
+		if sub.EnableVolumeCheck {
+			stats, err := volume(req.Symbol, closeTime, sub.VolumeAvgPeriod)
+			if err != nil {
+				return nil, err
+			}
+			if !indicators.CheckVolumeCondition(stats, SubscriptionVolumeConfig(sub)) {
+				result.SkippedByVolume++
+				continue
+			}
+			indicator.VolumeRatio = stats.VolumeRatio
+		}
+
+		lastSignal = closeTime
+		result.Signals = append(result.Signals, backtestSignal(klines, i, direction, indicator, req.Horizons))
+	}
+
+	summarizeBacktest(result, req.Horizons)
+	return result, nil
+}
+
+// backtestSignal 建立訊號並計算前瞻報酬與最大不利偏移
+func backtestSignal(klines []KlineData, i int, direction string, indicator *models.IndicatorResult, horizons []int) models.BacktestSignal {
+	entry := klines[i].Close
+	side := 1.0
+	if direction == models.SignalBelowLower {
+		side = -1
+	}
+
+	signal := models.BacktestSignal{
+		Time:        time.UnixMilli(klines[i].CloseTime + 1),
+		Direction:   direction,
+		Price:       entry,
+		UpperBand:   indicator.UpperBand,
+		LowerBand:   indicator.LowerBand,
+		PearsonR:    indicator.PearsonR,
+		VolumeRatio: indicator.VolumeRatio,
+		Returns:     []models.ForwardReturn{},
+	}
+	for _, h := range horizons {
+		if i+h >= len(klines) {
+			continue
+		}
+		signal.Returns = append(signal.Returns, models.ForwardReturn{
+			Bars:      h,
+			ReturnPct: side * (klines[i+h].Close/entry - 1) * 100,
+		})
+	}
+
+	// 最大不利偏移：做多看最低價，做空看最高價
+	last := min(i+slices.Max(horizons), len(klines)-1)
+	for j := i + 1; j <= last; j++ {
+		adverse := (entry - klines[j].Low) / entry * 100
+		if side < 0 {
+			adverse = (klines[j].High - entry) / entry * 100
+		}
+		signal.MAEPct = math.Max(signal.MAEPct, adverse)
+	}
+	return signal
+}
+
+// summarizeBacktest 統計各前瞻期間的勝率、平均與中位數報酬，以及最大不利偏移
+func summarizeBacktest(result *models.BacktestResult, horizons []int) {
+	result.Horizons = make([]models.HorizonStats, 0, len(horizons))
+	for _, h := range horizons {
+		stats := models.HorizonStats{Bars: h}
+		returns := horizonReturns(result.Signals, h)
+		if len(returns) > 0 {
+			slices.Sort(returns)
+			stats.Signals = len(returns)
+			stats.HitRate, stats.AvgReturnPct = hitRateAndMean(returns)
+			stats.MedianReturnPct = returns[len(returns)/2]
+			if len(returns)%2 == 0 {
+				stats.MedianReturnPct = (returns[len(returns)/2-1] + returns[len(returns)/2]) / 2
+			}
+		}
+		result.Horizons = append(result.Horizons, stats)
+	}
+
+	for _, signal := range result.Signals {
+		result.AvgMAE += signal.MAEPct
+		result.MaxMAE = math.Max(result.MaxMAE, signal.MAEPct)
+	}
+	if len(result.Signals) > 0 {
+		result.AvgMAE /= float64(len(result.Signals))
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"cryptowatch/internal/expr"
+	"cryptowatch/internal/indicators"
+	"cryptowatch/internal/models"
+	"cryptowatch/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// CorrelationService 幣種相關係數服務
+type CorrelationService struct {
+	repo         *repository.RedisRepository
+	priceService *PriceService
+}
+
+// NewCorrelationService 創建相關係數服務
+func NewCorrelationService(repo *repository.RedisRepository, priceService *PriceService) *CorrelationService {
+	return &CorrelationService{
+		repo:         repo,
+		priceService: priceService,
+	}
+}
+
+// GetCorrelation 獲取監控幣種的報酬率相關矩陣與相對 BTC 的 Beta，快取到下一根 K 線收盤
+func (s *CorrelationService) GetCorrelation(interval string, window int) (*models.CorrelationMatrix, error) {
+	if !expr.ValidInterval(interval) {
+		return nil, &models.ValidationError{Field: "interval", Message: fmt.Sprintf("無效的 K 線週期 %q", interval)}
+	}
+	if window <= 0 {
+		window = models.DefaultCorrelationWindow
+	}
+	if window < 10 || window > models.MaxCorrelationWindow {
+		return nil, &models.ValidationError{Field: "window", Message: fmt.Sprintf("必須介於 10 到 %d 之間", models.MaxCorrelationWindow)}
+	}
+
+	if cached, err := s.repo.GetCorrelation(interval, window); err == nil {
+		return cached, nil
+	}
+
+	// 所有幣種都以最近一根已收盤的 K 線為結尾，報酬率序列可直接以尾端對齊
+	now := time.Now()
+	returns := make(map[string][]float64)
+	symbols := make([]string, 0, len(s.priceService.GetSymbols()))
+	for _, symbol := range s.priceService.GetSymbols() {
+		// 多取一根未收盤 K 線與一根計算第一筆報酬率
+		klines, err := s.priceService.FetchKlines(symbol, interval, window+2)
+		if err != nil {
+			if symbol == models.BenchmarkSymbol {
+				return nil, fmt.Errorf("error fetching %s klines: %v", symbol, err)
+			}
+			log.Warn().Err(err).Str("symbol", symbol).Msg("Error fetching klines for correlation")
+			continue
+		}
+		if n := len(klines); n > 0 && !IsKlineClosed(klines[n-1], now) {
+			klines = klines[:n-1]
+		}
+		if len(klines) > window+1 {
+			klines = klines[len(klines)-window-1:]
+		}
+		if len(klines) < 3 {
+			continue
+		}
+		returns[symbol] = indicators.LogReturns(GetClosePrices(klines))
+		symbols = append(symbols, symbol)
+	}
+
+	btc, ok := returns[models.BenchmarkSymbol]
+	if !ok {
+		return nil, fmt.Errorf("not enough %s klines for correlation", models.BenchmarkSymbol)
+	}
+
+	next, err := NextCandleClose(interval, now)
+	if err != nil {
+		return nil, err
+	}
+
+	matrix := &models.CorrelationMatrix{
+		Interval:      interval,
+		Window:        window,
+		Symbols:       symbols,
+		Matrix:        make([][]float64, len(symbols)),
+		Stats:         make([]models.SymbolCorrelation, 0, len(symbols)),
+		UpdatedAt:     now,
+		NextRefreshAt: next,
+	}
+	for i, a := range symbols {
+		matrix.Matrix[i] = make([]float64, len(symbols))
+		for j, b := range symbols {
+			switch {
+			case i == j:
+				matrix.Matrix[i][j] = 1
+			case j < i:
+				matrix.Matrix[i][j] = matrix.Matrix[j][i]
+			default:
+				matrix.Matrix[i][j] = indicators.Correlation(returns[a], returns[b])
+			}
+		}
+
+		beta := indicators.Beta(returns[a], btc)
+		last := indicators.Last(returns[a])
+		matrix.Stats = append(matrix.Stats, models.SymbolCorrelation{
+			Symbol:         a,
+			BTCCorrelation: indicators.Correlation(returns[a], btc),
+			Beta:           beta,
+			Return:         last,
+			Residual:       last - beta*indicators.Last(btc),
+		})
+	}
+
+	if err := s.repo.SetCorrelation(matrix); err != nil {
+		return nil, err
+	}
+	return matrix, nil
+}
+
+// IsBTCDriven 幣種觸發 K 線是否只是跟隨 BTC 波動
+// live 為 true 時（盤中觸發）以未收盤 K 線開盤至今的報酬判斷，否則使用最近一根已收盤 K 線
+func (s *CorrelationService) IsBTCDriven(symbol, interval string, minCorrelation float64, live bool) (bool, error) {
+	matrix, err := s.GetCorrelation(interval, models.DefaultCorrelationWindow)
+	if err != nil {
+		return false, err
+	}
+	stat, ok := matrix.Stat(symbol)
+	if !ok {
+		return false, nil
+	}
+	if !live {
+		return stat.IsBTCDriven(minCorrelation), nil
+	}
+
+	symbolReturn, err := s.liveReturn(symbol, interval)
+	if err != nil {
+		return false, err
+	}
+	btcReturn, err := s.liveReturn(models.BenchmarkSymbol, interval)
+	if err != nil {
+		return false, err
+	}
+	current := *stat
+	current.Return = symbolReturn
+	current.Residual = symbolReturn - current.Beta*btcReturn
+	return current.IsBTCDriven(minCorrelation), nil
+}
+
+// liveReturn 未收盤 K 線開盤至今的對數報酬率
+func (s *CorrelationService) liveReturn(symbol, interval string) (float64, error) {
+	klines, err := s.priceService.FetchKlines(symbol, interval, 1)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching %s klines: %v", symbol, err)
+	}
+	if len(klines) == 0 || klines[0].Open <= 0 || klines[0].Close <= 0 {
+		return 0, fmt.Errorf("no open %s kline", symbol)
+	}
+	return math.Log(klines[0].Close / klines[0].Open), nil
+}
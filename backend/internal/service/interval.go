@@ -0,0 +1,65 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// IntervalDuration 將 K 線週期字串轉換為時間長度
+// interval: 幣安 K 線週期 (例如 "1m", "4h", "1d", "1w")
+// 注意 "1M"（月線）長度不固定，以 30 天近似
+func IntervalDuration(interval string) (time.Duration, error) {
+	if len(interval) < 2 {
+		return 0, fmt.Errorf("無效的 K 線週期: %q", interval)
+	}
+
+	n, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("無效的 K 線週期: %q", interval)
+	}
+
+	switch interval[len(interval)-1] {
+	case 'm':
+		return time.Duration(n) * time.Minute, nil
+	case 'h':
+		return time.Duration(n) * time.Hour, nil
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour, nil
+	case 'w':
+		return time.Duration(n) * 7 * 24 * time.Hour, nil
+	case 'M':
+		return time.Duration(n) * 30 * 24 * time.Hour, nil
+	default:
+		return 0, fmt.Errorf("無效的 K 線週期: %q", interval)
+	}
+}
+
+// NextCandleClose 計算下一根 K 線的收盤時間
+// 幣安 K 線以 UTC 對齊：分/時/日線對齊 Unix 紀元，週線從週一 00:00 開始，月線從每月 1 日開始
+func NextCandleClose(interval string, now time.Time) (time.Time, error) {
+	now = now.UTC()
+
+	switch interval {
+	case "1M":
+		return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC), nil
+	case "1w":
+		// 1970-01-01 是週四，往後推 4 天對齊到週一
+		monday := time.Date(1970, 1, 5, 0, 0, 0, 0, time.UTC)
+		week := 7 * 24 * time.Hour
+		elapsed := now.Sub(monday)
+		return monday.Add((elapsed/week + 1) * week), nil
+	}
+
+	d, err := IntervalDuration(interval)
+	if err != nil {
+		return time.Time{}, err
+	}
+	step := d.Milliseconds()
+	return time.UnixMilli((now.UnixMilli()/step + 1) * step).UTC(), nil
+}
+
+// IsKlineClosed 判斷 K 線是否已收盤
+func IsKlineClosed(k KlineData, now time.Time) bool {
+	return k.CloseTime < now.UnixMilli()
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"cryptowatch/internal/expr"
+	"cryptowatch/internal/indicators"
+	"cryptowatch/internal/models"
+	"cryptowatch/internal/repository"
+)
+
+// levelLookback 計算支撐壓力位使用的 K 線根數
+const levelLookback = 300
+
+// LevelService 支撐壓力位服務
+type LevelService struct {
+	repo         *repository.RedisRepository
+	priceService *PriceService
+	config       indicators.LevelConfig
+}
+
+// NewLevelService 創建支撐壓力位服務
+func NewLevelService(repo *repository.RedisRepository, priceService *PriceService) *LevelService {
+	return &LevelService{
+		repo:         repo,
+		priceService: priceService,
+		config:       indicators.DefaultLevelConfig(),
+	}
+}
+
+// GetLevels 獲取支撐壓力位，快取過期（已有新 K 線收盤）時重新計算
+func (s *LevelService) GetLevels(symbol, interval string) (*models.SRLevels, error) {
+	levels, _, err := s.RefreshIfDue(symbol, interval)
+	return levels, err
+}
+
+// RefreshIfDue 快取不存在或已有新 K 線收盤時重新計算，refreshed 表示是否重新計算
+func (s *LevelService) RefreshIfDue(symbol, interval string) (levels *models.SRLevels, refreshed bool, err error) {
+	if !expr.ValidInterval(interval) {
+		return nil, false, &models.ValidationError{Field: "interval", Message: fmt.Sprintf("無效的 K 線週期 %q", interval)}
+	}
+
+	cached, err := s.repo.GetSRLevels(symbol, interval)
+	if err == nil && time.Now().Before(cached.NextRefreshAt) {
+		return cached, false, nil
+	}
+
+	levels, err = s.Refresh(symbol, interval)
+	if err != nil {
+		return nil, false, err
+	}
+	return levels, true, nil
+}
+
+// Refresh 以已收盤的 K 線重新計算並儲存支撐壓力位
+func (s *LevelService) Refresh(symbol, interval string) (*models.SRLevels, error) {
+	klines, err := s.priceService.FetchKlines(symbol, interval, levelLookback)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching klines: %v", err)
+	}
+	now := time.Now()
+	if n := len(klines); n > 0 && !IsKlineClosed(klines[n-1], now) {
+		klines = klines[:n-1]
+	}
+
+	price, err := s.priceService.FetchCurrentPrice(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("error getting current price: %v", err)
+	}
+
+	detected := indicators.DetectLevels(GetHighs(klines), GetLows(klines), GetClosePrices(klines), GetVolumes(klines), s.config)
+	levels := &models.SRLevels{
+		Symbol:    symbol,
+		Interval:  interval,
+		Price:     price,
+		Levels:    make([]models.SRLevel, 0, len(detected)),
+		UpdatedAt: now,
+	}
+	for _, l := range detected {
+		kind := models.LevelKindSupport
+		if l.Price > price {
+			kind = models.LevelKindResistance
+		}
+		levels.Levels = append(levels.Levels, models.SRLevel{
+			Price:       l.Price,
+			Lower:       l.Lower,
+			Upper:       l.Upper,
+			Touches:     l.Touches,
+			VolumeShare: l.VolumeShare,
+			Strength:    l.Strength,
+			Kind:        kind,
+		})
+	}
+
+	next, err := NextCandleClose(interval, now)
+	if err != nil {
+		return nil, err
+	}
+	levels.NextRefreshAt = next
+
+	if err := s.repo.SetSRLevels(levels); err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// ResolveAlertTarget 依當前價格為支撐壓力位警報設定目標價
+// direction "above" 對應上方最近的壓力區上緣，"below" 對應下方最近的支撐區下緣
+func (s *LevelService) ResolveAlertTarget(alert *models.Alert, levels *models.SRLevels, price float64) error {
+	var level *models.SRLevel
+	var ok bool
+	switch alert.Direction {
+	case "above":
+		if level, ok = levels.NearestResistance(price); ok {
+			alert.TargetPrice = level.Upper
+		}
+	case "below":
+		if level, ok = levels.NearestSupport(price); ok {
+			alert.TargetPrice = level.Lower
+		}
+	default:
+		return &models.ValidationError{Field: "direction", Message: "支撐壓力位警報的 direction 必須是 above 或 below"}
+	}
+	if !ok {
+		return &models.ValidationError{Field: "direction", Message: fmt.Sprintf("%s %s 週期目前沒有可用的%s", alert.Symbol, alert.LevelInterval, levelSideName(alert.Direction))}
+	}
+	alert.LevelPrice = level.Price
+	return nil
+}
+
+// CurrentPrice 獲取當前價格
+func (s *LevelService) CurrentPrice(symbol string) (float64, error) {
+	return s.priceService.FetchCurrentPrice(symbol)
+}
+
+// levelSideName 方向對應的支撐壓力名稱
+func levelSideName(direction string) string {
+	if direction == "above" {
+		return "壓力位"
+	}
+	return "支撐位"
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"fmt"
+
+	"cryptowatch/internal/models"
+)
+
+// Notifier 通知管道
+type Notifier interface {
+	SendAlert(chatID string, payload models.AlertPayload) error
+}
+
+// NotificationService 依通知對象的管道分派通知
+type NotificationService struct {
+	notifiers map[string]Notifier
+}
+
+// NewNotificationService 創建通知服務
+func NewNotificationService() *NotificationService {
+	return &NotificationService{notifiers: make(map[string]Notifier)}
+}
+
+// Register 註冊通知管道
+func (s *NotificationService) Register(channel string, notifier Notifier) {
+	s.notifiers[channel] = notifier
+}
+
+// ValidateTargets 檢查通知對象的管道已註冊且有收件對象
+func (s *NotificationService) ValidateTargets(targets []models.NotifyTarget) error {
+	for i, target := range targets {
+		if _, ok := s.notifiers[target.Channel]; !ok {
+			return &models.ValidationError{Field: "targets", Message: fmt.Sprintf("第 %d 個通知對象的管道 %q 未設定", i+1, target.Channel)}
+		}
+		if target.ChatID == "" {
+			return &models.ValidationError{Field: "targets", Message: fmt.Sprintf("第 %d 個通知對象缺少 chatId", i+1)}
+		}
+	}
+	return nil
+}
+
+// Send 發送通知給單一對象
+func (s *NotificationService) Send(target models.NotifyTarget, payload models.AlertPayload) error {
+	notifier, ok := s.notifiers[target.Channel]
+	if !ok {
+		return fmt.Errorf("notification channel %q is not configured", target.Channel)
+	}
+	return notifier.SendAlert(target.ChatID, payload)
+}
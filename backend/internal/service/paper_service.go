@@ -0,0 +1,244 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"cryptowatch/internal/models"
+	"cryptowatch/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// PaperService 模擬交易服務
+type PaperService struct {
+	repo         *repository.RedisRepository
+	priceService *PriceService
+}
+
+// NewPaperService 創建模擬交易服務
+func NewPaperService(repo *repository.RedisRepository, priceService *PriceService) *PaperService {
+	return &PaperService{
+		repo:         repo,
+		priceService: priceService,
+	}
+}
+
+// OnSignal 依訂閱的模擬交易設定處理一次通知
+// 同方向已有未平倉部位時不加碼；反向部位先以訊號價格平倉，再開立新部位
+// side 為觸發時判斷的方向，沒有方向（neutral）的訊號不開倉
+// interval 為觸發判斷的 K 線週期，atr 停損以此週期計算
+func (s *PaperService) OnSignal(sub *models.IndicatorSubscription, result *models.IndicatorResult, side, interval string) (*models.PaperPosition, error) {
+	cfg := sub.PaperTrading
+	if !cfg.Enabled || result.CurrentPrice <= 0 {
+		return nil, nil
+	}
+	if side != models.SignalSideLong && side != models.SignalSideShort {
+		log.Debug().Str("subscriptionId", sub.SubscriptionID).Str("side", side).Msg("Skipping paper trade for signal without direction")
+		return nil, nil
+	}
+
+	open, err := s.repo.GetOpenPaperPositions()
+	if err != nil {
+		return nil, fmt.Errorf("error getting open positions: %v", err)
+	}
+	now := time.Now()
+	for _, position := range open {
+		if position.SubscriptionID != sub.SubscriptionID {
+			continue
+		}
+		if position.Side == side {
+			return nil, nil
+		}
+		position.Close(result.CurrentPrice, models.PaperExitReverse, now)
+		if err := s.repo.SavePaperPosition(position); err != nil {
+			return nil, fmt.Errorf("error closing position: %v", err)
+		}
+	}
+
+	position := &models.PaperPosition{
+		PositionID:     uuid.New().String(),
+		UserID:         sub.UserID,
+		SubscriptionID: sub.SubscriptionID,
+		Symbol:         result.Symbol,
+		Side:           side,
+		Status:         models.PaperStatusOpen,
+		SignalPrice:    result.CurrentPrice,
+		SizeUSDT:       cfg.SizeUSDT,
+		FeePct:         cfg.FeePct,
+		SlippagePct:    cfg.SlippagePct,
+		OpenedAt:       now,
+	}
+	d := position.Direction()
+	position.EntryPrice = result.CurrentPrice * (1 + d*cfg.SlippagePct/100)
+	position.Quantity = cfg.SizeUSDT / position.EntryPrice
+	position.Fees = cfg.SizeUSDT * cfg.FeePct / 100
+
+	if position.StopPrice, err = s.stopPrice(&cfg, position, result, interval); err != nil {
+		return nil, err
+	}
+	if d*(position.EntryPrice-position.StopPrice) <= 0 {
+		return nil, fmt.Errorf("stop price %.8g is on the wrong side of entry %.8g", position.StopPrice, position.EntryPrice)
+	}
+
+	switch {
+	case cfg.TakeProfitR > 0:
+		position.TakeProfitPrice = position.EntryPrice + d*cfg.TakeProfitR*math.Abs(position.EntryPrice-position.StopPrice)
+	case cfg.TakeProfitPct > 0:
+		position.TakeProfitPrice = position.EntryPrice * (1 + d*cfg.TakeProfitPct/100)
+	}
+
+	if err := s.repo.SavePaperPosition(position); err != nil {
+		return nil, fmt.Errorf("error saving position: %v", err)
+	}
+	return position, nil
+}
+
+// stopPrice 依停損方式計算停損價
+func (s *PaperService) stopPrice(cfg *models.PaperTradingConfig, position *models.PaperPosition, result *models.IndicatorResult, interval string) (float64, error) {
+	d := position.Direction()
+	switch cfg.StopMode {
+	case models.PaperStopATR:
+		atr, err := s.priceService.FetchATR(position.Symbol, interval, cfg.ATRPeriod)
+		if err != nil {
+			return 0, err
+		}
+		return position.EntryPrice - d*cfg.StopATRMultiple*atr, nil
+	case models.PaperStopPercent:
+		return position.EntryPrice * (1 - d*cfg.StopPct/100), nil
+	default:
+		if d > 0 {
+			return result.LowerBand, nil
+		}
+		return result.UpperBand, nil
+	}
+}
+
+// CheckPositions 以 Redis 中的最新價格檢查所有未平倉部位的停損停利
+func (s *PaperService) CheckPositions() error {
+	open, err := s.repo.GetOpenPaperPositions()
+	if err != nil {
+		return fmt.Errorf("error getting open positions: %v", err)
+	}
+
+	for _, position := range open {
+		price, err := s.repo.GetPrice(position.Symbol)
+		if err != nil {
+			continue
+		}
+		reason := position.ExitReasonAt(price.Price)
+		if reason == "" {
+			continue
+		}
+
+		position.Close(price.Price, reason, time.Now())
+		if err := s.repo.SavePaperPosition(position); err != nil {
+			log.Error().Err(err).Str("positionId", position.PositionID).Msg("Error closing paper position")
+			continue
+		}
+		log.Info().
+			Str("positionId", position.PositionID).
+			Str("symbol", position.Symbol).
+			Str("side", position.Side).
+			Str("reason", reason).
+			Float64("pnl", position.RealizedPnL).
+			Msg("Paper position closed")
+	}
+	return nil
+}
+
+// GetPositions 獲取用戶的模擬部位（新到舊），未平倉部位附上最新價格與未實現損益
+// status 為 "open"、"closed" 或空字串（全部）
+func (s *PaperService) GetPositions(userID, status string) ([]*models.PaperPosition, error) {
+	switch status {
+	case "", models.PaperStatusOpen, models.PaperStatusClosed:
+	default:
+		return nil, &models.ValidationError{Field: "status", Message: fmt.Sprintf("不支援的部位狀態 %q", status)}
+	}
+
+	positions, err := s.userPositions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*models.PaperPosition, 0, len(positions))
+	for _, position := range positions {
+		if status == "" || position.Status == status {
+			filtered = append(filtered, position)
+		}
+	}
+	slices.SortFunc(filtered, func(a, b *models.PaperPosition) int {
+		return b.OpenedAt.Compare(a.OpenedAt)
+	})
+	return filtered, nil
+}
+
+// GetAccount 獲取用戶的模擬交易帳戶摘要與已實現權益曲線
+func (s *PaperService) GetAccount(userID string) (*models.PaperAccount, error) {
+	positions, err := s.userPositions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &models.PaperAccount{
+		UserID:      userID,
+		EquityCurve: []models.EquityPoint{},
+	}
+
+	var closed []*models.PaperPosition
+	for _, position := range positions {
+		if position.Status == models.PaperStatusOpen {
+			account.OpenPositions++
+			account.UnrealizedPnL += position.UnrealizedPnL
+			account.Fees += position.Fees
+			continue
+		}
+		closed = append(closed, position)
+	}
+	slices.SortFunc(closed, func(a, b *models.PaperPosition) int {
+		return a.ClosedAt.Compare(*b.ClosedAt)
+	})
+
+	wins := 0
+	peak := 0.0
+	for _, position := range closed {
+		if position.RealizedPnL > 0 {
+			wins++
+		}
+		account.ClosedPositions++
+		account.RealizedPnL += position.RealizedPnL
+		account.Fees += position.Fees
+		peak = math.Max(peak, account.RealizedPnL)
+		account.MaxDrawdown = math.Max(account.MaxDrawdown, peak-account.RealizedPnL)
+		account.EquityCurve = append(account.EquityCurve, models.EquityPoint{
+			Time:       *position.ClosedAt,
+			Equity:     account.RealizedPnL,
+			PositionID: position.PositionID,
+		})
+	}
+	if account.ClosedPositions > 0 {
+		account.WinRate = float64(wins) / float64(account.ClosedPositions)
+	}
+	account.Equity = account.RealizedPnL + account.UnrealizedPnL
+	return account, nil
+}
+
+// userPositions 獲取用戶的所有部位，未平倉部位以最新價格計算未實現損益
+func (s *PaperService) userPositions(userID string) ([]*models.PaperPosition, error) {
+	positions, err := s.repo.GetUserPaperPositions(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting positions: %v", err)
+	}
+	for _, position := range positions {
+		if position.Status != models.PaperStatusOpen {
+			continue
+		}
+		if price, err := s.repo.GetPrice(position.Symbol); err == nil {
+			position.Mark(price.Price)
+		}
+	}
+	return positions, nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"math"
+	"time"
+)
+
+// PricePoint 某個時間點的價格
+type PricePoint struct {
+	Time  time.Time
+	Price float64
+}
+
+// PercentMove 最近 window 分鐘內的上漲與下跌幅度（%，皆不小於 0）
+// 以 1 分鐘 K 線的最低價與最高價為基準，計算最新價格的漲幅與跌幅
+func PercentMove(klines []KlineData, price float64, now time.Time, window int) (up, down float64) {
+	start := now.Add(-time.Duration(window) * time.Minute).UnixMilli()
+	high, low := price, price
+	for _, k := range klines {
+		if k.CloseTime < start {
+			continue
+		}
+		high = math.Max(high, k.High)
+		low = math.Min(low, k.Low)
+	}
+	if low > 0 {
+		up = (price/low - 1) * 100
+	}
+	if high > 0 {
+		down = (1 - price/high) * 100
+	}
+	return up, down
+}
+
+// Velocity 最近 window 分鐘的平均價格變動速度（%/分鐘）
+// points 依時間排序，以視窗開始前最後一個價格為基準；價格紀錄不足一個視窗時返回 false
+func Velocity(points []PricePoint, now time.Time, window int) (float64, bool) {
+	if len(points) == 0 {
+		return 0, false
+	}
+	start := now.Add(-time.Duration(window) * time.Minute)
+
+	base := -1
+	for i, p := range points {
+		if p.Time.After(start) {
+			break
+		}
+		base = i
+	}
+	if base < 0 {
+		return 0, false
+	}
+
+	last := points[len(points)-1]
+	minutes := last.Time.Sub(points[base].Time).Minutes()
+	if minutes <= 0 || points[base].Price <= 0 {
+		return 0, false
+	}
+	return (last.Price/points[base].Price - 1) * 100 / minutes, true
+}
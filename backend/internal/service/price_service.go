@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"strconv"
 	"time"
 
+	"cryptowatch/internal/indicators"
 	"cryptowatch/internal/models"
 	"cryptowatch/internal/repository"
 )
@@ -29,6 +31,11 @@ type KlineData struct {
 	Close     float64
 	Volume    float64
 	CloseTime int64
+
+	QuoteVolume         float64 // 成交額（USDT）
+	TradeCount          int64   // 成交筆數
+	TakerBuyBaseVolume  float64 // 主動買入成交量
+	TakerBuyQuoteVolume float64 // 主動買入成交額（USDT）
 }
 
 type PriceService struct {
@@ -177,7 +184,48 @@ func (s *PriceService) FetchKlines(symbol string, interval string, limit int) ([
 	pair := symbol + "USDT"
 	baseURL := s.getBaseURL()
 	url := fmt.Sprintf("%s/klines?symbol=%s&interval=%s&limit=%d", baseURL, pair, interval, limit)
+	return s.fetchKlines(url)
+}
+
+// klinePageSize 分頁抓取 K 線時每次請求的根數（幣安現貨上限）
+const klinePageSize = 1000
+
+// MaxKlinesPerRequest 單次請求最多的 K 線根數（幣安合約 API 上限，現貨為 1000）
+const MaxKlinesPerRequest = 1500
+
+// FetchKlinesRange 分頁獲取 [start, end) 區間內的所有 K 線
+func (s *PriceService) FetchKlinesRange(symbol string, interval string, start, end time.Time) ([]KlineData, error) {
+	pair := symbol + "USDT"
+	baseURL := s.getBaseURL()
+
+	var klines []KlineData
+	from := start.UnixMilli()
+	for from < end.UnixMilli() {
+		url := fmt.Sprintf("%s/klines?symbol=%s&interval=%s&startTime=%d&endTime=%d&limit=%d",
+			baseURL, pair, interval, from, end.UnixMilli()-1, klinePageSize)
+		page, err := s.fetchKlines(url)
+		if err != nil {
+			return nil, err
+		}
+		klines = append(klines, page...)
+		if len(page) < klinePageSize {
+			break
+		}
+		from = page[len(page)-1].OpenTime + 1
+	}
+	return klines, nil
+}
+
+// FetchKlinesBefore 獲取 end 之前（含開盤於 end 之前）的最近 limit 根 K 線
+func (s *PriceService) FetchKlinesBefore(symbol string, interval string, end time.Time, limit int) ([]KlineData, error) {
+	pair := symbol + "USDT"
+	baseURL := s.getBaseURL()
+	url := fmt.Sprintf("%s/klines?symbol=%s&interval=%s&endTime=%d&limit=%d", baseURL, pair, interval, end.UnixMilli()-1, limit)
+	return s.fetchKlines(url)
+}
 
+// fetchKlines 請求幣安 K 線 API 並解析結果
+func (s *PriceService) fetchKlines(url string) ([]KlineData, error) {
 	resp, err := http.Get(url)
 	if err != nil {
 		return nil, err
@@ -222,7 +270,7 @@ func (s *PriceService) FetchKlines(symbol string, interval string, limit int) ([
 		closePrice, _ := strconv.ParseFloat(closeStr, 64)
 		volume, _ := strconv.ParseFloat(volumeStr, 64)
 
-		klines = append(klines, KlineData{
+		kline := KlineData{
 			OpenTime:  int64(openTime),
 			Open:      open,
 			High:      high,
@@ -230,7 +278,22 @@ func (s *PriceService) FetchKlines(symbol string, interval string, limit int) ([
 			Close:     closePrice,
 			Volume:    volume,
 			CloseTime: int64(closeTime),
-		})
+		}
+
+		// 欄位 7~10：成交額、成交筆數、主動買入成交量、主動買入成交額
+		if len(k) >= 11 {
+			quoteVolumeStr, _ := k[7].(string)
+			tradeCount, _ := k[8].(float64)
+			takerBuyBaseStr, _ := k[9].(string)
+			takerBuyQuoteStr, _ := k[10].(string)
+
+			kline.QuoteVolume, _ = strconv.ParseFloat(quoteVolumeStr, 64)
+			kline.TradeCount = int64(tradeCount)
+			kline.TakerBuyBaseVolume, _ = strconv.ParseFloat(takerBuyBaseStr, 64)
+			kline.TakerBuyQuoteVolume, _ = strconv.ParseFloat(takerBuyQuoteStr, 64)
+		}
+
+		klines = append(klines, kline)
 	}
 
 	return klines, nil
@@ -254,6 +317,113 @@ func GetVolumes(klines []KlineData) []float64 {
 	return volumes
 }
 
+// GetOpens 從 K 線數據中提取開盤價
+func GetOpens(klines []KlineData) []float64 {
+	opens := make([]float64, len(klines))
+	for i, k := range klines {
+		opens[i] = k.Open
+	}
+	return opens
+}
+
+// GetHighs 從 K 線數據中提取最高價
+func GetHighs(klines []KlineData) []float64 {
+	highs := make([]float64, len(klines))
+	for i, k := range klines {
+		highs[i] = k.High
+	}
+	return highs
+}
+
+// GetLows 從 K 線數據中提取最低價
+func GetLows(klines []KlineData) []float64 {
+	lows := make([]float64, len(klines))
+	for i, k := range klines {
+		lows[i] = k.Low
+	}
+	return lows
+}
+
+// BuildVolumeStats 計算最新一根 K 線的成交量、成交額與主動買入佔比統計
+func BuildVolumeStats(klines []KlineData, avgPeriod int) indicators.VolumeResult {
+	if len(klines) == 0 {
+		return indicators.VolumeResult{}
+	}
+	result := indicators.CalculateVolumeStats(GetVolumes(klines), avgPeriod)
+
+	// 成交額與主動買入佔比
+	quoteResult := indicators.CalculateVolumeStats(GetQuoteVolumes(klines), avgPeriod)
+	result.CurrentQuoteVolume = quoteResult.CurrentVolume
+	result.AvgQuoteVolume = quoteResult.AvgVolume
+	last := klines[len(klines)-1]
+	result.TakerBuyRatio = indicators.CalculateTakerBuyRatio(last.Volume, last.TakerBuyBaseVolume)
+	return result
+}
+
+// SubscriptionVolumeConfig 訂閱的成交量檢查配置
+func SubscriptionVolumeConfig(s *models.IndicatorSubscription) indicators.VolumeConfig {
+	return indicators.VolumeConfig{
+		Enabled:    s.EnableVolumeCheck,
+		Mode:       indicators.VolumeCheckMode(s.VolumeCheckMode),
+		FixedValue: s.VolumeFixedValue,
+		Multiplier: s.VolumeMultiplier,
+		AvgPeriod:  s.VolumeAvgPeriod,
+
+		ScoreThreshold: s.VolumeScoreThreshold,
+		TakerRatio:     s.VolumeTakerRatio,
+	}
+}
+
+// IndicatorVolumeStats 指標結果中的 1 分 K 成交量統計
+func IndicatorVolumeStats(r *models.IndicatorResult) indicators.VolumeResult {
+	return indicators.VolumeResult{
+		CurrentVolume: r.CurrentVolume,
+		AvgVolume:     r.AvgVolume,
+		VolumeRatio:   r.VolumeRatio,
+		MedianVolume:  r.MedianVolume,
+		ZScore:        r.VolumeZScore,
+		MADScore:      r.VolumeMADScore,
+
+		CurrentQuoteVolume: r.CurrentQuoteVolume,
+		AvgQuoteVolume:     r.AvgQuoteVolume,
+		TakerBuyRatio:      r.TakerBuyRatio,
+	}
+}
+
+// GetCandles 從 K 線數據中提取開高低收，供 K 線型態判斷使用
+func GetCandles(klines []KlineData) []indicators.Candle {
+	candles := make([]indicators.Candle, len(klines))
+	for i, k := range klines {
+		candles[i] = indicators.Candle{Open: k.Open, High: k.High, Low: k.Low, Close: k.Close}
+	}
+	return candles
+}
+
+// GetQuoteVolumes 從 K 線數據中提取成交額（USDT）
+func GetQuoteVolumes(klines []KlineData) []float64 {
+	volumes := make([]float64, len(klines))
+	for i, k := range klines {
+		volumes[i] = k.QuoteVolume
+	}
+	return volumes
+}
+
+// FetchATR 以已收盤 K 線計算最新的 ATR
+func (s *PriceService) FetchATR(symbol, interval string, period int) (float64, error) {
+	klines, err := s.FetchKlines(symbol, interval, period*3+1)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching klines: %v", err)
+	}
+	if n := len(klines); n > 0 && !IsKlineClosed(klines[n-1], time.Now()) {
+		klines = klines[:n-1]
+	}
+	atr := indicators.Last(indicators.ATRSeries(GetHighs(klines), GetLows(klines), GetClosePrices(klines), period))
+	if math.IsNaN(atr) || atr <= 0 {
+		return 0, fmt.Errorf("not enough klines to calculate ATR(%d) on %s", period, interval)
+	}
+	return atr, nil
+}
+
 // FetchCurrentPrice 獲取當前價格（從 Redis 快取或 API）
 func (s *PriceService) FetchCurrentPrice(symbol string) (float64, error) {
 	// 先嘗試從 Redis 獲取
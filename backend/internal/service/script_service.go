@@ -0,0 +1,183 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"cryptowatch/internal/expr"
+	"cryptowatch/internal/models"
+	"cryptowatch/internal/pine"
+	"cryptowatch/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ScriptService Pine Script 腳本服務
+type ScriptService struct {
+	repo         *repository.RedisRepository
+	priceService *PriceService
+	limits       pine.Limits
+}
+
+// NewScriptService 創建腳本服務
+func NewScriptService(repo *repository.RedisRepository, priceService *PriceService) *ScriptService {
+	return &ScriptService{
+		repo:         repo,
+		priceService: priceService,
+		limits:       pine.DefaultLimits(),
+	}
+}
+
+// CreateScript 編譯並儲存腳本
+func (s *ScriptService) CreateScript(req *models.CreateScriptRequest) (*models.Script, error) {
+	program, err := pine.Compile(req.Source)
+	if err != nil {
+		return nil, &models.ValidationError{Field: "source", Message: err.Error()}
+	}
+
+	script := &models.Script{
+		ScriptID:  uuid.New().String(),
+		UserID:    req.UserID,
+		Name:      req.Name,
+		Source:    req.Source,
+		Inputs:    program.Inputs(),
+		Alerts:    program.Alerts(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := s.repo.SaveScript(script); err != nil {
+		return nil, err
+	}
+
+	return script, nil
+}
+
+// GetScript 獲取腳本
+func (s *ScriptService) GetScript(scriptID string) (*models.Script, error) {
+	return s.repo.GetScript(scriptID)
+}
+
+// GetUserScripts 獲取用戶的所有腳本
+func (s *ScriptService) GetUserScripts(userID string) ([]*models.Script, error) {
+	return s.repo.GetUserScripts(userID)
+}
+
+// DeleteScript 刪除腳本，仍有訂閱引用時拒絕刪除
+// 訂閱只能使用自己的腳本，因此只需檢查腳本擁有者的訂閱
+func (s *ScriptService) DeleteScript(scriptID string) error {
+	script, err := s.repo.GetScript(scriptID)
+	if err != nil {
+		return err
+	}
+
+	subscriptions, err := s.repo.GetUserSubscriptions(script.UserID)
+	if err != nil {
+		return fmt.Errorf("error getting subscriptions: %v", err)
+	}
+	var referenced []string
+	for _, sub := range subscriptions {
+		if sub.ScriptID == scriptID {
+			referenced = append(referenced, sub.SubscriptionID)
+		}
+	}
+	if len(referenced) > 0 {
+		return &models.ValidationError{Field: "scriptId", Message: fmt.Sprintf("腳本仍被訂閱使用：%s", strings.Join(referenced, ", "))}
+	}
+
+	return s.repo.DeleteScript(scriptID)
+}
+
+// Run 在 K 線上執行腳本
+func (s *ScriptService) Run(script *models.Script, klines []KlineData, inputs map[string]float64) (*pine.Result, error) {
+	program, err := pine.Compile(script.Source)
+	if err != nil {
+		return nil, err
+	}
+	return program.Run(ToPineSeries(klines), inputs, s.limits)
+}
+
+// RunScript 以最新的 K 線試跑腳本，返回最新一根的輸出
+func (s *ScriptService) RunScript(scriptID string, req *models.RunScriptRequest) (*models.ScriptRunResult, error) {
+	req.ApplyDefaults()
+	if !expr.ValidInterval(req.Interval) {
+		return nil, &models.ValidationError{Field: "interval", Message: fmt.Sprintf("無效的 K 線週期 %q", req.Interval)}
+	}
+	// 試跑只請求一次 K 線，受幣安單次請求上限限制
+	if maxLimit := min(pine.MaxBars, MaxKlinesPerRequest); req.Limit > maxLimit {
+		return nil, &models.ValidationError{Field: "limit", Message: fmt.Sprintf("不能超過 %d", maxLimit)}
+	}
+
+	script, err := s.repo.GetScript(scriptID)
+	if err != nil {
+		return nil, err
+	}
+
+	klines, err := s.priceService.FetchKlines(req.Symbol, req.Interval, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.Run(script, klines, req.Inputs)
+	if err != nil {
+		return nil, &models.ValidationError{Field: "source", Message: err.Error()}
+	}
+
+	runResult := &models.ScriptRunResult{
+		Symbol:   req.Symbol,
+		Interval: req.Interval,
+		Bars:     len(klines),
+		Plots:    make(map[string]*float64, len(result.Plots)),
+		Alerts:   make([]models.ScriptAlertState, 0, len(result.Alerts)),
+		Steps:    result.Steps,
+	}
+
+	for _, plot := range result.Plots {
+		if v := plot.Values[len(plot.Values)-1]; !math.IsNaN(v) && !math.IsInf(v, 0) {
+			runResult.Plots[plot.Title] = &v
+		} else {
+			runResult.Plots[plot.Title] = nil
+		}
+	}
+
+	for _, alert := range result.Alerts {
+		state := models.ScriptAlertState{
+			Title:   alert.Title,
+			Message: alert.Message,
+			Active:  alert.Values[len(alert.Values)-1],
+		}
+		for i := len(alert.Values) - 1; i >= 0; i-- {
+			if alert.Values[i] {
+				firedAt := time.UnixMilli(klines[i].OpenTime)
+				state.LastFiredAt = &firedAt
+				break
+			}
+		}
+		runResult.Alerts = append(runResult.Alerts, state)
+	}
+
+	return runResult, nil
+}
+
+// ToPineSeries 將 K 線轉換為腳本執行用的序列
+func ToPineSeries(klines []KlineData) pine.Series {
+	series := pine.Series{
+		Time:   make([]int64, len(klines)),
+		Open:   make([]float64, len(klines)),
+		High:   make([]float64, len(klines)),
+		Low:    make([]float64, len(klines)),
+		Close:  make([]float64, len(klines)),
+		Volume: make([]float64, len(klines)),
+	}
+	for i, k := range klines {
+		series.Time[i] = k.OpenTime
+		series.Open[i] = k.Open
+		series.High[i] = k.High
+		series.Low[i] = k.Low
+		series.Close[i] = k.Close
+		series.Volume[i] = k.Volume
+	}
+	return series
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"cryptowatch/internal/models"
+	"cryptowatch/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// 成績單統計的訊號數
+const (
+	DefaultScorecardSignals = 100
+	MaxScorecardSignals     = 500
+	scorecardRecent         = 20 // 成績單列出的最近訊號數
+)
+
+// SignalService 訊號結果追蹤服務
+type SignalService struct {
+	repo         *repository.RedisRepository
+	priceService *PriceService
+}
+
+// NewSignalService 創建訊號結果追蹤服務
+func NewSignalService(repo *repository.RedisRepository, priceService *PriceService) *SignalService {
+	return &SignalService{
+		repo:         repo,
+		priceService: priceService,
+	}
+}
+
+// RecordSignal 記錄一次已發出的通知，之後由 SignalTracker 補上後續價格
+// side 為觸發時判斷的方向，條件式與腳本等沒有固定方向的訊號為 neutral
+func (s *SignalService) RecordSignal(sub *models.IndicatorSubscription, result *models.IndicatorResult, alertType, side string) (*models.SignalRecord, error) {
+	now := time.Now()
+	signal := &models.SignalRecord{
+		SignalID:       uuid.New().String(),
+		SubscriptionID: sub.SubscriptionID,
+		UserID:         sub.UserID,
+		Symbol:         result.Symbol,
+		AlertType:      alertType,
+		Side:           side,
+		Price:          result.CurrentPrice,
+		UpperBand:      result.UpperBand,
+		LowerBand:      result.LowerBand,
+		VolumeRatio:    result.VolumeRatio,
+		CreatedAt:      now,
+		TrackedUntil:   now,
+	}
+	for _, h := range models.SignalHorizons {
+		signal.Outcomes = append(signal.Outcomes, models.SignalOutcome{Horizon: h.Label})
+	}
+
+	if err := s.repo.SaveSignal(signal); err != nil {
+		return nil, fmt.Errorf("error saving signal: %v", err)
+	}
+	return signal, nil
+}
+
+// UpdateOutcomes 以 1 分鐘 K 線補上已到期時間點的價格與最大順向/逆向波動
+// 每根 K 線只處理一次，返回訊號是否有更新
+func (s *SignalService) UpdateOutcomes(signal *models.SignalRecord, now time.Time) (bool, error) {
+	last := models.SignalHorizons[len(models.SignalHorizons)-1]
+	end := signal.CreatedAt.Add(last.Duration)
+	if now.Before(end) {
+		end = now
+	}
+	if end.Sub(signal.TrackedUntil) < time.Minute {
+		return false, nil
+	}
+
+	klines, err := s.priceService.FetchKlinesRange(signal.Symbol, "1m", signal.TrackedUntil, end)
+	if err != nil {
+		return false, fmt.Errorf("error fetching klines: %v", err)
+	}
+
+	updated := false
+	for _, k := range klines {
+		closeAt := time.UnixMilli(k.CloseTime + 1)
+		if closeAt.After(end) {
+			break // 尚未收盤
+		}
+		applySignalKline(signal, k, closeAt)
+		signal.TrackedUntil = closeAt
+		updated = true
+	}
+
+	// 追蹤期間已結束但沒有任何 K 線（例如幣種下架），直接結束追蹤
+	if !updated && now.Sub(signal.CreatedAt) > last.Duration+time.Hour {
+		signal.Completed = true
+		updated = true
+	}
+	if !updated {
+		return false, nil
+	}
+
+	if err := s.repo.UpdateSignal(signal); err != nil {
+		return false, fmt.Errorf("error updating signal: %v", err)
+	}
+	return true, nil
+}
+
+// applySignalKline 以一根已收盤的 K 線更新訊號結果
+func applySignalKline(signal *models.SignalRecord, k KlineData, closeAt time.Time) {
+	if signal.Price <= 0 {
+		return
+	}
+
+	// 做多看最高/最低價，做空相反
+	favorable := (k.High - signal.Price) / signal.Price * 100
+	adverse := (signal.Price - k.Low) / signal.Price * 100
+	if signal.Direction() < 0 {
+		favorable = (signal.Price - k.Low) / signal.Price * 100
+		adverse = (k.High - signal.Price) / signal.Price * 100
+	}
+	signal.MaxFavorablePct = math.Max(signal.MaxFavorablePct, favorable)
+	signal.MaxAdversePct = math.Max(signal.MaxAdversePct, adverse)
+
+	// 第一根在到期時間收盤的 K 線即為該時間點的價格
+	completed := true
+	for i, h := range models.SignalHorizons {
+		outcome := &signal.Outcomes[i]
+		if outcome.Price == nil && !closeAt.Before(signal.CreatedAt.Add(h.Duration)) {
+			price := k.Close
+			returnPct := signal.Direction() * (price/signal.Price - 1) * 100
+			outcome.Price = &price
+			outcome.ReturnPct = &returnPct
+		}
+		if outcome.Price == nil {
+			completed = false
+		}
+	}
+	signal.Completed = completed
+}
+
+// GetSubscriptionScorecard 獲取訂閱最近 limit 個訊號的成績單
+func (s *SignalService) GetSubscriptionScorecard(subscriptionID string, limit int) (*models.SignalScorecard, error) {
+	if err := validateScorecardLimit(limit); err != nil {
+		return nil, err
+	}
+	signals, err := s.repo.GetSubscriptionSignals(subscriptionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting signals: %v", err)
+	}
+	scorecard := buildScorecard(signals)
+	scorecard.SubscriptionID = subscriptionID
+	return scorecard, nil
+}
+
+// GetSymbolScorecard 獲取幣種最近 limit 個訊號（所有訂閱）的成績單
+func (s *SignalService) GetSymbolScorecard(symbol string, limit int) (*models.SignalScorecard, error) {
+	if err := validateScorecardLimit(limit); err != nil {
+		return nil, err
+	}
+	signals, err := s.repo.GetSymbolSignals(symbol, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting signals: %v", err)
+	}
+	scorecard := buildScorecard(signals)
+	scorecard.Symbol = symbol
+	return scorecard, nil
+}
+
+// validateScorecardLimit 驗證成績單統計的訊號數
+func validateScorecardLimit(limit int) error {
+	if limit < 1 || limit > MaxScorecardSignals {
+		return &models.ValidationError{Field: "limit", Message: fmt.Sprintf("訊號數必須介於 1 到 %d", MaxScorecardSignals)}
+	}
+	return nil
+}
+
+// buildScorecard 統計各時間點的勝率與平均報酬，以及已完成訊號的平均最大順向/逆向波動
+// 沒有方向的訊號無從判斷順逆向，只計入訊號數與最近訊號
+func buildScorecard(signals []*models.SignalRecord) *models.SignalScorecard {
+	scorecard := &models.SignalScorecard{
+		Signals: len(signals),
+		Recent:  []models.SignalRecord{},
+	}
+
+	returns := make([][]float64, len(models.SignalHorizons))
+	directionalCompleted := 0
+	for _, signal := range signals {
+		if len(scorecard.Recent) < scorecardRecent {
+			scorecard.Recent = append(scorecard.Recent, *signal)
+		}
+		if signal.Completed {
+			scorecard.Completed++
+		}
+		if !signal.IsDirectional() {
+			scorecard.Neutral++
+			continue
+		}
+
+		for i, outcome := range signal.Outcomes {
+			if i < len(returns) && outcome.ReturnPct != nil {
+				returns[i] = append(returns[i], *outcome.ReturnPct)
+			}
+		}
+		if signal.Completed {
+			directionalCompleted++
+			scorecard.AvgMaxFavorablePct += signal.MaxFavorablePct
+			scorecard.AvgMaxAdversePct += signal.MaxAdversePct
+		}
+	}
+	if directionalCompleted > 0 {
+		scorecard.AvgMaxFavorablePct /= float64(directionalCompleted)
+		scorecard.AvgMaxAdversePct /= float64(directionalCompleted)
+	}
+
+	for i, h := range models.SignalHorizons {
+		hitRate, avg := hitRateAndMean(returns[i])
+		scorecard.Horizons = append(scorecard.Horizons, models.HorizonScore{
+			Horizon:      h.Label,
+			Signals:      len(returns[i]),
+			HitRate:      hitRate,
+			AvgReturnPct: avg,
+		})
+	}
+	return scorecard
+}
@@ -1,9 +1,14 @@
 package service
 
 import (
+	"fmt"
+	"slices"
 	"time"
 
+	"cryptowatch/internal/expr"
+	"cryptowatch/internal/indicators"
 	"cryptowatch/internal/models"
+	"cryptowatch/internal/pine"
 	"cryptowatch/internal/repository"
 
 	"github.com/google/uuid"
@@ -19,25 +24,176 @@ func NewSubscriptionService(repo *repository.RedisRepository) *SubscriptionServi
 	return &SubscriptionService{repo: repo}
 }
 
+// validateSubscription 驗證訂閱設定，包含條件式編譯、K 線週期、型態名稱、成交量檢查模式與模擬交易的訊號方向
+func validateSubscription(sub *models.IndicatorSubscription) error {
+	if err := sub.Validate(); err != nil {
+		return err
+	}
+	if err := validateVolumeCheck(sub); err != nil {
+		return err
+	}
+
+	if sub.Condition != "" {
+		// 先檢查長度，過長的條件式不進入解析器
+		if len(sub.Condition) > expr.MaxSourceBytes {
+			return &models.ValidationError{Field: "condition", Message: fmt.Sprintf("條件式長度超過上限 %d bytes", expr.MaxSourceBytes)}
+		}
+		if _, err := expr.Compile(sub.Condition); err != nil {
+			return &models.ValidationError{Field: "condition", Message: err.Error()}
+		}
+	}
+
+	intervals := []struct {
+		field, interval string
+		used            bool
+	}{
+		{"scriptInterval", sub.ScriptInterval, sub.ScriptID != ""},
+		{"studyInterval", sub.StudyInterval, len(sub.StudyEvents) > 0},
+		{"divergenceInterval", sub.DivergenceInterval, len(sub.DivergenceTypes) > 0},
+		{"patternInterval", sub.PatternInterval, len(sub.PatternAlerts) > 0},
+	}
+	for _, iv := range intervals {
+		if iv.used && !expr.ValidInterval(iv.interval) {
+			return &models.ValidationError{Field: iv.field, Message: fmt.Sprintf("無效的 K 線週期 %q", iv.interval)}
+		}
+	}
+	for i, rule := range sub.Confluence {
+		if !expr.ValidInterval(rule.Interval) {
+			return &models.ValidationError{Field: "confluence", Message: fmt.Sprintf("第 %d 個條件的 K 線週期 %q 無效", i+1, rule.Interval)}
+		}
+	}
+
+	if err := validatePatterns("patternAlerts", sub.PatternAlerts); err != nil {
+		return err
+	}
+	if err := validatePatterns("patternFilter", sub.PatternFilter); err != nil {
+		return err
+	}
+
+	// 模擬交易需要訊號方向，十字線等沒有方向的型態無法決定做多或做空
+	if sub.PaperTrading.Enabled {
+		for _, p := range sub.PatternAlerts {
+			if pattern := indicators.CandlePattern(p); !pattern.IsBullish() && !pattern.IsBearish() {
+				return &models.ValidationError{Field: "paperTrading.enabled", Message: fmt.Sprintf("K 線型態 %q 沒有方向，無法模擬交易", p)}
+			}
+		}
+	}
+	return nil
+}
+
+// validateVolumeCheck 檢查成交量檢查模式與該模式需要的閾值
+func validateVolumeCheck(sub *models.IndicatorSubscription) error {
+	switch indicators.VolumeCheckMode(sub.VolumeCheckMode) {
+	case "", indicators.VolumeCheckModeFixed, indicators.VolumeCheckModeMultiplier:
+	case indicators.VolumeCheckModeZScore, indicators.VolumeCheckModeMAD:
+		if sub.EnableVolumeCheck && sub.VolumeScoreThreshold <= 0 {
+			return &models.ValidationError{Field: "volumeScoreThreshold", Message: "必須大於 0"}
+		}
+	case indicators.VolumeCheckModeNotional:
+		if sub.EnableVolumeCheck && sub.VolumeFixedValue <= 0 {
+			return &models.ValidationError{Field: "volumeFixedValue", Message: "成交額閾值必須大於 0"}
+		}
+	case indicators.VolumeCheckModeTakerBuy, indicators.VolumeCheckModeTakerSell:
+		if sub.EnableVolumeCheck && (sub.VolumeTakerRatio < 0.5 || sub.VolumeTakerRatio > 1) {
+			return &models.ValidationError{Field: "volumeTakerRatio", Message: "必須介於 0.5 到 1 之間"}
+		}
+	default:
+		return &models.ValidationError{Field: "volumeCheckMode", Message: fmt.Sprintf("不支援的成交量檢查模式 %q", sub.VolumeCheckMode)}
+	}
+	return nil
+}
+
+// validatePatterns 檢查 K 線型態名稱
+func validatePatterns(field string, patterns []string) error {
+	for _, p := range patterns {
+		if !slices.Contains(indicators.AllPatterns, indicators.CandlePattern(p)) {
+			return &models.ValidationError{Field: field, Message: fmt.Sprintf("不支援的 K 線型態 %q", p)}
+		}
+	}
+	return nil
+}
+
+// validate 驗證訂閱設定，並檢查引用的腳本與 alertcondition 是否存在
+func (s *SubscriptionService) validate(sub *models.IndicatorSubscription) error {
+	if err := validateSubscription(sub); err != nil {
+		return err
+	}
+	if sub.ScriptID == "" {
+		return nil
+	}
+
+	script, err := s.repo.GetScript(sub.ScriptID)
+	if err != nil {
+		return &models.ValidationError{Field: "scriptId", Message: fmt.Sprintf("找不到腳本 %q", sub.ScriptID)}
+	}
+	if script.UserID != sub.UserID {
+		return &models.ValidationError{Field: "scriptId", Message: "只能使用自己的腳本"}
+	}
+	if sub.ScriptAlert != "" && !slices.Contains(script.Alerts, sub.ScriptAlert) {
+		return &models.ValidationError{Field: "scriptAlert", Message: fmt.Sprintf("腳本沒有 alertcondition %q", sub.ScriptAlert)}
+	}
+	for name := range sub.ScriptInputs {
+		if !slices.ContainsFunc(script.Inputs, func(in pine.Input) bool { return in.Name == name }) {
+			return &models.ValidationError{Field: "scriptInputs", Message: fmt.Sprintf("腳本沒有輸入參數 %q", name)}
+		}
+	}
+	return nil
+}
+
 // CreateSubscription 創建訂閱
 func (s *SubscriptionService) CreateSubscription(req *models.CreateSubscriptionRequest) (*models.IndicatorSubscription, error) {
 	// 套用預設值
 	req.ApplyDefaults()
 
 	sub := &models.IndicatorSubscription{
-		SubscriptionID:    uuid.New().String(),
-		UserID:            req.UserID,
-		Symbol:            req.Symbol,
-		Enabled:           true, // 創建時預設啟用
-		TelegramChatID:    req.TelegramChatID,
-		NotifyIntervalMin: req.NotifyIntervalMin,
-		EnableVolumeCheck: req.EnableVolumeCheck,
-		VolumeCheckMode:   req.VolumeCheckMode,
-		VolumeFixedValue:  req.VolumeFixedValue,
-		VolumeMultiplier:  req.VolumeMultiplier,
-		VolumeAvgPeriod:   req.VolumeAvgPeriod,
-		CreatedAt:         time.Now(),
-		UpdatedAt:         time.Now(),
+		SubscriptionID:            uuid.New().String(),
+		UserID:                    req.UserID,
+		Symbol:                    req.Symbol,
+		Enabled:                   true, // 創建時預設啟用
+		TelegramChatID:            req.TelegramChatID,
+		NotifyIntervalMin:         req.NotifyIntervalMin,
+		EnableVolumeCheck:         req.EnableVolumeCheck,
+		VolumeCheckMode:           req.VolumeCheckMode,
+		VolumeFixedValue:          req.VolumeFixedValue,
+		VolumeMultiplier:          req.VolumeMultiplier,
+		VolumeAvgPeriod:           req.VolumeAvgPeriod,
+		VolumeScoreThreshold:      req.VolumeScoreThreshold,
+		VolumeTakerRatio:          req.VolumeTakerRatio,
+		TriggerMode:               req.TriggerMode,
+		ConfirmCloses:             req.ConfirmCloses,
+		MinPearsonR:               req.MinPearsonR,
+		SlopeFilter:               req.SlopeFilter,
+		Condition:                 req.Condition,
+		ScriptID:                  req.ScriptID,
+		ScriptInterval:            req.ScriptInterval,
+		ScriptAlert:               req.ScriptAlert,
+		ScriptInputs:              req.ScriptInputs,
+		StudyEvents:               req.StudyEvents,
+		StudyInterval:             req.StudyInterval,
+		DivergenceTypes:           req.DivergenceTypes,
+		DivergenceOscillator:      req.DivergenceOscillator,
+		DivergencePivotLookback:   req.DivergencePivotLookback,
+		DivergenceInterval:        req.DivergenceInterval,
+		PatternAlerts:             req.PatternAlerts,
+		PatternInterval:           req.PatternInterval,
+		PatternFilter:             req.PatternFilter,
+		PatternDojiBodyRatio:      req.PatternDojiBodyRatio,
+		PatternHammerWickRatio:    req.PatternHammerWickRatio,
+		PatternHammerShadowRatio:  req.PatternHammerShadowRatio,
+		PatternStarBodyRatio:      req.PatternStarBodyRatio,
+		PatternLongBodyRatio:      req.PatternLongBodyRatio,
+		Confluence:                req.Confluence,
+		RequireCompressedBreakout: req.RequireCompressedBreakout,
+		CompressedWithinBars:      req.CompressedWithinBars,
+		SuppressBTCDriven:         req.SuppressBTCDriven,
+		BTCCorrelationMin:         req.BTCCorrelationMin,
+		PaperTrading:              req.PaperTrading,
+		CreatedAt:                 time.Now(),
+		UpdatedAt:                 time.Now(),
+	}
+
+	if err := s.validate(sub); err != nil {
+		return nil, err
 	}
 
 	if err := s.repo.SaveSubscription(sub); err != nil {
@@ -89,6 +245,130 @@ func (s *SubscriptionService) UpdateSubscription(subscriptionID string, req *mod
 	if req.VolumeAvgPeriod != nil {
 		sub.VolumeAvgPeriod = *req.VolumeAvgPeriod
 	}
+	if req.VolumeScoreThreshold != nil {
+		sub.VolumeScoreThreshold = *req.VolumeScoreThreshold
+	}
+	if req.VolumeTakerRatio != nil {
+		sub.VolumeTakerRatio = *req.VolumeTakerRatio
+	}
+	if req.TriggerMode != nil {
+		sub.TriggerMode = *req.TriggerMode
+	}
+	if req.ConfirmCloses != nil {
+		sub.ConfirmCloses = *req.ConfirmCloses
+	}
+	if req.MinPearsonR != nil {
+		sub.MinPearsonR = *req.MinPearsonR
+	}
+	if req.SlopeFilter != nil {
+		sub.SlopeFilter = *req.SlopeFilter
+	}
+	if req.Condition != nil {
+		sub.Condition = *req.Condition
+	}
+	if req.ScriptID != nil {
+		sub.ScriptID = *req.ScriptID
+		if sub.ScriptID != "" && sub.ScriptInterval == "" {
+			sub.ScriptInterval = "4h"
+		}
+	}
+	if req.ScriptInterval != nil {
+		sub.ScriptInterval = *req.ScriptInterval
+	}
+	if req.ScriptAlert != nil {
+		sub.ScriptAlert = *req.ScriptAlert
+	}
+	if req.ScriptInputs != nil {
+		sub.ScriptInputs = req.ScriptInputs
+	}
+	if req.StudyEvents != nil {
+		sub.StudyEvents = *req.StudyEvents
+		if len(sub.StudyEvents) > 0 && sub.StudyInterval == "" {
+			sub.StudyInterval = "4h"
+		}
+	}
+	if req.StudyInterval != nil {
+		sub.StudyInterval = *req.StudyInterval
+	}
+	if req.DivergenceTypes != nil {
+		sub.DivergenceTypes = *req.DivergenceTypes
+		if len(sub.DivergenceTypes) > 0 {
+			if sub.DivergenceOscillator == "" {
+				sub.DivergenceOscillator = models.DivergenceOscillatorRSI
+			}
+			if sub.DivergencePivotLookback <= 0 {
+				sub.DivergencePivotLookback = 5
+			}
+			if sub.DivergenceInterval == "" {
+				sub.DivergenceInterval = "4h"
+			}
+		}
+	}
+	if req.DivergenceOscillator != nil {
+		sub.DivergenceOscillator = *req.DivergenceOscillator
+	}
+	if req.DivergencePivotLookback != nil {
+		sub.DivergencePivotLookback = *req.DivergencePivotLookback
+	}
+	if req.DivergenceInterval != nil {
+		sub.DivergenceInterval = *req.DivergenceInterval
+	}
+	if req.PatternAlerts != nil {
+		sub.PatternAlerts = *req.PatternAlerts
+		if len(sub.PatternAlerts) > 0 && sub.PatternInterval == "" {
+			sub.PatternInterval = "4h"
+		}
+	}
+	if req.PatternInterval != nil {
+		sub.PatternInterval = *req.PatternInterval
+	}
+	if req.PatternFilter != nil {
+		sub.PatternFilter = *req.PatternFilter
+	}
+	if req.PatternDojiBodyRatio != nil {
+		sub.PatternDojiBodyRatio = *req.PatternDojiBodyRatio
+	}
+	if req.PatternHammerWickRatio != nil {
+		sub.PatternHammerWickRatio = *req.PatternHammerWickRatio
+	}
+	if req.PatternHammerShadowRatio != nil {
+		sub.PatternHammerShadowRatio = *req.PatternHammerShadowRatio
+	}
+	if req.PatternStarBodyRatio != nil {
+		sub.PatternStarBodyRatio = *req.PatternStarBodyRatio
+	}
+	if req.PatternLongBodyRatio != nil {
+		sub.PatternLongBodyRatio = *req.PatternLongBodyRatio
+	}
+	if req.Confluence != nil {
+		sub.Confluence = *req.Confluence
+	}
+	if req.RequireCompressedBreakout != nil {
+		sub.RequireCompressedBreakout = *req.RequireCompressedBreakout
+		if sub.RequireCompressedBreakout && sub.CompressedWithinBars <= 0 {
+			sub.CompressedWithinBars = 3
+		}
+	}
+	if req.CompressedWithinBars != nil {
+		sub.CompressedWithinBars = *req.CompressedWithinBars
+	}
+	if req.SuppressBTCDriven != nil {
+		sub.SuppressBTCDriven = *req.SuppressBTCDriven
+		if sub.SuppressBTCDriven && sub.BTCCorrelationMin <= 0 {
+			sub.BTCCorrelationMin = models.DefaultBTCCorrelationMin
+		}
+	}
+	if req.BTCCorrelationMin != nil {
+		sub.BTCCorrelationMin = *req.BTCCorrelationMin
+	}
+	if req.PaperTrading != nil {
+		sub.PaperTrading = *req.PaperTrading
+		sub.PaperTrading.ApplyDefaults()
+	}
+
+	if err := s.validate(sub); err != nil {
+		return nil, err
+	}
 
 	sub.UpdatedAt = time.Now()
 
@@ -120,4 +400,3 @@ func (s *SubscriptionService) ToggleSubscription(subscriptionID string) (*models
 
 	return sub, nil
 }
-
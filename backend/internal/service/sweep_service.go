@@ -0,0 +1,476 @@
+package service
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"cryptowatch/internal/expr"
+	"cryptowatch/internal/indicators"
+	"cryptowatch/internal/models"
+	"cryptowatch/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// sweepTopResults 任務結果保留的前幾名
+const sweepTopResults = 20
+
+// 背景任務數量限制
+const (
+	maxRunningSweeps = 2 // 同時執行的任務數
+	maxQueuedSweeps  = 8 // 執行中與排隊中的任務上限
+)
+
+// SweepService LRC 參數搜尋服務
+type SweepService struct {
+	repo            *repository.RedisRepository
+	backtestService *BacktestService
+
+	queued  chan struct{} // 執行中與排隊中的任務
+	running chan struct{} // 執行中的任務
+}
+
+// NewSweepService 創建參數搜尋服務
+func NewSweepService(repo *repository.RedisRepository, backtestService *BacktestService) *SweepService {
+	return &SweepService{
+		repo:            repo,
+		backtestService: backtestService,
+		queued:          make(chan struct{}, maxQueuedSweeps),
+		running:         make(chan struct{}, maxRunningSweeps),
+	}
+}
+
+// StartSweep 驗證設定並在背景執行參數搜尋，立即返回任務
+func (s *SweepService) StartSweep(req *models.SweepRequest) (*models.SweepJob, error) {
+	req.ApplyDefaults()
+	for _, interval := range req.Intervals {
+		if !expr.ValidInterval(interval) {
+			return nil, &models.ValidationError{Field: "intervals", Message: fmt.Sprintf("無效的 K 線週期 %q", interval)}
+		}
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	horizons, err := sweepHorizons(req)
+	if err != nil {
+		return nil, err
+	}
+	start, err := sweepStart(req)
+	if err != nil {
+		return nil, err
+	}
+	req.Start = start
+
+	combos := sweepCombinations(req)
+
+	// 先以第一組參數驗證共用的訂閱設定，避免任務在背景才失敗
+	if err := validateSubscription(sweepSubscription(req, combos[0])); err != nil {
+		return nil, err
+	}
+
+	select {
+	case s.queued <- struct{}{}:
+	default:
+		return nil, &models.ValidationError{Field: "sweep", Message: fmt.Sprintf("進行中的參數搜尋已達上限 %d 個，請稍後再試", maxQueuedSweeps)}
+	}
+
+	now := time.Now()
+	job := &models.SweepJob{
+		ID:        uuid.New().String(),
+		Status:    models.SweepStatusPending,
+		Request:   *req,
+		Total:     len(combos),
+		Results:   []models.SweepResult{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.SaveSweepJob(job); err != nil {
+		<-s.queued
+		return nil, err
+	}
+
+	// 超過同時執行上限時維持 pending，等前面的任務完成
+	go func() {
+		defer func() { <-s.queued }()
+		s.running <- struct{}{}
+		defer func() { <-s.running }()
+		s.run(job, combos, horizons)
+	}()
+	return job, nil
+}
+
+// GetSweep 獲取參數搜尋任務
+func (s *SweepService) GetSweep(id string) (*models.SweepJob, error) {
+	return s.repo.GetSweepJob(id)
+}
+
+// FailOrphanedSweeps 將服務重啟前未完成的任務標記為失敗，啟動時呼叫
+func (s *SweepService) FailOrphanedSweeps() error {
+	jobs, err := s.repo.GetSweepJobs()
+	if err != nil {
+		return fmt.Errorf("error getting sweep jobs: %v", err)
+	}
+	for _, job := range jobs {
+		if job.Status == models.SweepStatusPending || job.Status == models.SweepStatusRunning {
+			s.fail(job, fmt.Errorf("服務重啟，任務已中斷"))
+		}
+	}
+	return nil
+}
+
+// run 執行參數搜尋：每個週期只抓一次 K 線，成交量統計在組合之間共用
+// horizons 為前瞻期間換算後各週期的 K 線根數
+func (s *SweepService) run(job *models.SweepJob, combos []models.SweepParams, horizons map[string]int) {
+	req := &job.Request
+	job.Status = models.SweepStatusRunning
+	s.save(job)
+
+	type loaded struct {
+		klines   []KlineData
+		startIdx int
+	}
+	klineCache := make(map[string]loaded)
+	volumeCache := make(map[string]indicators.VolumeResult)
+	volume := func(symbol string, closeTime time.Time, avgPeriod int) (indicators.VolumeResult, error) {
+		key := strconv.FormatInt(closeTime.UnixMilli(), 10) + ":" + strconv.Itoa(avgPeriod)
+		if stats, ok := volumeCache[key]; ok {
+			return stats, nil
+		}
+		stats, err := s.backtestService.volumeStats(symbol, closeTime, avgPeriod)
+		if err != nil {
+			return stats, err
+		}
+		volumeCache[key] = stats
+		return stats, nil
+	}
+
+	warmup := models.MaxConfirmCloses
+	for _, length := range req.LRCLengths {
+		warmup = max(warmup, length+models.MaxConfirmCloses)
+	}
+
+	folds := sweepFolds(req.Start, req.End, req.Folds)
+	results := make([]models.SweepResult, 0, len(combos))
+	signals := make([][]models.BacktestSignal, 0, len(combos))
+	for _, params := range combos {
+		data, ok := klineCache[params.Interval]
+		if !ok {
+			klines, startIdx, err := s.backtestService.loadKlines(req.Symbol, params.Interval, req.Start, req.End, warmup)
+			if err != nil {
+				s.fail(job, err)
+				return
+			}
+			data = loaded{klines: klines, startIdx: startIdx}
+			klineCache[params.Interval] = data
+		}
+
+		backtest := &models.BacktestRequest{
+			Symbol:           req.Symbol,
+			Interval:         params.Interval,
+			LRCLength:        params.LRCLength,
+			LRCDevMultiplier: params.LRCDevMultiplier,
+			Horizons:         []int{horizons[params.Interval]},
+		}
+		result, err := replayBacktest(backtest, sweepSubscription(req, params), data.klines, data.startIdx, volume)
+		if err != nil {
+			s.fail(job, err)
+			return
+		}
+
+		results = append(results, sweepResult(req, params, result.Signals, horizons[params.Interval], folds))
+		signals = append(signals, result.Signals)
+		job.Completed++
+		s.save(job)
+	}
+
+	job.WalkForward, job.WalkForwardObjective = walkForward(req, results, signals, horizons, folds)
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Eligible != results[j].Eligible {
+			return results[i].Eligible
+		}
+		return results[i].Objective > results[j].Objective
+	})
+	job.Results = results[:min(sweepTopResults, len(results))]
+
+	finished := time.Now()
+	job.Status = models.SweepStatusDone
+	job.FinishedAt = &finished
+	s.save(job)
+
+	log.Info().
+		Str("jobId", job.ID).
+		Str("symbol", req.Symbol).
+		Int("combinations", job.Total).
+		Msg("Parameter sweep finished")
+}
+
+// save 更新任務進度
+func (s *SweepService) save(job *models.SweepJob) {
+	job.UpdatedAt = time.Now()
+	if err := s.repo.SaveSweepJob(job); err != nil {
+		log.Error().Err(err).Str("jobId", job.ID).Msg("Error saving sweep job")
+	}
+}
+
+// fail 將任務標記為失敗
+func (s *SweepService) fail(job *models.SweepJob, err error) {
+	log.Error().Err(err).Str("jobId", job.ID).Msg("Parameter sweep failed")
+	finished := time.Now()
+	job.Status = models.SweepStatusFailed
+	job.Error = err.Error()
+	job.FinishedAt = &finished
+	s.save(job)
+}
+
+// sweepStart 搜尋開始時間，未指定時以最長的週期往前推 Bars 根，並檢查每個週期的範圍上限
+func sweepStart(req *models.SweepRequest) (time.Time, error) {
+	start := req.Start
+	if start.IsZero() {
+		var longest time.Duration
+		for _, interval := range req.Intervals {
+			d, err := IntervalDuration(interval)
+			if err != nil {
+				return time.Time{}, err
+			}
+			longest = max(longest, d)
+		}
+		start = req.End.Add(-time.Duration(req.Bars) * longest)
+	}
+	for _, interval := range req.Intervals {
+		if _, err := backtestStart(interval, start, req.End, 0); err != nil {
+			return time.Time{}, &models.ValidationError{Field: "intervals", Message: fmt.Sprintf("%s 週期：%v", interval, err)}
+		}
+	}
+	return start, nil
+}
+
+// sweepHorizons 將前瞻期間換算為各週期的 K 線根數，期間必須是每個週期的整數倍
+func sweepHorizons(req *models.SweepRequest) (map[string]int, error) {
+	horizon, err := IntervalDuration(req.Horizon)
+	if err != nil {
+		return nil, &models.ValidationError{Field: "horizon", Message: fmt.Sprintf("無效的前瞻期間 %q", req.Horizon)}
+	}
+	bars := make(map[string]int, len(req.Intervals))
+	for _, interval := range req.Intervals {
+		d, err := IntervalDuration(interval)
+		if err != nil {
+			return nil, &models.ValidationError{Field: "intervals", Message: err.Error()}
+		}
+		if horizon < d || horizon%d != 0 {
+			return nil, &models.ValidationError{Field: "horizon", Message: fmt.Sprintf("%s 必須是 %s 週期的整數倍", req.Horizon, interval)}
+		}
+		n := int(horizon / d)
+		if n > models.MaxBacktestHorizon {
+			return nil, &models.ValidationError{Field: "horizon", Message: fmt.Sprintf("%s 週期換算為 %d 根，不能超過 %d 根", interval, n, models.MaxBacktestHorizon)}
+		}
+		bars[interval] = n
+	}
+	return bars, nil
+}
+
+// sweepCombinations 要評估的參數組合
+// grid 為所有組合；random 只抽出不重複的組合編號再換算參數，不展開整個搜尋空間
+func sweepCombinations(req *models.SweepRequest) []models.SweepParams {
+	total := req.Combinations()
+	var indices []int
+	if req.Method == models.SweepMethodRandom && req.Samples < total {
+		picked := make(map[int]bool, req.Samples)
+		for len(picked) < req.Samples {
+			picked[rand.IntN(total)] = true
+		}
+		indices = slices.Sorted(maps.Keys(picked))
+	} else {
+		indices = make([]int, total)
+		for i := range indices {
+			indices[i] = i
+		}
+	}
+
+	combos := make([]models.SweepParams, len(indices))
+	for i, index := range indices {
+		combos[i] = sweepParams(req, index)
+	}
+	return combos
+}
+
+// sweepParams 依組合編號換算參數，順序依週期、長度、標準差倍數、成交量門檻展開
+func sweepParams(req *models.SweepRequest, index int) models.SweepParams {
+	volume := req.VolumeMultipliers[index%len(req.VolumeMultipliers)]
+	index /= len(req.VolumeMultipliers)
+	dev := req.LRCDevMultipliers[index%len(req.LRCDevMultipliers)]
+	index /= len(req.LRCDevMultipliers)
+	length := req.LRCLengths[index%len(req.LRCLengths)]
+	index /= len(req.LRCLengths)
+	return models.SweepParams{
+		Interval:         req.Intervals[index],
+		LRCLength:        length,
+		LRCDevMultiplier: dev,
+		VolumeMultiplier: volume,
+	}
+}
+
+// sweepSubscription 以一組參數建立回測用的訂閱
+func sweepSubscription(req *models.SweepRequest, params models.SweepParams) *models.IndicatorSubscription {
+	backtest := &models.BacktestRequest{
+		Symbol:            req.Symbol,
+		NotifyIntervalMin: req.NotifyIntervalMin,
+		EnableVolumeCheck: params.VolumeMultiplier > 0,
+		VolumeCheckMode:   string(indicators.VolumeCheckModeMultiplier),
+		VolumeMultiplier:  params.VolumeMultiplier,
+		TriggerMode:       req.TriggerMode,
+		ConfirmCloses:     req.ConfirmCloses,
+		MinPearsonR:       req.MinPearsonR,
+		SlopeFilter:       req.SlopeFilter,
+	}
+	return backtest.Subscription()
+}
+
+// sweepFolds 將搜尋範圍等分為 n 段，返回各段的起點與最後的終點
+// 訊號時間為 K 線收盤時間，最後一段包含結束時間
+func sweepFolds(start, end time.Time, n int) []time.Time {
+	bounds := make([]time.Time, n+1)
+	step := end.Sub(start) / time.Duration(n)
+	for i := range bounds {
+		bounds[i] = start.Add(time.Duration(i) * step)
+	}
+	bounds[n] = end.Add(time.Nanosecond)
+	return bounds
+}
+
+// sweepResult 統計一組參數的整體與各分段表現，horizon 為該週期的前瞻 K 線根數
+func sweepResult(req *models.SweepRequest, params models.SweepParams, signals []models.BacktestSignal, horizon int, folds []time.Time) models.SweepResult {
+	result := models.SweepResult{
+		Params:         params,
+		Signals:        len(signals),
+		FoldObjectives: make([]float64, len(folds)-1),
+		FoldSignals:    make([]int, len(folds)-1),
+		Eligible:       len(signals) >= req.MinSignals,
+	}
+
+	returns := horizonReturns(signals, horizon)
+	result.HitRate, result.ExpectancyPct = hitRateAndMean(returns)
+	result.SignalsPerWeek = perWeek(len(signals), folds[0], folds[len(folds)-1])
+	for _, signal := range signals {
+		result.AvgMAEPct += signal.MAEPct
+	}
+	if len(signals) > 0 {
+		result.AvgMAEPct /= float64(len(signals))
+	}
+	result.Objective = sweepObjective(req, signals, horizon, folds[0], folds[len(folds)-1])
+
+	for i := range result.FoldObjectives {
+		inFold := signalsBetween(signals, folds[i], folds[i+1])
+		result.FoldSignals[i] = len(inFold)
+		result.FoldObjectives[i] = sweepObjective(req, inFold, horizon, folds[i], folds[i+1])
+	}
+	return result
+}
+
+// walkForward 錨定式 walk-forward：以第 0~k-1 段挑出目標值最高的參數，在第 k 段驗證
+func walkForward(req *models.SweepRequest, results []models.SweepResult, signals [][]models.BacktestSignal, horizons map[string]int, folds []time.Time) ([]models.WalkForwardStep, float64) {
+	steps := []models.WalkForwardStep{}
+	total := 0.0
+	for k := 1; k < len(folds)-1; k++ {
+		// 訓練區間的最少訊號數依長度比例縮小
+		minTrain := max(1, req.MinSignals*k/req.Folds)
+		best, bestObjective := -1, math.Inf(-1)
+		for i := range results {
+			train := signalsBetween(signals[i], folds[0], folds[k])
+			if len(train) < minTrain {
+				continue
+			}
+			horizon := horizons[results[i].Params.Interval]
+			if objective := sweepObjective(req, train, horizon, folds[0], folds[k]); objective > bestObjective {
+				best, bestObjective = i, objective
+			}
+		}
+		if best < 0 {
+			continue
+		}
+
+		test := signalsBetween(signals[best], folds[k], folds[k+1])
+		step := models.WalkForwardStep{
+			Fold:           k,
+			Params:         results[best].Params,
+			TrainObjective: bestObjective,
+			TestObjective:  sweepObjective(req, test, horizons[results[best].Params.Interval], folds[k], folds[k+1]),
+			TestSignals:    len(test),
+		}
+		steps = append(steps, step)
+		total += step.TestObjective
+	}
+	if len(steps) == 0 {
+		return steps, 0
+	}
+	return steps, total / float64(len(steps))
+}
+
+// sweepObjective 計算一組訊號在 [from, to) 期間的目標值，horizon 為前瞻 K 線根數
+func sweepObjective(req *models.SweepRequest, signals []models.BacktestSignal, horizon int, from, to time.Time) float64 {
+	switch req.Objective {
+	case models.SweepObjectivePrecision:
+		hitRate, _ := hitRateAndMean(horizonReturns(signals, horizon))
+		return hitRate
+	case models.SweepObjectiveSignalsPerWeek:
+		return perWeek(len(signals), from, to)
+	default:
+		_, mean := hitRateAndMean(horizonReturns(signals, horizon))
+		return mean
+	}
+}
+
+// signalsBetween 篩選 [from, to) 期間的訊號
+func signalsBetween(signals []models.BacktestSignal, from, to time.Time) []models.BacktestSignal {
+	var out []models.BacktestSignal
+	for _, signal := range signals {
+		if !signal.Time.Before(from) && signal.Time.Before(to) {
+			out = append(out, signal)
+		}
+	}
+	return out
+}
+
+// horizonReturns 取出指定前瞻期間的報酬率，資料不足的訊號略過
+func horizonReturns(signals []models.BacktestSignal, horizon int) []float64 {
+	var returns []float64
+	for _, signal := range signals {
+		for _, r := range signal.Returns {
+			if r.Bars == horizon {
+				returns = append(returns, r.ReturnPct)
+			}
+		}
+	}
+	return returns
+}
+
+// hitRateAndMean 報酬為正的比例與平均報酬率
+func hitRateAndMean(returns []float64) (hitRate, mean float64) {
+	if len(returns) == 0 {
+		return 0, 0
+	}
+	hits := 0
+	for _, r := range returns {
+		if r > 0 {
+			hits++
+		}
+		mean += r
+	}
+	return float64(hits) / float64(len(returns)), mean / float64(len(returns))
+}
+
+// perWeek 換算為每週次數
+func perWeek(count int, from, to time.Time) float64 {
+	weeks := to.Sub(from).Hours() / (24 * 7)
+	if weeks <= 0 {
+		return 0
+	}
+	return float64(count) / weeks
+}
@@ -45,8 +45,7 @@ func NewTelegramService(botToken string, testMode bool, myChatID string) *Telegr
 	if testMode {
 		log.Info().Msg("TelegramService running in test mode (log only)")
 	} else if botToken == "" {
-		log.Warn().Msg("TelegramService: No bot token provided, notifications will be logged only")
-		service.testMode = true
+		log.Warn().Msg("TelegramService: No bot token provided, notifications are disabled")
 	} else {
 		log.Info().Msg("TelegramService initialized")
 	}
@@ -132,22 +131,28 @@ func (s *TelegramService) sendMessage(chatID string, text string) error {
 }
 
 // formatAlertMessage 格式化警報訊息
+// 沒有通道資訊的警報（價格、成交量警報）只列出有值的欄位
 func (s *TelegramService) formatAlertMessage(payload models.AlertPayload) string {
+	details := []string{fmt.Sprintf("幣種: <code>%s</code>", payload.Symbol)}
+	if payload.CurrentPrice > 0 {
+		details = append(details, fmt.Sprintf("當前價格: <code>%.2f</code>", payload.CurrentPrice))
+	}
+	if payload.UpperBand != 0 || payload.LowerBand != 0 {
+		details = append(details,
+			fmt.Sprintf("上軌: <code>%.2f</code>", payload.UpperBand),
+			fmt.Sprintf("下軌: <code>%.2f</code>", payload.LowerBand),
+		)
+	}
+
 	// 使用 HTML 格式
-	message := fmt.Sprintf(
-		"<b>%s</b>\n\n%s\n\n"+
-			"📊 <b>詳細資訊</b>\n"+
-			"├ 幣種: <code>%s</code>\n"+
-			"├ 當前價格: <code>%.2f</code>\n"+
-			"├ 上軌: <code>%.2f</code>\n"+
-			"└ 下軌: <code>%.2f</code>",
-		payload.Title,
-		payload.Body,
-		payload.Symbol,
-		payload.CurrentPrice,
-		payload.UpperBand,
-		payload.LowerBand,
-	)
+	message := fmt.Sprintf("<b>%s</b>\n\n%s\n\n📊 <b>詳細資訊</b>", payload.Title, payload.Body)
+	for i, line := range details {
+		prefix := "├"
+		if i == len(details)-1 {
+			prefix = "└"
+		}
+		message += fmt.Sprintf("\n%s %s", prefix, line)
+	}
 
 	return message
 }
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"cryptowatch/internal/expr"
+	"cryptowatch/internal/indicators"
+	"cryptowatch/internal/models"
+)
+
+// VolatilityService 已實現波動率服務
+type VolatilityService struct {
+	priceService *PriceService
+	config       indicators.VolatilityConfig
+}
+
+// NewVolatilityService 創建已實現波動率服務
+func NewVolatilityService(priceService *PriceService) *VolatilityService {
+	return &VolatilityService{
+		priceService: priceService,
+		config:       indicators.DefaultVolatilityConfig(),
+	}
+}
+
+// GetVolatility 獲取幣種在指定週期的已實現波動率與波動率狀態
+func (s *VolatilityService) GetVolatility(symbol, interval string) (*models.Volatility, error) {
+	if !expr.ValidInterval(interval) {
+		return nil, &models.ValidationError{Field: "interval", Message: fmt.Sprintf("無效的 K 線週期 %q", interval)}
+	}
+
+	klines, err := s.priceService.FetchKlines(symbol, interval, s.config.Window+s.config.History+1)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching klines: %v", err)
+	}
+	if n := len(klines); n > 0 && !IsKlineClosed(klines[n-1], time.Now()) {
+		klines = klines[:n-1]
+	}
+	return BuildVolatility(klines, interval, s.config)
+}
+
+// BuildVolatility 以已收盤的 K 線計算年化的已實現波動率
+func BuildVolatility(klines []KlineData, interval string, config indicators.VolatilityConfig) (*models.Volatility, error) {
+	result, err := indicators.CalculateVolatility(GetOpens(klines), GetHighs(klines), GetLows(klines), GetClosePrices(klines), config)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := IntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+	annualize := math.Sqrt(float64(365*24*time.Hour) / float64(d))
+
+	return &models.Volatility{
+		Interval:            interval,
+		CloseToClose:        result.CloseToClose * annualize,
+		Parkinson:           result.Parkinson * annualize,
+		GarmanKlass:         result.GarmanKlass * annualize,
+		Percentile:          result.Percentile,
+		Regime:              string(result.Regime),
+		BarsSinceCompressed: result.BarsSinceCompressed,
+	}, nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"cryptowatch/internal/expr"
+	"cryptowatch/internal/indicators"
+	"cryptowatch/internal/models"
+	"cryptowatch/internal/repository"
+)
+
+// VolumeProfileService 成交量分佈服務
+type VolumeProfileService struct {
+	repo         *repository.RedisRepository
+	priceService *PriceService
+	config       indicators.VolumeProfileConfig
+}
+
+// NewVolumeProfileService 創建成交量分佈服務
+func NewVolumeProfileService(repo *repository.RedisRepository, priceService *PriceService) *VolumeProfileService {
+	return &VolumeProfileService{
+		repo:         repo,
+		priceService: priceService,
+		config:       indicators.DefaultVolumeProfileConfig(),
+	}
+}
+
+// GetVolumeProfile 獲取成交量分佈，以已收盤的 K 線計算並快取到下一根 K 線收盤
+// 幣安 K 線不含逐筆成交的價格分佈，因此以 K 線的最高到最低範圍分配成交量
+func (s *VolumeProfileService) GetVolumeProfile(symbol, interval string, lookback int) (*models.VolumeProfile, error) {
+	if !expr.ValidInterval(interval) {
+		return nil, &models.ValidationError{Field: "interval", Message: fmt.Sprintf("無效的 K 線週期 %q", interval)}
+	}
+	if lookback <= 0 {
+		lookback = models.DefaultProfileLookback
+	}
+	if lookback > models.MaxProfileLookback {
+		return nil, &models.ValidationError{Field: "lookback", Message: fmt.Sprintf("不能超過 %d", models.MaxProfileLookback)}
+	}
+
+	if cached, err := s.repo.GetVolumeProfile(symbol, interval, lookback); err == nil {
+		return cached, nil
+	}
+
+	klines, err := s.priceService.FetchKlines(symbol, interval, lookback)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching klines: %v", err)
+	}
+	now := time.Now()
+	if n := len(klines); n > 0 && !IsKlineClosed(klines[n-1], now) {
+		klines = klines[:n-1]
+	}
+
+	result, err := indicators.CalculateVolumeProfile(GetHighs(klines), GetLows(klines), GetVolumes(klines), s.config)
+	if err != nil {
+		return nil, err
+	}
+
+	price, err := s.priceService.FetchCurrentPrice(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("error getting current price: %v", err)
+	}
+
+	next, err := NextCandleClose(interval, now)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &models.VolumeProfile{
+		Symbol:          symbol,
+		Interval:        interval,
+		Lookback:        lookback,
+		Price:           price,
+		POC:             result.POC,
+		ValueAreaHigh:   result.ValueAreaHigh,
+		ValueAreaLow:    result.ValueAreaLow,
+		HighVolumeNodes: result.HighVolumeNodes,
+		LowVolumeNodes:  result.LowVolumeNodes,
+		TotalVolume:     result.TotalVolume,
+		Bins:            make([]models.VolumeProfileBin, len(result.Bins)),
+		UpdatedAt:       now,
+		NextRefreshAt:   next,
+	}
+	for i, bin := range result.Bins {
+		profile.Bins[i] = models.VolumeProfileBin{Low: bin.Low, High: bin.High, Volume: bin.Volume}
+	}
+
+	if err := s.repo.SetVolumeProfile(profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// ValueAreaBreak 價格是否離開價值區，direction 為空時任一側都算
+func ValueAreaBreak(profile *models.VolumeProfile, direction string, price float64) bool {
+	switch direction {
+	case "above":
+		return price > profile.ValueAreaHigh
+	case "below":
+		return price < profile.ValueAreaLow
+	default:
+		return price > profile.ValueAreaHigh || price < profile.ValueAreaLow
+	}
+}
+
+// ValueAreaCrossed 價格是否自上次檢查後離開價值區，返回穿越時的極值
+// prev 為上次檢查的價格，high、low 為這段期間（含最新價格）的最高與最低價
+func ValueAreaCrossed(profile *models.VolumeProfile, direction string, prev, high, low float64) (float64, bool) {
+	if direction != "below" && prev <= profile.ValueAreaHigh && high > profile.ValueAreaHigh {
+		return high, true
+	}
+	if direction != "above" && prev >= profile.ValueAreaLow && low < profile.ValueAreaLow {
+		return low, true
+	}
+	return 0, false
+}
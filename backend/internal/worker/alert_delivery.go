@@ -0,0 +1,275 @@
+package worker
+
+import (
+	"fmt"
+	"time"
+
+	"cryptowatch/internal/models"
+	"cryptowatch/internal/repository"
+	"cryptowatch/internal/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// alertDeliveryTimeout 觸發後持續重試通知的時間，逾時仍未送達則放棄並刪除警報
+const alertDeliveryTimeout = 24 * time.Hour
+
+// alertDelivery 價格與成交量警報共用的觸發與通知流程
+// 觸發時先保存觸發狀態，所有通知對象都送達後才刪除警報，送達失敗則於下次檢查重試
+// 重複警報送達後不刪除，而是停用至價格回到遲滯區間外
+// 設定生效時段的警報在安靜時段內不觸發，或觸發後延到下一個生效時段才送出通知
+type alertDelivery struct {
+	repo                *repository.RedisRepository
+	notificationService *service.NotificationService
+	historyService      *service.AlertHistoryService
+}
+
+// trigger 記錄警報觸發並送出通知
+func (d alertDelivery) trigger(alert *models.Alert, value float64) {
+	now := time.Now()
+	alert.DeliverAfter = nil
+	if alert.InQuietHours(now) {
+		if !alert.Schedule.DeferQuiet {
+			// 安靜時段略過觸發，只保存檢查狀態
+			log.Debug().Str("alertId", alert.AlertID).Float64("value", value).Msg("Alert trigger skipped in quiet hours")
+			d.save(alert, "Error saving alert state")
+			return
+		}
+		deliverAfter := alert.Schedule.NextActive(now)
+		alert.DeliverAfter = &deliverAfter
+	}
+	alert.TriggeredAt = &now
+	alert.TriggerValue = value
+	alert.TriggerCount++
+	alert.EventID = ""
+
+	// 先保存觸發狀態，警報已被刪除時不記錄也不通知
+	if !d.save(alert, "Error saving triggered alert") {
+		return
+	}
+
+	// 記錄觸發歷史，送達結果於每次送出後更新
+	if event, err := d.historyService.RecordTrigger(alert); err != nil {
+		log.Error().Err(err).Str("alertId", alert.AlertID).Msg("Error recording alert event")
+	} else {
+		alert.EventID = event.EventID
+		d.save(alert, "Error saving triggered alert")
+	}
+	d.deliver(alert)
+}
+
+// deliver 送出尚未送達的通知，全部送達後刪除警報
+func (d alertDelivery) deliver(alert *models.Alert) {
+	// 安靜時段觸發的通知等到生效時段開始才送出
+	start := *alert.TriggeredAt
+	if alert.DeliverAfter != nil {
+		if time.Now().Before(*alert.DeliverAfter) {
+			return
+		}
+		start = *alert.DeliverAfter
+	}
+
+	payload := alertPayload(alert)
+
+	pending := 0
+	results := make(map[int]error)
+	for i := range alert.Targets {
+		target := &alert.Targets[i]
+		if target.Delivered {
+			continue
+		}
+		err := d.notificationService.Send(*target, payload)
+		results[i] = err
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("alertId", alert.AlertID).
+				Str("channel", target.Channel).
+				Msg("Error sending alert notification")
+			pending++
+			continue
+		}
+		target.Delivered = true
+	}
+
+	completed := pending == 0 || time.Since(start) >= alertDeliveryTimeout
+	if err := d.historyService.RecordDelivery(alert, results, completed); err != nil {
+		log.Error().Err(err).Str("alertId", alert.AlertID).Msg("Error recording alert delivery")
+	}
+
+	if !completed {
+		d.save(alert, "Error saving alert delivery state")
+		return
+	}
+	if pending > 0 {
+		log.Error().
+			Str("alertId", alert.AlertID).
+			Int("pending", pending).
+			Msg("Giving up alert notification after delivery timeout")
+	} else {
+		log.Info().
+			Str("alertId", alert.AlertID).
+			Str("symbol", alert.Symbol).
+			Int("targets", len(alert.Targets)).
+			Msg("Alert delivered")
+	}
+
+	if alert.Repeat && !alert.IsExhausted() && !alert.IsExpired(time.Now()) {
+		d.disarm(alert)
+		return
+	}
+	d.repo.DeleteAlert(alert.AlertID)
+}
+
+// disarm 重複警報送達後停用，等待重新啟用
+func (d alertDelivery) disarm(alert *models.Alert) {
+	alert.LastTriggeredAt = alert.TriggeredAt
+	alert.TriggeredAt = nil
+	alert.DeliverAfter = nil
+	alert.Disarmed = true
+	for i := range alert.Targets {
+		alert.Targets[i].Delivered = false
+	}
+	d.save(alert, "Error saving disarmed alert")
+}
+
+// rearm 重新啟用停用中的重複警報
+func (d alertDelivery) rearm(alert *models.Alert, value float64) {
+	alert.Disarmed = false
+	if !d.save(alert, "Error saving re-armed alert") {
+		return
+	}
+	log.Info().
+		Str("alertId", alert.AlertID).
+		Str("symbol", alert.Symbol).
+		Float64("value", value).
+		Int("trigger_count", alert.TriggerCount).
+		Msg("Alert re-armed")
+}
+
+// save 寫回警報的檢查與觸發狀態，返回警報是否仍存在；已被用戶刪除的警報不會被重新寫回
+// level 警報的目標價由 LevelMonitor 維護，未觸發時保留儲存中最新的目標價
+func (d alertDelivery) save(alert *models.Alert, errMsg string) bool {
+	saved, err := d.repo.UpdateAlertState(alert.AlertID, func(stored *models.Alert) bool {
+		targetPrice, levelPrice := stored.TargetPrice, stored.LevelPrice
+		*stored = *alert
+		if stored.AlertType == models.AlertTypeLevel && !stored.IsTriggered() {
+			stored.TargetPrice, stored.LevelPrice = targetPrice, levelPrice
+		}
+		return true
+	})
+	if err != nil {
+		log.Error().Err(err).Str("alertId", alert.AlertID).Msg(errMsg)
+		return false
+	}
+	if !saved {
+		log.Debug().Str("alertId", alert.AlertID).Msg("Alert deleted while being checked")
+	}
+	return saved
+}
+
+// lapse 刪除已到期未觸發的警報並通知用戶，通知只嘗試一次
+func (d alertDelivery) lapse(alert *models.Alert) {
+	// 先刪除，警報已被用戶刪除時不再通知
+	deleted, err := d.repo.DeleteAlertIfExists(alert.AlertID)
+	if err != nil {
+		log.Error().Err(err).Str("alertId", alert.AlertID).Msg("Error deleting expired alert")
+		return
+	}
+	if !deleted {
+		return
+	}
+
+	payload := models.AlertPayload{
+		Symbol: alert.Symbol,
+		Type:   alert.AlertType,
+		Title:  fmt.Sprintf("⌛ %s 警報已到期", alert.Symbol),
+		Body:   fmt.Sprintf("%s 警報（建立於 %s）已於 %s 到期", alertTypeLabel(alert), alert.CreatedAt.UTC().Format(time.RFC3339), alert.ExpiresAt.UTC().Format(time.RFC3339)),
+	}
+	if alert.TriggerCount > 0 {
+		payload.Body += fmt.Sprintf("，期間共觸發 %d 次", alert.TriggerCount)
+	}
+
+	for _, target := range alert.Targets {
+		if err := d.notificationService.Send(target, payload); err != nil {
+			log.Error().
+				Err(err).
+				Str("alertId", alert.AlertID).
+				Str("channel", target.Channel).
+				Msg("Error sending alert expiry notification")
+		}
+	}
+
+	log.Info().Str("alertId", alert.AlertID).Str("symbol", alert.Symbol).Msg("Alert expired")
+}
+
+// alertTypeLabel 警報類型的顯示名稱
+func alertTypeLabel(alert *models.Alert) string {
+	switch alert.AlertType {
+	case "volume":
+		return "成交量"
+	case models.AlertTypeLevel:
+		return "支撐壓力位"
+	case models.AlertTypeValueArea:
+		return "價值區"
+	case models.AlertTypePercentMove:
+		return "漲跌幅"
+	case models.AlertTypeVelocity:
+		return "速度"
+	case models.AlertTypeTrailingStop:
+		return "追蹤停損"
+	default:
+		return fmt.Sprintf("價格 %.2f", alert.TargetPrice)
+	}
+}
+
+// alertPayload 依警報類型產生通知內容
+func alertPayload(alert *models.Alert) models.AlertPayload {
+	payload := models.AlertPayload{
+		Symbol:       alert.Symbol,
+		Type:         alert.AlertType,
+		CurrentPrice: alert.TriggerValue,
+	}
+
+	action := "突破"
+	if alert.Direction == "below" {
+		action = "跌破"
+	}
+
+	switch alert.AlertType {
+	case models.AlertTypePercentMove, models.AlertTypeVelocity:
+		payload.CurrentPrice = 0
+		move := "急漲 🚀"
+		if alert.TriggerValue < 0 {
+			move = "急跌 💥"
+		}
+		payload.Title = fmt.Sprintf("⚡ %s %s", alert.Symbol, move)
+		payload.Body = fmt.Sprintf("%d 分鐘內漲跌 %+.2f%%（門檻 %.2f%%）", alert.TimeWindow, alert.TriggerValue, alert.MovePct)
+		if alert.AlertType == models.AlertTypeVelocity {
+			payload.Body = fmt.Sprintf("%d 分鐘平均速度 %+.2f%%/分鐘（門檻 %.2f%%/分鐘）", alert.TimeWindow, alert.TriggerValue, alert.VelocityPct)
+		}
+	case models.AlertTypeTrailingStop:
+		payload.Title = fmt.Sprintf("🛑 %s 追蹤停損", alert.Symbol)
+		payload.Body = fmt.Sprintf("價格 %.2f 自最高價 %.2f 回撤，觸及追蹤停損價 %.2f", alert.TriggerValue, alert.TrailExtreme, alert.TrailStopPrice)
+		if alert.Direction == models.TrailDirectionShort {
+			payload.Body = fmt.Sprintf("價格 %.2f 自最低價 %.2f 反彈，觸及追蹤停損價 %.2f", alert.TriggerValue, alert.TrailExtreme, alert.TrailStopPrice)
+		}
+	case "volume":
+		payload.CurrentPrice = 0
+		payload.Title = fmt.Sprintf("📊 %s 成交量警報", alert.Symbol)
+		payload.Body = fmt.Sprintf("%d 分鐘成交量 %.2f 已達目標 %.2f", alert.TimeWindow, alert.TriggerValue, alert.TargetVolume)
+	case models.AlertTypeLevel:
+		payload.Title = fmt.Sprintf("🧱 %s 支撐壓力位警報", alert.Symbol)
+		payload.Body = fmt.Sprintf("價格 %.2f %s %s 支撐壓力區 %.2f", alert.TriggerValue, action, alert.LevelInterval, alert.TargetPrice)
+	case models.AlertTypeValueArea:
+		payload.Title = fmt.Sprintf("📦 %s 價值區警報", alert.Symbol)
+		payload.Body = fmt.Sprintf("價格 %.2f 離開 %s 成交量分佈價值區", alert.TriggerValue, alert.ProfileInterval)
+		if alert.Direction != "" {
+			payload.Body = fmt.Sprintf("價格 %.2f %s %s 成交量分佈價值區", alert.TriggerValue, action, alert.ProfileInterval)
+		}
+	default:
+		payload.Title = fmt.Sprintf("🔔 %s 價格警報", alert.Symbol)
+		payload.Body = fmt.Sprintf("價格 %.2f 已%s目標價 %.2f", alert.TriggerValue, action, alert.TargetPrice)
+	}
+	return payload
+}
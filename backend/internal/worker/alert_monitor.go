@@ -2,19 +2,50 @@ package worker
 
 import (
 	"context"
+	"math"
 	"time"
 
+	"cryptowatch/internal/models"
 	"cryptowatch/internal/repository"
+	"cryptowatch/internal/service"
 
 	"github.com/rs/zerolog/log"
 )
 
+// crossLookback 補抓輪詢間隔內 K 線的最長期間，停機過久時只回看這段時間
+const crossLookback = time.Hour
+
 type AlertMonitor struct {
-	repo *repository.RedisRepository
+	repo           *repository.RedisRepository
+	priceService   *service.PriceService
+	profileService *service.VolumeProfileService
+	delivery       alertDelivery
+
+	// 追蹤停損 atr 模式使用的 ATR，K 線收盤後才重新計算
+	atrs map[atrKey]cachedATR
 }
 
-func NewAlertMonitor(repo *repository.RedisRepository) *AlertMonitor {
-	return &AlertMonitor{repo: repo}
+// atrKey 幣種、K 線週期與 ATR 週期
+type atrKey struct {
+	symbol   string
+	interval string
+	period   int
+}
+
+// cachedATR 快取的 ATR 與下次重新計算的時間
+type cachedATR struct {
+	value   float64
+	refresh time.Time
+}
+
+func NewAlertMonitor(repo *repository.RedisRepository, priceService *service.PriceService, profileService *service.VolumeProfileService, notificationService *service.NotificationService, historyService *service.AlertHistoryService) *AlertMonitor {
+	return &AlertMonitor{
+		repo:           repo,
+		priceService:   priceService,
+		profileService: profileService,
+		delivery:       alertDelivery{repo: repo, notificationService: notificationService, historyService: historyService},
+		atrs:           make(map[atrKey]cachedATR),
+	}
 }
 
 func (w *AlertMonitor) Start(ctx context.Context) error {
@@ -41,8 +72,22 @@ func (w *AlertMonitor) checkAlerts() {
 		return
 	}
 
+	now := time.Now()
+	windows := w.klineWindows(alerts, now)
+
 	for _, alert := range alerts {
-		if alert.AlertType != "price" && alert.AlertType != "" {
+		// level 警報的目標價由 LevelMonitor 維護，觸發方式與價格警報相同
+		if !isPriceAlert(alert) {
+			continue
+		}
+
+		// 已觸發但通知尚未全部送達，重試通知
+		if alert.IsTriggered() {
+			w.delivery.deliver(alert)
+			continue
+		}
+		// 到期的警報由 AlertSweeper 通知用戶並刪除
+		if alert.IsExpired(now) {
 			continue
 		}
 
@@ -51,20 +96,192 @@ func (w *AlertMonitor) checkAlerts() {
 			continue
 		}
 
-		shouldTrigger := false
-		if alert.Direction == "above" && price.Price >= alert.TargetPrice {
-			shouldTrigger = true
-		} else if alert.Direction == "below" && price.Price <= alert.TargetPrice {
-			shouldTrigger = true
+		// 上次檢查到現在的最高/最低價，包含輪詢間隔內的 K 線，避免漏掉短暫的插針
+		prev, lastChecked := alert.LastPrice, alert.LastCheckedAt
+		high, low := updatePriceRange(alert, windows[alert.Symbol], price.Price)
+		alert.LastPrice = price.Price
+		alert.LastCheckedAt = &now
+
+		// 第一次檢查只記錄價格，不因建立時已在目標另一側而立即觸發
+		if lastChecked == nil {
+			w.saveAlert(alert)
+			continue
+		}
+
+		// 重複警報觸發後停用，回到遲滯區間外才重新啟用
+		if alert.Disarmed {
+			if w.shouldRearm(alert, price.Price) {
+				w.delivery.rearm(alert, price.Price)
+			} else {
+				w.saveAlert(alert)
+			}
+			continue
+		}
+
+		var value float64
+		var crossed bool
+		switch alert.AlertType {
+		case models.AlertTypeValueArea:
+			value, crossed = w.checkValueArea(alert, prev, high, low)
+		case models.AlertTypeTrailingStop:
+			value, crossed = w.checkTrailingStop(alert, high, low, price.Price, now)
+		default:
+			value, crossed = alert.Crossed(prev, high, low)
+		}
+
+		if !crossed {
+			w.saveAlert(alert)
+			continue
+		}
+
+		log.Info().
+			Str("symbol", alert.Symbol).
+			Float64("previous_price", prev).
+			Float64("current_price", price.Price).
+			Float64("cross_price", value).
+			Float64("target_price", alert.TargetPrice).
+			Msg("Alert triggered")
+		w.delivery.trigger(alert, value)
+	}
+}
+
+// isPriceAlert 是否為以價格判斷的警報
+func isPriceAlert(alert *models.Alert) bool {
+	switch alert.AlertType {
+	case "price", "", models.AlertTypeLevel, models.AlertTypeValueArea, models.AlertTypeTrailingStop:
+		return true
+	}
+	return false
+}
+
+// klineWindows 抓取各幣種自最早一次檢查以來的 1 分鐘 K 線
+func (w *AlertMonitor) klineWindows(alerts []*models.Alert, now time.Time) map[string][]service.KlineData {
+	since := make(map[string]time.Time)
+	for _, alert := range alerts {
+		if !isPriceAlert(alert) || alert.IsTriggered() || alert.LastCheckedAt == nil {
+			continue
+		}
+		if t, ok := since[alert.Symbol]; !ok || alert.LastCheckedAt.Before(t) {
+			since[alert.Symbol] = *alert.LastCheckedAt
+		}
+	}
+
+	windows := make(map[string][]service.KlineData, len(since))
+	for symbol, start := range since {
+		if earliest := now.Add(-crossLookback); start.Before(earliest) {
+			start = earliest
+		}
+		klines, err := w.priceService.FetchKlinesRange(symbol, "1m", start.Truncate(time.Minute), now)
+		if err != nil {
+			// 抓不到 K 線時只用最新價格判斷
+			log.Warn().Err(err).Str("symbol", symbol).Msg("Error fetching klines for cross detection")
+			continue
+		}
+		windows[symbol] = klines
+	}
+	return windows
+}
+
+// updatePriceRange 上次檢查之後的最高與最低價（含最新價格），並記錄目前未收盤 K 線的高低點
+// 上次檢查時未收盤的 K 線只計入超過當時記錄的高低點，之前已存在的插針不會重複觸發
+func updatePriceRange(alert *models.Alert, klines []service.KlineData, price float64) (high, low float64) {
+	high, low = price, price
+	if alert.LastCheckedAt == nil {
+		klines = nil
+	}
+
+	var since int64
+	if alert.LastCheckedAt != nil {
+		since = alert.LastCheckedAt.UnixMilli()
+	}
+	var current *service.KlineData
+	for i, k := range klines {
+		if k.CloseTime < since {
+			continue
+		}
+		current = &klines[i]
+		switch {
+		case k.OpenTime == alert.LastKlineOpen:
+			if k.High > alert.LastKlineHigh {
+				high = math.Max(high, k.High)
+			}
+			if k.Low < alert.LastKlineLow {
+				low = math.Min(low, k.Low)
+			}
+		case k.OpenTime <= since:
+			// 沒有上次檢查時的高低點，無法分辨插針發生在檢查前或後，略過
+		default:
+			high = math.Max(high, k.High)
+			low = math.Min(low, k.Low)
+		}
+	}
+
+	alert.LastKlineOpen, alert.LastKlineHigh, alert.LastKlineLow = 0, 0, 0
+	if current != nil {
+		alert.LastKlineOpen = current.OpenTime
+		alert.LastKlineHigh = current.High
+		alert.LastKlineLow = current.Low
+	}
+	return high, low
+}
+
+// saveAlert 保存最新的檢查狀態，已被刪除的警報不會被重新寫回
+func (w *AlertMonitor) saveAlert(alert *models.Alert) {
+	w.delivery.save(alert, "Error saving alert state")
+}
+
+// shouldRearm 停用中的重複警報是否可以重新啟用，value_area 警報在價格回到價值區內時重新啟用
+func (w *AlertMonitor) shouldRearm(alert *models.Alert, price float64) bool {
+	if alert.AlertType == models.AlertTypeValueArea {
+		profile, err := w.profileService.GetVolumeProfile(alert.Symbol, alert.ProfileInterval, alert.ProfileLookback)
+		if err != nil {
+			log.Error().Err(err).Str("symbol", alert.Symbol).Msg("Error fetching volume profile")
+			return false
 		}
+		return !service.ValueAreaBreak(profile, "", price)
+	}
+	return alert.ShouldRearm(price)
+}
+
+// checkValueArea 價格是否自上次檢查後離開成交量分佈的價值區
+func (w *AlertMonitor) checkValueArea(alert *models.Alert, prev, high, low float64) (float64, bool) {
+	profile, err := w.profileService.GetVolumeProfile(alert.Symbol, alert.ProfileInterval, alert.ProfileLookback)
+	if err != nil {
+		log.Error().Err(err).Str("symbol", alert.Symbol).Msg("Error fetching volume profile")
+		return 0, false
+	}
+	return service.ValueAreaCrossed(profile, alert.Direction, prev, high, low)
+}
 
-		if shouldTrigger {
-			log.Info().
-				Str("symbol", alert.Symbol).
-				Float64("current_price", price.Price).
-				Float64("target_price", alert.TargetPrice).
-				Msg("Alert triggered")
-			w.repo.DeleteAlert(alert.AlertID)
+// checkTrailingStop 更新追蹤停損的極值，並檢查價格是否回撤到停損價
+func (w *AlertMonitor) checkTrailingStop(alert *models.Alert, high, low, price float64, now time.Time) (float64, bool) {
+	var atr float64
+	if alert.TrailMode == models.TrailModeATR {
+		var ok bool
+		if atr, ok = w.atr(alert, now); !ok {
+			return 0, false
 		}
 	}
+	return alert.Trail(high, low, price, atr)
+}
+
+// atr 獲取追蹤停損使用的 ATR，計算失敗時沿用上次的值
+func (w *AlertMonitor) atr(alert *models.Alert, now time.Time) (float64, bool) {
+	key := atrKey{symbol: alert.Symbol, interval: alert.TrailATRInterval, period: alert.TrailATRPeriod}
+	cached, ok := w.atrs[key]
+	if ok && now.Before(cached.refresh) {
+		return cached.value, true
+	}
+
+	atr, err := w.priceService.FetchATR(key.symbol, key.interval, key.period)
+	if err != nil {
+		log.Error().Err(err).Str("symbol", key.symbol).Str("interval", key.interval).Msg("Error calculating ATR for trailing stop")
+		return cached.value, ok
+	}
+	d, err := service.IntervalDuration(key.interval)
+	if err != nil {
+		d = time.Minute
+	}
+	w.atrs[key] = cachedATR{value: atr, refresh: now.Truncate(d).Add(d)}
+	return atr, true
 }
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"cryptowatch/internal/repository"
+	"cryptowatch/internal/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// AlertSweeper 到期警報清理器
+// 刪除已到期且沒有待送通知的警報，並通知用戶警報已到期
+type AlertSweeper struct {
+	repo     *repository.RedisRepository
+	delivery alertDelivery
+}
+
+// NewAlertSweeper 創建到期警報清理器
+func NewAlertSweeper(repo *repository.RedisRepository, notificationService *service.NotificationService, historyService *service.AlertHistoryService) *AlertSweeper {
+	return &AlertSweeper{
+		repo:     repo,
+		delivery: alertDelivery{repo: repo, notificationService: notificationService, historyService: historyService},
+	}
+}
+
+// Start 啟動清理器
+func (w *AlertSweeper) Start(ctx context.Context) error {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	log.Info().Msg("Alert Sweeper Worker started")
+
+	w.sweepAlerts()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Alert Sweeper Worker stopped")
+			return ctx.Err()
+		case <-ticker.C:
+			w.sweepAlerts()
+		}
+	}
+}
+
+// sweepAlerts 清理所有已到期的警報，已觸發的警報等通知送達後由監控器處理
+func (w *AlertSweeper) sweepAlerts() {
+	alerts, err := w.repo.GetAllAlerts()
+	if err != nil {
+		log.Error().Err(err).Msg("Error fetching alerts")
+		return
+	}
+
+	now := time.Now()
+	for _, alert := range alerts {
+		if alert.IsTriggered() || !alert.IsExpired(now) {
+			continue
+		}
+		w.delivery.lapse(alert)
+	}
+}
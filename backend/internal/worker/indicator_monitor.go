@@ -3,8 +3,13 @@ package worker
 import (
 	"context"
 	"fmt"
+	"html"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	"cryptowatch/internal/expr"
 	"cryptowatch/internal/indicators"
 	"cryptowatch/internal/models"
 	"cryptowatch/internal/repository"
@@ -15,10 +20,24 @@ import (
 
 // IndicatorMonitor 技術指標監控器
 type IndicatorMonitor struct {
-	repo            *repository.RedisRepository
-	priceService    *service.PriceService
-	telegramService *service.TelegramService
-	config          models.IndicatorConfig
+	repo               *repository.RedisRepository
+	priceService       *service.PriceService
+	telegramService    *service.TelegramService
+	scriptService      *service.ScriptService
+	correlationService *service.CorrelationService
+	signalService      *service.SignalService
+	paperService       *service.PaperService
+	config             models.IndicatorConfig
+
+	// 成交量季節性基準快取
+	seasonalMu sync.Mutex
+	seasonal   map[string]seasonalEntry
+}
+
+// seasonalEntry 季節性基準快取項目，同一 UTC 小時內共用
+type seasonalEntry struct {
+	baseline []float64
+	hour     time.Time
 }
 
 // NewIndicatorMonitor 創建指標監控器
@@ -26,21 +45,34 @@ func NewIndicatorMonitor(
 	repo *repository.RedisRepository,
 	priceService *service.PriceService,
 	telegramService *service.TelegramService,
+	scriptService *service.ScriptService,
+	correlationService *service.CorrelationService,
+	signalService *service.SignalService,
+	paperService *service.PaperService,
 ) *IndicatorMonitor {
 	return &IndicatorMonitor{
-		repo:            repo,
-		priceService:    priceService,
-		telegramService: telegramService,
-		config:          models.DefaultIndicatorConfig(),
+		repo:               repo,
+		priceService:       priceService,
+		telegramService:    telegramService,
+		scriptService:      scriptService,
+		correlationService: correlationService,
+		signalService:      signalService,
+		paperService:       paperService,
+		config:             models.DefaultIndicatorConfig(),
+		seasonal:           make(map[string]seasonalEntry),
 	}
 }
 
 // Start 啟動監控
 func (w *IndicatorMonitor) Start(ctx context.Context) error {
-	// 每 30 秒檢查一次
+	// 每 30 秒檢查一次（盤中模式）
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 
+	// 收盤確認模式在各訂閱觸發週期的 K 線收盤時檢查，所有週期都在整分鐘收盤
+	closeTimer := time.NewTimer(untilNextMinute(time.Now()))
+	defer closeTimer.Stop()
+
 	log.Info().Msg("Indicator Monitor Worker started")
 
 	// 啟動時先執行一次
@@ -53,19 +85,68 @@ func (w *IndicatorMonitor) Start(ctx context.Context) error {
 			return ctx.Err()
 		case <-ticker.C:
 			w.checkAndNotify()
+		case <-closeTimer.C:
+			w.checkCandleClose(time.Now().Truncate(time.Minute))
+			closeTimer.Reset(untilNextMinute(time.Now()))
 		}
 	}
 }
 
-// checkAndNotify 檢查指標並發送通知
-func (w *IndicatorMonitor) checkAndNotify() {
-	// 獲取系統配置
+// loadConfig 獲取系統配置，失敗時使用預設配置
+func (w *IndicatorMonitor) loadConfig() *models.IndicatorConfig {
 	config, err := w.repo.GetIndicatorConfig()
 	if err != nil {
 		log.Error().Err(err).Msg("Error getting indicator config")
-		config = &w.config
+		return &w.config
+	}
+	return config
+}
+
+// untilNextMinute 距離下一個整分鐘的時間
+// 額外等待 candleCloseSettle，讓交易所完成收盤 K 線的寫入
+func untilNextMinute(now time.Time) time.Duration {
+	return now.Truncate(time.Minute).Add(time.Minute).Sub(now) + candleCloseSettle
+}
+
+// closedIntervals 在 closeTime 剛收盤的 K 線週期
+// 檢查 LRC 週期以及啟用中收盤確認訂閱的觸發週期（腳本、事件、背離、型態可各自設定週期）
+func (w *IndicatorMonitor) closedIntervals(config *models.IndicatorConfig, closeTime time.Time) []string {
+	intervals := []string{config.LRCInterval}
+	for _, symbol := range config.Symbols {
+		subscriptions, err := w.repo.GetSubscriptionsBySymbol(symbol)
+		if err != nil {
+			log.Error().Err(err).Str("symbol", symbol).Msg("Error getting subscriptions")
+			continue
+		}
+		for _, sub := range subscriptions {
+			interval := sub.TriggerInterval(config.LRCInterval)
+			if sub.Enabled && sub.IsCloseConfirmed() && !slices.Contains(intervals, interval) {
+				intervals = append(intervals, interval)
+			}
+		}
 	}
 
+	closed := make([]string, 0, len(intervals))
+	for _, interval := range intervals {
+		next, err := service.NextCandleClose(interval, closeTime.Add(-time.Millisecond))
+		if err != nil {
+			log.Error().Err(err).Str("interval", interval).Msg("Error scheduling candle close check")
+			continue
+		}
+		if next.Equal(closeTime) {
+			closed = append(closed, interval)
+		}
+	}
+	return closed
+}
+
+// candleCloseSettle 收盤後延遲檢查的時間
+const candleCloseSettle = 3 * time.Second
+
+// checkAndNotify 檢查指標並發送通知（盤中模式）
+func (w *IndicatorMonitor) checkAndNotify() {
+	config := w.loadConfig()
+
 	for _, symbol := range config.Symbols {
 		// 計算指標
 		result, err := w.calculateIndicators(symbol, config)
@@ -77,45 +158,548 @@ func (w *IndicatorMonitor) checkAndNotify() {
 		// 快取結果
 		w.repo.SetIndicatorResult(result)
 
-		// 檢查是否突破 LRC（必要條件）
-		if !result.IsAboveUpper && !result.IsBelowLower {
+		// 自訂條件式不以 LRC 突破為前提，每個幣種都需檢查
+		source := newKlineSource(symbol, w.priceService, result.CurrentPrice)
+		w.notifySubscribers(symbol, result, source, nil)
+	}
+}
+
+// checkCandleClose 在 K 線收盤時檢查指標（收盤確認模式）
+// closeTime: 本次檢查的整分鐘時間，只處理在這個時間收盤的週期
+func (w *IndicatorMonitor) checkCandleClose(closeTime time.Time) {
+	config := w.loadConfig()
+	closed := w.closedIntervals(config, closeTime)
+	if len(closed) == 0 {
+		return
+	}
+
+	for _, symbol := range config.Symbols {
+		// LRC 週期收盤時不使用快取，確保拿到剛收盤的 K 線
+		var result *models.IndicatorResult
+		var err error
+		if slices.Contains(closed, config.LRCInterval) {
+			result, err = w.computeIndicators(symbol, config)
+		} else {
+			result, err = w.calculateIndicators(symbol, config)
+		}
+		if err != nil {
+			log.Error().Err(err).Str("symbol", symbol).Msg("Error calculating indicators at candle close")
 			continue
 		}
 
-		// 獲取該幣種的所有訂閱者
-		subscriptions, err := w.repo.GetSubscriptionsBySymbol(symbol)
-		if err != nil {
-			log.Error().Err(err).Str("symbol", symbol).Msg("Error getting subscriptions")
+		w.repo.SetIndicatorResult(result)
+
+		source := newKlineSource(symbol, w.priceService, result.CurrentPrice)
+		w.notifySubscribers(symbol, result, source, closed)
+	}
+}
+
+// notifySubscribers 通知符合條件的訂閱者
+// source: 本次檢查共用的 K 線資料來源
+// closed: K 線收盤時的檢查為剛收盤的週期，只處理觸發週期在其中的收盤確認模式訂閱；盤中檢查為 nil
+func (w *IndicatorMonitor) notifySubscribers(symbol string, result *models.IndicatorResult, source *klineSource, closed []string) {
+	// 獲取該幣種的所有訂閱者
+	subscriptions, err := w.repo.GetSubscriptionsBySymbol(symbol)
+	if err != nil {
+		log.Error().Err(err).Str("symbol", symbol).Msg("Error getting subscriptions")
+		return
+	}
+
+	for _, sub := range subscriptions {
+		// 檢查開關
+		if !sub.Enabled {
 			continue
 		}
 
-		for _, sub := range subscriptions {
-			// 檢查開關
-			if !sub.Enabled {
+		// 盤中檢查只處理盤中模式，收盤檢查只處理觸發週期剛收盤的收盤確認模式
+		if sub.IsCloseConfirmed() != (closed != nil) {
+			continue
+		}
+		if closed != nil && !slices.Contains(closed, sub.TriggerInterval(w.loadConfig().LRCInterval)) {
+			continue
+		}
+
+		var alertType, side, detail string
+		var firedAt int64 // 背離轉折點或型態 K 線的開盤時間，通知後記錄於訂閱
+		switch {
+		case sub.ScriptID != "":
+			// 檢查 Pine Script 腳本的 alertcondition
+			var triggered bool
+			detail, triggered = w.evaluateScript(sub, source)
+			if !triggered {
+				continue
+			}
+			alertType = alertTypeScript
+			side = models.SignalSideNeutral
+		case len(sub.StudyEvents) > 0:
+			// 檢查一目均衡表／SAR 事件
+			var triggered bool
+			detail, side, triggered = w.evaluateStudies(sub, source)
+			if !triggered {
+				continue
+			}
+			alertType = alertTypeStudy
+		case len(sub.DivergenceTypes) > 0:
+			// 檢查價格與震盪指標的背離
+			var triggered bool
+			detail, side, firedAt, triggered = w.evaluateDivergence(sub, source)
+			if !triggered {
+				continue
+			}
+			alertType = alertTypeDivergence
+		case len(sub.PatternAlerts) > 0:
+			// 檢查 K 線型態：下一根收盤前都是同一根 K 線，已通知過的不再觸發
+			patterns, openTime, err := closedPatterns(source, sub.PatternInterval, patternConfig(sub))
+			if err != nil {
+				log.Warn().Err(err).Str("subscriptionId", sub.SubscriptionID).Msg("Error detecting candlestick patterns")
 				continue
 			}
+			if openTime == sub.LastPatternCandleAt {
+				continue
+			}
+			matched := matchPatterns(patterns, sub.PatternAlerts)
+			if len(matched) == 0 {
+				continue
+			}
+			alertType = alertTypePattern
+			side = patternSide(patterns, sub.PatternAlerts)
+			detail = sub.PatternInterval + " " + strings.Join(matched, "、")
+			firedAt = openTime
+		case sub.Condition != "":
+			// 檢查自訂條件式
+			if !w.evaluateCondition(sub, source) {
+				continue
+			}
+			alertType = alertTypeCondition
+			side = models.SignalSideNeutral
+			detail = sub.Condition
+		default:
+			// 檢查觸發條件
+			var triggered bool
+			alertType, triggered = sub.TriggerDirection(result)
+			if !triggered {
+				continue
+			}
+			side = models.SignalSide(alertType)
 
-			// 檢查冷卻時間
-			if w.isInCooldown(sub.SubscriptionID, sub.NotifyIntervalMin) {
+			// 檢查通道品質
+			if !sub.PassesChannelFilter(result, alertType) {
 				continue
 			}
 
-			// 檢查成交量條件（如果啟用）
-			if sub.EnableVolumeCheck {
-				if !w.checkVolumeCondition(result, sub) {
+			// 檢查突破前是否處於波動收斂
+			if !sub.PassesVolatilityFilter(result) {
+				continue
+			}
+
+			// 檢查 K 線型態過濾：最近一根已收盤的 LRC 週期 K 線須符合其中一種型態
+			if len(sub.PatternFilter) > 0 {
+				patterns, _, err := closedPatterns(source, w.loadConfig().LRCInterval, patternConfig(sub))
+				if err != nil {
+					log.Warn().Err(err).Str("subscriptionId", sub.SubscriptionID).Msg("Error detecting candlestick patterns")
 					continue
 				}
+				matched := matchPatterns(patterns, sub.PatternFilter)
+				if len(matched) == 0 {
+					continue
+				}
+				detail = strings.Join(matched, "、")
+			}
+		}
+
+		// 檢查多週期共振
+		agreed, ok := w.checkConfluence(sub, source, alertType)
+		if !ok {
+			continue
+		}
+
+		// 檢查冷卻時間
+		if w.isInCooldown(sub.SubscriptionID, sub.NotifyIntervalMin) {
+			continue
+		}
+
+		// 只是跟隨 BTC 波動時不通知
+		if sub.SuppressBTCDriven && w.isBTCDriven(sub) {
+			continue
+		}
+
+		// 檢查成交量條件（如果啟用）
+		if sub.EnableVolumeCheck {
+			if !w.checkVolumeCondition(result, sub) {
+				continue
+			}
+		}
+
+		// 發送通知
+		w.sendNotification(sub, result, alertNotice{
+			alertType:  alertType,
+			detail:     detail,
+			confluence: agreed,
+		})
+
+		// 記錄通知時間
+		w.recordNotification(sub.SubscriptionID)
+
+		// 記錄已通知的背離轉折點或型態 K 線，同一根 K 線內的後續檢查不再重複通知
+		if firedAt != 0 {
+			err := w.repo.UpdateSubscriptionState(sub.SubscriptionID, func(s *models.IndicatorSubscription) {
+				if alertType == alertTypeDivergence {
+					s.LastDivergencePivotAt = firedAt
+				} else {
+					s.LastPatternCandleAt = firedAt
+				}
+			})
+			if err != nil {
+				log.Error().Err(err).Str("subscriptionId", sub.SubscriptionID).Msg("Error saving fired candle")
+			}
+		}
+
+		// 記錄訊號，之後追蹤後續價格
+		if _, err := w.signalService.RecordSignal(sub, result, alertType, side); err != nil {
+			log.Error().Err(err).Str("subscriptionId", sub.SubscriptionID).Msg("Error recording signal")
+		}
+
+		// 開立模擬部位（如果啟用）
+		if sub.PaperTrading.Enabled {
+			interval := sub.TriggerInterval(w.loadConfig().LRCInterval)
+			if _, err := w.paperService.OnSignal(sub, result, side, interval); err != nil {
+				log.Error().Err(err).Str("subscriptionId", sub.SubscriptionID).Msg("Error opening paper position")
+			}
+		}
+	}
+}
+
+// checkConfluence 檢查訂閱的多週期共振條件，返回成立的條件說明
+// 各週期的 K 線與 LRC 由 source 快取，同一幣種在一次檢查中只計算一次
+func (w *IndicatorMonitor) checkConfluence(sub *models.IndicatorSubscription, source *klineSource, alertType string) ([]string, bool) {
+	if len(sub.Confluence) == 0 {
+		return nil, true
+	}
+
+	config := w.loadConfig()
+	agreed := make([]string, 0, len(sub.Confluence))
+	for _, rule := range sub.Confluence {
+		ch, err := source.Channel(rule.Interval, config.LRCLength, config.LRCDevMultiplier)
+		if err != nil {
+			log.Warn().Err(err).Str("subscriptionId", sub.SubscriptionID).Str("interval", rule.Interval).Msg("Error calculating confluence channel")
+			return nil, false
+		}
+
+		// aligned 條件依突破方向轉換為對應的上升／下降條件
+		condition := rule.Condition
+		switch condition {
+		case models.ConfluenceSlopeAligned:
+			condition = models.ConfluenceSlopeDown
+			if alertType == alertTypeAboveUpper {
+				condition = models.ConfluenceSlopeUp
+			}
+		case models.ConfluenceCenterAligned:
+			condition = models.ConfluenceBelowCenter
+			if alertType == alertTypeAboveUpper {
+				condition = models.ConfluenceAboveCenter
+			}
+		}
+
+		var ok bool
+		var label string
+		switch condition {
+		case models.ConfluenceSlopeUp:
+			ok, label = ch.lrc.Slope > 0, "斜率↑"
+		case models.ConfluenceSlopeDown:
+			ok, label = ch.lrc.Slope < 0, "斜率↓"
+		case models.ConfluenceAboveCenter:
+			ok, label = ch.lastClose > ch.lrc.CenterLine, "收盤>中線"
+		case models.ConfluenceBelowCenter:
+			ok, label = ch.lastClose < ch.lrc.CenterLine, "收盤<中線"
+		}
+		if !ok {
+			return nil, false
+		}
+		agreed = append(agreed, rule.Interval+" "+label)
+	}
+	return agreed, true
+}
+
+// evaluateCondition 對訂閱的自訂條件式求值
+func (w *IndicatorMonitor) evaluateCondition(sub *models.IndicatorSubscription, source *klineSource) bool {
+	program, err := expr.Compile(sub.Condition)
+	if err != nil {
+		log.Error().Err(err).Str("subscriptionId", sub.SubscriptionID).Msg("Invalid subscription condition")
+		return false
+	}
+
+	ok, err := program.Eval(source)
+	if err != nil {
+		log.Warn().Err(err).Str("subscriptionId", sub.SubscriptionID).Msg("Error evaluating subscription condition")
+		return false
+	}
+	return ok
+}
+
+// evaluateScript 在訂閱的 K 線週期上執行腳本，返回觸發說明
+// 盤中模式看最新一根（含未收盤）；收盤確認模式需最近已收盤的 RequiredCloses 根皆成立
+func (w *IndicatorMonitor) evaluateScript(sub *models.IndicatorSubscription, source *klineSource) (string, bool) {
+	script, err := w.scriptService.GetScript(sub.ScriptID)
+	if err != nil {
+		log.Error().Err(err).Str("subscriptionId", sub.SubscriptionID).Str("scriptId", sub.ScriptID).Msg("Error getting subscription script")
+		return "", false
+	}
+
+	klines, err := subscriptionKlines(sub, source, sub.ScriptInterval)
+	if err != nil {
+		log.Warn().Err(err).Str("subscriptionId", sub.SubscriptionID).Msg("Error fetching script klines")
+		return "", false
+	}
+	if len(klines) == 0 {
+		return "", false
+	}
+
+	result, err := w.scriptService.Run(script, klines, sub.ScriptInputs)
+	if err != nil {
+		log.Warn().Err(err).Str("subscriptionId", sub.SubscriptionID).Str("scriptId", sub.ScriptID).Msg("Error running subscription script")
+		return "", false
+	}
+
+	alert, ok := result.Alert(sub.ScriptAlert)
+	if !ok {
+		log.Error().Str("subscriptionId", sub.SubscriptionID).Str("alert", sub.ScriptAlert).Msg("Script alertcondition not found")
+		return "", false
+	}
+
+	required := 1
+	if sub.IsCloseConfirmed() {
+		required = sub.RequiredCloses()
+	}
+	if len(alert.Values) < required {
+		return "", false
+	}
+	for _, fired := range alert.Values[len(alert.Values)-required:] {
+		if !fired {
+			return "", false
+		}
+	}
+
+	detail := script.Name + " / " + alert.Title
+	if alert.Message != "" {
+		detail += "：" + alert.Message
+	}
+	return detail, true
+}
+
+// evaluateStudies 檢查訂閱的一目均衡表／SAR 事件，返回發生的事件說明與共同的方向
+// 盤中模式以未收盤的最新一根判斷；收盤確認模式以最近一根已收盤 K 線判斷
+func (w *IndicatorMonitor) evaluateStudies(sub *models.IndicatorSubscription, source *klineSource) (string, string, bool) {
+	klines, err := subscriptionKlines(sub, source, sub.StudyInterval)
+	if err != nil {
+		log.Warn().Err(err).Str("subscriptionId", sub.SubscriptionID).Msg("Error fetching study klines")
+		return "", "", false
+	}
+	highs, lows, closes := service.GetHighs(klines), service.GetLows(klines), service.GetClosePrices(klines)
+
+	var ichimoku indicators.IchimokuResult
+	var psar indicators.PSARResult
+	var hasIchimoku, hasPSAR bool
+	fired := make([]string, 0, len(sub.StudyEvents))
+	sides := make([]string, 0, len(sub.StudyEvents))
+
+	for _, event := range sub.StudyEvents {
+		if models.IsIchimokuEvent(event) && !hasIchimoku {
+			ichimoku, err = indicators.CalculateIchimoku(highs, lows, closes, indicators.DefaultIchimokuConfig())
+			if err != nil {
+				log.Warn().Err(err).Str("subscriptionId", sub.SubscriptionID).Msg("Error calculating Ichimoku")
+				return "", "", false
+			}
+			hasIchimoku = true
+		}
+		if !models.IsIchimokuEvent(event) && !hasPSAR {
+			psar, err = indicators.CalculatePSAR(highs, lows, closes, indicators.DefaultPSARConfig())
+			if err != nil {
+				log.Warn().Err(err).Str("subscriptionId", sub.SubscriptionID).Msg("Error calculating PSAR")
+				return "", "", false
 			}
+			hasPSAR = true
+		}
+
+		switch {
+		case event == models.StudyEventCloudBreakUp && ichimoku.CloudBreakUp:
+			fired = append(fired, fmt.Sprintf("突破雲上緣 %.2f", ichimoku.CloudTop()))
+			sides = append(sides, models.SignalSideLong)
+		case event == models.StudyEventCloudBreakDown && ichimoku.CloudBreakDown:
+			fired = append(fired, fmt.Sprintf("跌破雲下緣 %.2f", ichimoku.CloudBottom()))
+			sides = append(sides, models.SignalSideShort)
+		case event == models.StudyEventTKCrossBull && ichimoku.TKCrossBull:
+			fired = append(fired, fmt.Sprintf("轉換線 %.2f 上穿基準線 %.2f", ichimoku.Tenkan, ichimoku.Kijun))
+			sides = append(sides, models.SignalSideLong)
+		case event == models.StudyEventTKCrossBear && ichimoku.TKCrossBear:
+			fired = append(fired, fmt.Sprintf("轉換線 %.2f 下穿基準線 %.2f", ichimoku.Tenkan, ichimoku.Kijun))
+			sides = append(sides, models.SignalSideShort)
+		case event == models.StudyEventSARFlipUp && psar.FlippedUp:
+			fired = append(fired, fmt.Sprintf("SAR 翻多 %.2f", psar.SAR))
+			sides = append(sides, models.SignalSideLong)
+		case event == models.StudyEventSARFlipDown && psar.FlippedDown:
+			fired = append(fired, fmt.Sprintf("SAR 翻空 %.2f", psar.SAR))
+			sides = append(sides, models.SignalSideShort)
+		}
+	}
+
+	if len(fired) == 0 {
+		return "", "", false
+	}
+	return sub.StudyInterval + " " + strings.Join(fired, "、"), models.CommonSignalSide(sides), true
+}
+
+// evaluateDivergence 偵測訂閱的背離類型，返回兩個轉折點的說明、背離方向與最新轉折點的 K 線開盤時間
+// 轉折點需要右側 K 線確認，因此一律只使用已收盤的 K 線；盤中檢查在下一根收盤前看到的都是同一組轉折點，
+// 已通知過的轉折點（LastDivergencePivotAt）不再觸發
+func (w *IndicatorMonitor) evaluateDivergence(sub *models.IndicatorSubscription, source *klineSource) (string, string, int64, bool) {
+	klines, err := source.ClosedKlines(sub.DivergenceInterval)
+	if err != nil {
+		log.Warn().Err(err).Str("subscriptionId", sub.SubscriptionID).Msg("Error fetching divergence klines")
+		return "", "", 0, false
+	}
+
+	closes := service.GetClosePrices(klines)
+	oscillatorName := "RSI"
+	var oscillator []float64
+	switch sub.DivergenceOscillator {
+	case models.DivergenceOscillatorMACD:
+		oscillatorName = "MACD"
+		oscillator = indicators.MACDHistogramSeries(closes, 12, 26, 9)
+	default:
+		oscillator = indicators.RSISeries(closes, 14)
+	}
+
+	config := indicators.DefaultDivergenceConfig()
+	config.PivotLeft = sub.DivergencePivotLookback
+	config.PivotRight = sub.DivergencePivotLookback
+
+	divergences := indicators.DetectDivergences(service.GetHighs(klines), service.GetLows(klines), oscillator, config)
+	for _, d := range divergences {
+		if !slices.Contains(sub.DivergenceTypes, string(d.Type)) {
+			continue
+		}
+		pivotAt := klines[d.Latest.Index].OpenTime
+		if pivotAt == sub.LastDivergencePivotAt {
+			continue
+		}
+
+		pivotKind, side := "高點", models.SignalSideShort
+		if d.Type.IsBullish() {
+			pivotKind, side = "低點", models.SignalSideLong
+		}
+		prevTime := time.UnixMilli(klines[d.Previous.Index].OpenTime).UTC().Format("01-02 15:04")
+		lastTime := time.UnixMilli(klines[d.Latest.Index].OpenTime).UTC().Format("01-02 15:04")
+		detail := fmt.Sprintf("%s %s %s背離：%s %s %.2f (%s %.2f) → %s %.2f (%s %.2f) UTC",
+			sub.DivergenceInterval, oscillatorName, divergenceLabels[d.Type], pivotKind,
+			prevTime, d.Previous.Price, oscillatorName, d.Previous.Oscillator,
+			lastTime, d.Latest.Price, oscillatorName, d.Latest.Oscillator)
+		return detail, side, pivotAt, true
+	}
+	return "", "", 0, false
+}
+
+// divergenceLabels 背離類型的中文名稱
+var divergenceLabels = map[indicators.DivergenceType]string{
+	indicators.DivergenceRegularBullish: "常規看漲",
+	indicators.DivergenceRegularBearish: "常規看跌",
+	indicators.DivergenceHiddenBullish:  "隱藏看漲",
+	indicators.DivergenceHiddenBearish:  "隱藏看跌",
+}
+
+// closedPatterns 找出指定週期最近一根已收盤 K 線構成的型態，並返回該 K 線的開盤時間
+func closedPatterns(source *klineSource, interval string, config indicators.PatternConfig) ([]indicators.CandlePattern, int64, error) {
+	klines, err := source.ClosedKlines(interval)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(klines) == 0 {
+		return nil, 0, nil
+	}
+	return indicators.DetectPatterns(service.GetCandles(klines), config), klines[len(klines)-1].OpenTime, nil
+}
+
+// patternConfig 訂閱的 K 線型態判斷比例，未設定的比例使用預設值
+func patternConfig(sub *models.IndicatorSubscription) indicators.PatternConfig {
+	config := indicators.DefaultPatternConfig()
+	for _, ratio := range []struct {
+		dst *float64
+		src float64
+	}{
+		{&config.DojiBodyRatio, sub.PatternDojiBodyRatio},
+		{&config.HammerWickRatio, sub.PatternHammerWickRatio},
+		{&config.HammerShadowRatio, sub.PatternHammerShadowRatio},
+		{&config.StarBodyRatio, sub.PatternStarBodyRatio},
+		{&config.LongBodyRatio, sub.PatternLongBodyRatio},
+	} {
+		if ratio.src > 0 {
+			*ratio.dst = ratio.src
+		}
+	}
+	return config
+}
 
-			// 發送通知
-			w.sendNotification(sub, result)
+// matchPatterns 返回訂閱要求且實際出現的型態名稱
+func matchPatterns(patterns []indicators.CandlePattern, wanted []string) []string {
+	matched := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		if slices.Contains(wanted, string(p)) {
+			matched = append(matched, patternLabels[p])
+		}
+	}
+	return matched
+}
 
-			// 記錄通知時間
-			w.recordNotification(sub.SubscriptionID)
+// patternSide 訂閱要求且實際出現的型態共同的方向，十字線等沒有方向的型態或方向互相矛盾時為 neutral
+func patternSide(patterns []indicators.CandlePattern, wanted []string) string {
+	sides := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		if !slices.Contains(wanted, string(p)) {
+			continue
+		}
+		switch {
+		case p.IsBullish():
+			sides = append(sides, models.SignalSideLong)
+		case p.IsBearish():
+			sides = append(sides, models.SignalSideShort)
+		default:
+			sides = append(sides, models.SignalSideNeutral)
 		}
 	}
+	return models.CommonSignalSide(sides)
+}
+
+// patternLabels K 線型態的中文名稱
+var patternLabels = map[indicators.CandlePattern]string{
+	indicators.PatternBullishEngulfing: "看漲吞噬",
+	indicators.PatternBearishEngulfing: "看跌吞噬",
+	indicators.PatternHammer:           "錘子線",
+	indicators.PatternShootingStar:     "射擊之星",
+	indicators.PatternDoji:             "十字線",
+	indicators.PatternMorningStar:      "晨星",
+	indicators.PatternEveningStar:      "夜星",
+	indicators.PatternInsideBar:        "內包線",
+	indicators.PatternOutsideBar:       "外包線",
 }
 
+// subscriptionKlines 依訂閱的觸發模式取得 K 線：收盤確認模式只使用已收盤的 K 線
+func subscriptionKlines(sub *models.IndicatorSubscription, source *klineSource, interval string) ([]service.KlineData, error) {
+	if sub.IsCloseConfirmed() {
+		return source.ClosedKlines(interval)
+	}
+	return source.Klines(interval)
+}
+
+// 觸發類型（對應 AlertPayload.Type）
+const (
+	alertTypeAboveUpper = models.SignalAboveUpper
+	alertTypeBelowLower = models.SignalBelowLower
+	alertTypeCondition  = "condition"
+	alertTypeScript     = "script"
+	alertTypeStudy      = "study"
+	alertTypeDivergence = "divergence"
+	alertTypePattern    = "pattern"
+)
+
 // calculateIndicators 計算指標
 func (w *IndicatorMonitor) calculateIndicators(symbol string, config *models.IndicatorConfig) (*models.IndicatorResult, error) {
 	// 嘗試從快取獲取
@@ -125,8 +709,14 @@ func (w *IndicatorMonitor) calculateIndicators(symbol string, config *models.Ind
 		return cached, nil
 	}
 
-	// 獲取 4H K 線數據（用於 LRC 計算）
-	lrcKlines, err := w.priceService.FetchKlines(symbol, config.LRCInterval, config.LRCLength+5)
+	return w.computeIndicators(symbol, config)
+}
+
+// computeIndicators 不經快取直接計算指標
+func (w *IndicatorMonitor) computeIndicators(symbol string, config *models.IndicatorConfig) (*models.IndicatorResult, error) {
+	// 獲取 4H K 線數據（用於 LRC 計算），多取的部分用於收盤確認與波動率歷史
+	limit := max(config.LRCLength+models.MaxConfirmCloses+5, config.VolatilityWindow+config.VolatilityHistory+1)
+	lrcKlines, err := w.priceService.FetchKlines(symbol, config.LRCInterval, limit)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching LRC klines: %v", err)
 	}
@@ -151,10 +741,43 @@ func (w *IndicatorMonitor) calculateIndicators(symbol string, config *models.Ind
 		// 成交量獲取失敗不影響主要功能
 	}
 
+	// 收盤確認：只看已收盤的 K 線
+	now := time.Now()
+	closedKlines := lrcKlines
+	if n := len(closedKlines); n > 0 && !service.IsKlineClosed(closedKlines[n-1], now) {
+		closedKlines = closedKlines[:n-1]
+	}
+	closesAbove, closesBelow := indicators.CountClosesBeyondBands(
+		service.GetClosePrices(closedKlines), config.LRCLength, config.LRCDevMultiplier, models.MaxConfirmCloses,
+	)
+	var lastCloseTime time.Time
+	if n := len(closedKlines); n > 0 {
+		lastCloseTime = time.UnixMilli(closedKlines[n-1].CloseTime)
+	}
+
+	// 已實現波動率與波動率狀態
+	volatilityConfig := indicators.DefaultVolatilityConfig()
+	volatilityConfig.Window = config.VolatilityWindow
+	volatilityConfig.History = config.VolatilityHistory
+	volatility, err := service.BuildVolatility(closedKlines, config.LRCInterval, volatilityConfig)
+	if err != nil {
+		log.Warn().Err(err).Str("symbol", symbol).Msg("Error calculating volatility")
+	}
+
 	var volumeResult indicators.VolumeResult
+	seasonalFactor := 1.0
 	if len(volumeKlines) > 0 {
-		volumes := service.GetVolumes(volumeKlines)
-		volumeResult = indicators.CalculateVolumeStats(volumes, config.DefaultVolumeAvgPeriod)
+		volumeResult = service.BuildVolumeStats(volumeKlines, config.DefaultVolumeAvgPeriod)
+
+		// 異常分數改以近 N 天同一 UTC 小時的 1 分 K 成交量為基準
+		current := volumeKlines[len(volumeKlines)-1]
+		if baseline, ok := w.seasonalBaseline(symbol, config.VolumeSeasonalDays, time.UnixMilli(current.OpenTime)); ok {
+			var baselineMedian float64
+			volumeResult.ZScore, volumeResult.MADScore, baselineMedian = indicators.ScoreAgainstBaseline(volumeResult.CurrentVolume, baseline)
+			if volumeResult.MedianVolume > 0 && baselineMedian > 0 {
+				seasonalFactor = baselineMedian / volumeResult.MedianVolume
+			}
+		}
 	}
 
 	result := &models.IndicatorResult{
@@ -164,35 +787,94 @@ func (w *IndicatorMonitor) calculateIndicators(symbol string, config *models.Ind
 		CenterLine:    lrc.CenterLine,
 		Slope:         lrc.Slope,
 		Deviation:     lrc.Deviation,
+		PearsonR:      lrc.PearsonR,
+		SlopePct:      lrc.SlopePct,
 		CurrentPrice:  currentPrice,
 		CurrentVolume: volumeResult.CurrentVolume,
 		AvgVolume:     volumeResult.AvgVolume,
 		VolumeRatio:   volumeResult.VolumeRatio,
-		IsAboveUpper:  currentPrice > lrc.UpperBand,
-		IsBelowLower:  currentPrice < lrc.LowerBand,
-		CalculatedAt:  time.Now(),
+
+		MedianVolume:         volumeResult.MedianVolume,
+		VolumeZScore:         volumeResult.ZScore,
+		VolumeMADScore:       volumeResult.MADScore,
+		VolumeSeasonalFactor: seasonalFactor,
+
+		CurrentQuoteVolume: volumeResult.CurrentQuoteVolume,
+		AvgQuoteVolume:     volumeResult.AvgQuoteVolume,
+		TakerBuyRatio:      volumeResult.TakerBuyRatio,
+
+		IsAboveUpper: currentPrice > lrc.UpperBand,
+		IsBelowLower: currentPrice < lrc.LowerBand,
+
+		ClosesAboveUpper: closesAbove,
+		ClosesBelowLower: closesBelow,
+		LastCloseTime:    lastCloseTime,
+
+		Volatility: volatility,
+
+		CalculatedAt: now,
 	}
 
 	return result, nil
 }
 
-// checkVolumeCondition 檢查成交量條件
-func (w *IndicatorMonitor) checkVolumeCondition(result *models.IndicatorResult, sub *models.IndicatorSubscription) bool {
-	config := indicators.VolumeConfig{
-		Enabled:    sub.EnableVolumeCheck,
-		Mode:       indicators.VolumeCheckMode(sub.VolumeCheckMode),
-		FixedValue: sub.VolumeFixedValue,
-		Multiplier: sub.VolumeMultiplier,
-		AvgPeriod:  sub.VolumeAvgPeriod,
+// minSeasonalSamples 季節性基準至少需要的樣本數，不足時沿用近 N 根的統計
+const minSeasonalSamples = 30
+
+// seasonalBaseline 獲取幣種近 days 天與 openTime 同一 UTC 小時的 1 分 K 成交量（每小時更新一次）
+func (w *IndicatorMonitor) seasonalBaseline(symbol string, days int, openTime time.Time) ([]float64, bool) {
+	if days <= 0 {
+		return nil, false
+	}
+
+	hour := openTime.UTC().Truncate(time.Hour)
+	w.seasonalMu.Lock()
+	entry, ok := w.seasonal[symbol]
+	w.seasonalMu.Unlock()
+	if ok && entry.hour.Equal(hour) {
+		return entry.baseline, len(entry.baseline) >= minSeasonalSamples
 	}
 
-	volumeResult := indicators.VolumeResult{
-		CurrentVolume: result.CurrentVolume,
-		AvgVolume:     result.AvgVolume,
-		VolumeRatio:   result.VolumeRatio,
+	var baseline []float64
+	for d := 1; d <= days; d++ {
+		start := hour.AddDate(0, 0, -d)
+		klines, err := w.priceService.FetchKlinesRange(symbol, "1m", start, start.Add(time.Hour))
+		if err != nil {
+			log.Warn().Err(err).Str("symbol", symbol).Msg("Error fetching seasonal klines, skipping seasonal baseline")
+			return nil, false
+		}
+		baseline = append(baseline, service.GetVolumes(klines)...)
 	}
 
-	return indicators.CheckVolumeCondition(volumeResult, config)
+	w.seasonalMu.Lock()
+	w.seasonal[symbol] = seasonalEntry{baseline: baseline, hour: hour}
+	w.seasonalMu.Unlock()
+
+	return baseline, len(baseline) >= minSeasonalSamples
+}
+
+// isBTCDriven 訂閱幣種觸發的 K 線是否只是跟隨 BTC 波動，無法計算時不抑制
+// 盤中觸發看未收盤的 K 線；收盤確認與 K 線型態（只在收盤後判斷）看最近一根已收盤 K 線
+func (w *IndicatorMonitor) isBTCDriven(sub *models.IndicatorSubscription) bool {
+	interval := sub.TriggerInterval(w.loadConfig().LRCInterval)
+	live := !sub.IsCloseConfirmed() && len(sub.PatternAlerts) == 0
+	driven, err := w.correlationService.IsBTCDriven(sub.Symbol, interval, sub.BTCCorrelationMin, live)
+	if err != nil {
+		log.Warn().Err(err).Str("subscriptionId", sub.SubscriptionID).Msg("Error checking BTC correlation")
+		return false
+	}
+	if driven {
+		log.Info().
+			Str("subscriptionId", sub.SubscriptionID).
+			Str("symbol", sub.Symbol).
+			Msg("Alert suppressed: move follows BTC")
+	}
+	return driven
+}
+
+// checkVolumeCondition 檢查成交量條件
+func (w *IndicatorMonitor) checkVolumeCondition(result *models.IndicatorResult, sub *models.IndicatorSubscription) bool {
+	return indicators.CheckVolumeCondition(service.IndicatorVolumeStats(result), service.SubscriptionVolumeConfig(sub))
 }
 
 // isInCooldown 檢查是否在冷卻時間內
@@ -213,13 +895,33 @@ func (w *IndicatorMonitor) recordNotification(subscriptionID string) {
 	w.repo.SetLastNotifyTime(key)
 }
 
+// alertNotice 一次觸發的通知內容
+type alertNotice struct {
+	alertType  string
+	detail     string   // 觸發說明：條件式內容、腳本、事件或符合的 K 線型態
+	confluence []string // 成立的多週期共振條件
+}
+
 // sendNotification 發送通知
-func (w *IndicatorMonitor) sendNotification(sub *models.IndicatorSubscription, result *models.IndicatorResult) {
+func (w *IndicatorMonitor) sendNotification(sub *models.IndicatorSubscription, result *models.IndicatorResult, notice alertNotice) {
+	alertType := notice.alertType
 	direction := "突破上軌 📈"
-	alertType := "above_upper"
-	if result.IsBelowLower {
+	switch {
+	case alertType == alertTypeCondition:
+		direction = "條件觸發 🎯"
+	case alertType == alertTypeScript:
+		direction = "腳本觸發 📜"
+	case alertType == alertTypeStudy:
+		direction = "指標事件 ☁️"
+	case alertType == alertTypeDivergence:
+		direction = "背離 🔀"
+	case alertType == alertTypePattern:
+		direction = "K 線型態 🕯️"
+	case alertType == alertTypeBelowLower:
 		direction = "跌破下軌 📉"
-		alertType = "below_lower"
+	}
+	if sub.IsCloseConfirmed() && (alertType == alertTypeAboveUpper || alertType == alertTypeBelowLower || alertType == alertTypeScript) {
+		direction += fmt.Sprintf("（%d 根收盤確認）", sub.RequiredCloses())
 	}
 
 	payload := models.AlertPayload{
@@ -232,9 +934,46 @@ func (w *IndicatorMonitor) sendNotification(sub *models.IndicatorSubscription, r
 		LowerBand:    result.LowerBand,
 	}
 
+	// 如果是自訂條件式或腳本，附上觸發內容
+	switch alertType {
+	case alertTypeCondition:
+		payload.Body += fmt.Sprintf(" | 條件 %s", html.EscapeString(notice.detail))
+	case alertTypeScript:
+		payload.Body += fmt.Sprintf(" | 腳本 %s", html.EscapeString(notice.detail))
+	case alertTypeStudy, alertTypeDivergence:
+		payload.Body += fmt.Sprintf(" | %s", html.EscapeString(notice.detail))
+	case alertTypePattern:
+		payload.Body += fmt.Sprintf(" | 型態 %s", notice.detail)
+	case alertTypeAboveUpper, alertTypeBelowLower:
+		if notice.detail != "" {
+			payload.Body += fmt.Sprintf(" | 型態 %s", notice.detail)
+		}
+	}
+
+	// 如果有多週期共振，列出成立的週期
+	if len(notice.confluence) > 0 {
+		payload.Body += fmt.Sprintf(" | 共振 %s", html.EscapeString(strings.Join(notice.confluence, "、")))
+	}
+
+	// 如果有通道品質過濾，加入擬合度資訊
+	if sub.MinPearsonR > 0 || sub.SlopeFilter != models.SlopeFilterNone {
+		payload.Body += fmt.Sprintf(" | R %.2f | 斜率 %+.3f%%/根", result.PearsonR, result.SlopePct)
+	}
+
 	// 如果有成交量判斷，加入成交量資訊
 	if sub.EnableVolumeCheck && result.CurrentVolume > 0 {
-		payload.Body += fmt.Sprintf(" | 成交量 %.2f (%.1fx)", result.CurrentVolume, result.VolumeRatio)
+		switch indicators.VolumeCheckMode(sub.VolumeCheckMode) {
+		case indicators.VolumeCheckModeZScore:
+			payload.Body += fmt.Sprintf(" | 成交量 %.2f (Z %.1f)", result.CurrentVolume, result.VolumeZScore)
+		case indicators.VolumeCheckModeMAD:
+			payload.Body += fmt.Sprintf(" | 成交量 %.2f (MAD %.1f)", result.CurrentVolume, result.VolumeMADScore)
+		case indicators.VolumeCheckModeNotional:
+			payload.Body += fmt.Sprintf(" | 成交額 %.0f USDT", result.CurrentQuoteVolume)
+		case indicators.VolumeCheckModeTakerBuy, indicators.VolumeCheckModeTakerSell:
+			payload.Body += fmt.Sprintf(" | 成交量 %.2f (%.1fx) | 主動買入 %.0f%%", result.CurrentVolume, result.VolumeRatio, result.TakerBuyRatio*100)
+		default:
+			payload.Body += fmt.Sprintf(" | 成交量 %.2f (%.1fx)", result.CurrentVolume, result.VolumeRatio)
+		}
 	}
 
 	if err := w.telegramService.SendAlert(sub.TelegramChatID, payload); err != nil {
@@ -270,4 +1009,3 @@ func (w *IndicatorMonitor) GetIndicatorResult(symbol string) (*models.IndicatorR
 
 	return w.calculateIndicators(symbol, config)
 }
-
@@ -0,0 +1,115 @@
+package worker
+
+import (
+	"time"
+
+	"cryptowatch/internal/expr"
+	"cryptowatch/internal/indicators"
+	"cryptowatch/internal/service"
+)
+
+// klineSource 單一幣種在一次檢查中的 K 線資料來源
+// 同一週期的 K 線只請求一次，供該幣種的所有訂閱共用
+type klineSource struct {
+	symbol       string
+	priceService *service.PriceService
+	price        float64
+
+	klines   map[string][]service.KlineData
+	errs     map[string]error
+	channels map[string]*channel
+}
+
+// newKlineSource 創建資料來源
+// price: 本次檢查使用的當前價格
+func newKlineSource(symbol string, priceService *service.PriceService, price float64) *klineSource {
+	return &klineSource{
+		symbol:       symbol,
+		priceService: priceService,
+		price:        price,
+		klines:       make(map[string][]service.KlineData),
+		errs:         make(map[string]error),
+		channels:     make(map[string]*channel),
+	}
+}
+
+// Klines 獲取指定週期的 K 線（最新的在最後，包含尚未收盤的 K 線）
+func (s *klineSource) Klines(interval string) ([]service.KlineData, error) {
+	if klines, ok := s.klines[interval]; ok {
+		return klines, nil
+	}
+	if err, ok := s.errs[interval]; ok {
+		return nil, err
+	}
+
+	klines, err := s.priceService.FetchKlines(s.symbol, interval, expr.Lookback)
+	if err != nil {
+		s.errs[interval] = err
+		return nil, err
+	}
+	s.klines[interval] = klines
+	return klines, nil
+}
+
+// ClosedKlines 獲取指定週期已收盤的 K 線（去掉尚未收盤的最新一根）
+func (s *klineSource) ClosedKlines(interval string) ([]service.KlineData, error) {
+	klines, err := s.Klines(interval)
+	if err != nil {
+		return nil, err
+	}
+	if n := len(klines); n > 0 && !service.IsKlineClosed(klines[n-1], time.Now()) {
+		klines = klines[:n-1]
+	}
+	return klines, nil
+}
+
+// Price 當前價格
+func (s *klineSource) Price() (float64, error) {
+	return s.price, nil
+}
+
+// Closes 指定週期的收盤價
+func (s *klineSource) Closes(interval string) ([]float64, error) {
+	klines, err := s.Klines(interval)
+	if err != nil {
+		return nil, err
+	}
+	return service.GetClosePrices(klines), nil
+}
+
+// Volumes 指定週期的成交量
+func (s *klineSource) Volumes(interval string) ([]float64, error) {
+	klines, err := s.Klines(interval)
+	if err != nil {
+		return nil, err
+	}
+	return service.GetVolumes(klines), nil
+}
+
+// channel 某週期已收盤 K 線的 LRC 通道與最新收盤價
+type channel struct {
+	lrc       indicators.LRCResult
+	lastClose float64
+}
+
+// Channel 以已收盤 K 線計算指定週期的 LRC 通道（同一週期只計算一次）
+func (s *klineSource) Channel(interval string, length int, devMultiplier float64) (*channel, error) {
+	if ch, ok := s.channels[interval]; ok {
+		return ch, nil
+	}
+
+	klines, err := s.ClosedKlines(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	closes := service.GetClosePrices(klines)
+	lrc, err := indicators.CalculateLRC(closes, length, devMultiplier)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := &channel{lrc: lrc, lastClose: closes[len(closes)-1]}
+	s.channels[interval] = ch
+	return ch, nil
+}
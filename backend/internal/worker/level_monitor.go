@@ -0,0 +1,154 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"cryptowatch/internal/models"
+	"cryptowatch/internal/repository"
+	"cryptowatch/internal/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LevelMonitor 支撐壓力位更新器
+// 在 K 線收盤後重新計算支撐壓力位，並把 level 警報的目標價移到最新的最近區域
+type LevelMonitor struct {
+	repo         *repository.RedisRepository
+	levelService *service.LevelService
+}
+
+// NewLevelMonitor 創建支撐壓力位更新器
+func NewLevelMonitor(repo *repository.RedisRepository, levelService *service.LevelService) *LevelMonitor {
+	return &LevelMonitor{
+		repo:         repo,
+		levelService: levelService,
+	}
+}
+
+// Start 啟動更新器
+func (w *LevelMonitor) Start(ctx context.Context) error {
+	// 每分鐘檢查一次是否有新 K 線收盤
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	log.Info().Msg("Level Monitor Worker started")
+
+	w.refreshLevels()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Level Monitor Worker stopped")
+			return ctx.Err()
+		case <-ticker.C:
+			w.refreshLevels()
+		}
+	}
+}
+
+// levelKey 幣種與週期
+type levelKey struct {
+	symbol   string
+	interval string
+}
+
+// refreshLevels 更新監控幣種（LRC 週期）與 level 警報使用的支撐壓力位
+func (w *LevelMonitor) refreshLevels() {
+	config, err := w.repo.GetIndicatorConfig()
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting indicator config")
+		defaultConfig := models.DefaultIndicatorConfig()
+		config = &defaultConfig
+	}
+
+	alertsByKey := make(map[levelKey][]*models.Alert)
+	for _, symbol := range config.Symbols {
+		alertsByKey[levelKey{symbol: symbol, interval: config.LRCInterval}] = nil
+	}
+
+	alerts, err := w.repo.GetAllAlerts()
+	if err != nil {
+		log.Error().Err(err).Msg("Error fetching alerts")
+	}
+	for _, alert := range alerts {
+		if alert.AlertType != models.AlertTypeLevel {
+			continue
+		}
+		key := levelKey{symbol: alert.Symbol, interval: alert.LevelInterval}
+		alertsByKey[key] = append(alertsByKey[key], alert)
+	}
+
+	for key, alerts := range alertsByKey {
+		levels, refreshed, err := w.levelService.RefreshIfDue(key.symbol, key.interval)
+		if err != nil {
+			log.Error().Err(err).Str("symbol", key.symbol).Str("interval", key.interval).Msg("Error refreshing S/R levels")
+			continue
+		}
+		if !refreshed {
+			continue
+		}
+
+		log.Info().
+			Str("symbol", key.symbol).
+			Str("interval", key.interval).
+			Int("levels", len(levels.Levels)).
+			Msg("S/R levels refreshed")
+
+		for _, alert := range alerts {
+			w.retarget(alert, levels)
+		}
+	}
+}
+
+// retarget 將 level 警報的目標價移到最新的最近支撐壓力區
+// 以儲存中最新的警報判斷並只改動目標價，不會覆蓋 AlertMonitor 同時寫入的檢查與觸發狀態
+func (w *LevelMonitor) retarget(alert *models.Alert, levels *models.SRLevels) {
+	price := levels.Price
+	if latest, err := w.repo.GetPrice(alert.Symbol); err == nil {
+		price = latest.Price
+	}
+
+	var previous, target float64
+	retargeted, err := w.repo.UpdateAlertState(alert.AlertID, func(stored *models.Alert) bool {
+		// 已觸發、等待通知送達的警報不再移動目標價
+		if stored.IsTriggered() {
+			return false
+		}
+
+		// 價格自 AlertMonitor 上次檢查後已穿越目前目標價，保留原目標價讓下次檢查觸發
+		if stored.LastCheckedAt != nil && !stored.Disarmed {
+			if _, crossed := stored.Crossed(stored.LastPrice, price, price); crossed {
+				log.Info().
+					Str("alertId", stored.AlertID).
+					Float64("last_price", stored.LastPrice).
+					Float64("price", price).
+					Float64("target_price", stored.TargetPrice).
+					Msg("Keeping level alert target until pending cross is checked")
+				return false
+			}
+		}
+
+		previous = stored.TargetPrice
+		if err := w.levelService.ResolveAlertTarget(stored, levels, levels.Price); err != nil {
+			// 暫時沒有可用的區域時保留原目標價
+			log.Warn().Err(err).Str("alertId", stored.AlertID).Msg("Keeping previous level alert target")
+			return false
+		}
+		target = stored.TargetPrice
+		return target != previous
+	})
+	if err != nil {
+		log.Error().Err(err).Str("alertId", alert.AlertID).Msg("Error saving level alert target")
+		return
+	}
+	if !retargeted {
+		return
+	}
+	log.Info().
+		Str("alertId", alert.AlertID).
+		Str("symbol", alert.Symbol).
+		Float64("previous_target", previous).
+		Float64("target_price", target).
+		Msg("Level alert retargeted")
+}
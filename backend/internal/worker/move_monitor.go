@@ -0,0 +1,174 @@
+package worker
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"cryptowatch/internal/models"
+	"cryptowatch/internal/repository"
+	"cryptowatch/internal/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// MoveMonitor 漲跌幅與速度警報監控器
+// 漲跌幅以 1 分鐘 K 線計算，速度以 PriceFetcher 寫入 Redis 的價格滾動視窗計算
+type MoveMonitor struct {
+	repo         *repository.RedisRepository
+	priceService *service.PriceService
+	delivery     alertDelivery
+
+	// 各幣種最近 MaxVelocityWindow 分鐘的價格
+	points map[string][]service.PricePoint
+}
+
+// NewMoveMonitor 創建漲跌幅與速度警報監控器
+func NewMoveMonitor(repo *repository.RedisRepository, priceService *service.PriceService, notificationService *service.NotificationService, historyService *service.AlertHistoryService) *MoveMonitor {
+	return &MoveMonitor{
+		repo:         repo,
+		priceService: priceService,
+		delivery:     alertDelivery{repo: repo, notificationService: notificationService, historyService: historyService},
+		points:       make(map[string][]service.PricePoint),
+	}
+}
+
+// Start 啟動監控
+func (w *MoveMonitor) Start(ctx context.Context) error {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	log.Info().Msg("Move Monitor Worker started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Move Monitor Worker stopped")
+			return ctx.Err()
+		case <-ticker.C:
+			w.checkMoveAlerts()
+		}
+	}
+}
+
+// checkMoveAlerts 檢查所有漲跌幅與速度警報
+func (w *MoveMonitor) checkMoveAlerts() {
+	alerts, err := w.repo.GetAllAlerts()
+	if err != nil {
+		log.Error().Err(err).Msg("Error fetching alerts")
+		return
+	}
+
+	// 各幣種漲跌幅警報的最長視窗，K 線只抓需要的根數
+	windows := make(map[string]int)
+	for _, alert := range alerts {
+		if alert.AlertType == models.AlertTypePercentMove {
+			windows[alert.Symbol] = max(windows[alert.Symbol], alert.TimeWindow)
+		}
+	}
+
+	now := time.Now()
+	klines := make(map[string][]service.KlineData)
+	sampled := make(map[string]*models.Price)
+
+	for _, alert := range alerts {
+		if alert.AlertType != models.AlertTypePercentMove && alert.AlertType != models.AlertTypeVelocity {
+			continue
+		}
+
+		// 已觸發但通知尚未全部送達，重試通知
+		if alert.IsTriggered() {
+			w.delivery.deliver(alert)
+			continue
+		}
+		// 到期的警報由 AlertSweeper 通知用戶並刪除
+		if alert.IsExpired(now) {
+			continue
+		}
+
+		price, ok := sampled[alert.Symbol]
+		if !ok {
+			price = w.samplePrice(alert.Symbol, now)
+			sampled[alert.Symbol] = price
+		}
+		if price == nil {
+			continue
+		}
+
+		var up, down float64
+		if alert.AlertType == models.AlertTypePercentMove {
+			if _, ok := klines[alert.Symbol]; !ok {
+				klines[alert.Symbol] = w.fetchKlines(alert.Symbol, windows[alert.Symbol]+1)
+			}
+			up, down = service.PercentMove(klines[alert.Symbol], price.Price, now, alert.TimeWindow)
+		} else {
+			velocity, ok := service.Velocity(w.points[alert.Symbol], now, alert.TimeWindow)
+			if !ok {
+				continue // 價格紀錄還不足一個視窗
+			}
+			up, down = math.Max(velocity, 0), math.Max(-velocity, 0)
+		}
+		magnitude, signed := alert.MoveMagnitude(up, down)
+
+		// 重複警報觸發後停用，變動幅度回落到遲滯區間外才重新啟用
+		if alert.Disarmed {
+			if alert.ShouldRearm(magnitude) {
+				w.delivery.rearm(alert, magnitude)
+			}
+			continue
+		}
+
+		if magnitude >= alert.MoveThreshold() {
+			log.Info().
+				Str("symbol", alert.Symbol).
+				Str("type", alert.AlertType).
+				Int("window", alert.TimeWindow).
+				Float64("move", signed).
+				Float64("threshold", alert.MoveThreshold()).
+				Msg("Move alert triggered")
+			w.delivery.trigger(alert, signed)
+		}
+	}
+
+	w.prunePoints(now)
+}
+
+// samplePrice 讀取最新價格並加入滾動視窗，同一筆價格只記錄一次
+func (w *MoveMonitor) samplePrice(symbol string, now time.Time) *models.Price {
+	price, err := w.repo.GetPrice(symbol)
+	if err != nil {
+		return nil
+	}
+
+	at := price.Timestamp
+	if at.IsZero() || at.After(now) {
+		at = now
+	}
+	points := w.points[symbol]
+	if n := len(points); n == 0 || at.After(points[n-1].Time) {
+		w.points[symbol] = append(points, service.PricePoint{Time: at, Price: price.Price})
+	}
+	return price
+}
+
+// fetchKlines 抓取最近 limit 根 1 分鐘 K 線
+func (w *MoveMonitor) fetchKlines(symbol string, limit int) []service.KlineData {
+	klines, err := w.priceService.FetchKlines(symbol, "1m", limit)
+	if err != nil {
+		log.Error().Err(err).Str("symbol", symbol).Msg("Error fetching klines for move alerts")
+		return nil
+	}
+	return klines
+}
+
+// prunePoints 只保留速度警報視窗上限內的價格，並保留視窗開始前的最後一筆作為基準
+func (w *MoveMonitor) prunePoints(now time.Time) {
+	cutoff := now.Add(-time.Duration(models.MaxVelocityWindow) * time.Minute)
+	for symbol, points := range w.points {
+		keep := 0
+		for keep+1 < len(points) && !points[keep+1].Time.After(cutoff) {
+			keep++
+		}
+		w.points[symbol] = points[keep:]
+	}
+}
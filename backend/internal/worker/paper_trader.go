@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"cryptowatch/internal/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// PaperTrader 模擬交易撮合器
+// 以 PriceFetcher 寫入 Redis 的最新價格檢查未平倉模擬部位的停損停利
+type PaperTrader struct {
+	paperService *service.PaperService
+}
+
+// NewPaperTrader 創建模擬交易撮合器
+func NewPaperTrader(paperService *service.PaperService) *PaperTrader {
+	return &PaperTrader{paperService: paperService}
+}
+
+// Start 啟動撮合器
+func (w *PaperTrader) Start(ctx context.Context) error {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	log.Info().Msg("Paper Trader Worker started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Paper Trader Worker stopped")
+			return ctx.Err()
+		case <-ticker.C:
+			if err := w.paperService.CheckPositions(); err != nil {
+				log.Error().Err(err).Msg("Error checking paper positions")
+			}
+		}
+	}
+}
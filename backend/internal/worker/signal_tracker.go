@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"cryptowatch/internal/repository"
+	"cryptowatch/internal/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SignalTracker 訊號結果追蹤器
+// 通知發出後補上 +15m/+1h/+4h/+24h 的價格與最大順向/逆向波動
+type SignalTracker struct {
+	repo          *repository.RedisRepository
+	signalService *service.SignalService
+}
+
+// NewSignalTracker 創建訊號結果追蹤器
+func NewSignalTracker(repo *repository.RedisRepository, signalService *service.SignalService) *SignalTracker {
+	return &SignalTracker{
+		repo:          repo,
+		signalService: signalService,
+	}
+}
+
+// Start 啟動追蹤器
+func (w *SignalTracker) Start(ctx context.Context) error {
+	// 每分鐘補上一次已收盤的 1 分鐘 K 線
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	log.Info().Msg("Signal Tracker Worker started")
+
+	w.trackSignals()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Signal Tracker Worker stopped")
+			return ctx.Err()
+		case <-ticker.C:
+			w.trackSignals()
+		}
+	}
+}
+
+// trackSignals 更新所有尚未追蹤完成的訊號
+func (w *SignalTracker) trackSignals() {
+	signals, err := w.repo.GetPendingSignals()
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting pending signals")
+		return
+	}
+
+	now := time.Now()
+	for _, signal := range signals {
+		if _, err := w.signalService.UpdateOutcomes(signal, now); err != nil {
+			log.Error().
+				Err(err).
+				Str("signalId", signal.SignalID).
+				Str("symbol", signal.Symbol).
+				Msg("Error updating signal outcomes")
+		}
+	}
+}
@@ -13,12 +13,14 @@ import (
 type VolumeMonitor struct {
 	repo         *repository.RedisRepository
 	priceService *service.PriceService
+	delivery     alertDelivery
 }
 
-func NewVolumeMonitor(repo *repository.RedisRepository, priceService *service.PriceService) *VolumeMonitor {
+func NewVolumeMonitor(repo *repository.RedisRepository, priceService *service.PriceService, notificationService *service.NotificationService, historyService *service.AlertHistoryService) *VolumeMonitor {
 	return &VolumeMonitor{
 		repo:         repo,
 		priceService: priceService,
+		delivery:     alertDelivery{repo: repo, notificationService: notificationService, historyService: historyService},
 	}
 }
 
@@ -51,6 +53,16 @@ func (w *VolumeMonitor) checkVolumeAlerts() {
 			continue
 		}
 
+		// 已觸發但通知尚未全部送達，重試通知
+		if alert.IsTriggered() {
+			w.delivery.deliver(alert)
+			continue
+		}
+		// 到期的警報由 AlertSweeper 通知用戶並刪除
+		if alert.IsExpired(time.Now()) {
+			continue
+		}
+
 		interval := w.getIntervalString(alert.TimeWindow)
 
 		currentVolume, err := w.priceService.FetchKlineVolume(alert.Symbol, interval)
@@ -62,6 +74,14 @@ func (w *VolumeMonitor) checkVolumeAlerts() {
 			continue
 		}
 
+		// 重複警報觸發後停用，成交量回落到遲滯區間外才重新啟用
+		if alert.Disarmed {
+			if alert.ShouldRearm(currentVolume) {
+				w.delivery.rearm(alert, currentVolume)
+			}
+			continue
+		}
+
 		if currentVolume >= alert.TargetVolume {
 			log.Info().
 				Str("symbol", alert.Symbol).
@@ -69,7 +89,7 @@ func (w *VolumeMonitor) checkVolumeAlerts() {
 				Float64("current_volume", currentVolume).
 				Float64("target_volume", alert.TargetVolume).
 				Msg("Volume alert triggered")
-			w.repo.DeleteAlert(alert.AlertID)
+			w.delivery.trigger(alert, currentVolume)
 		}
 	}
 }